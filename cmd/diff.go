@@ -0,0 +1,590 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/veditoid/kubectl-rltop/pkg"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Levels the diff command compares at, chosen with --by
+const (
+	diffByPod      = "pod"
+	diffByWorkload = "workload"
+	diffByNode     = "node"
+)
+
+// diffLevels are the supported values of --by
+var diffLevels = []string{diffByPod, diffByWorkload, diffByNode}
+
+// Changes a diff row is marked with
+const (
+	diffAdded     = "added"
+	diffRemoved   = "removed"
+	diffChanged   = "changed"
+	diffUnchanged = "unchanged"
+)
+
+// diffValues holds the usage, requests and limits of one side of a diff row,
+// CPU in millicores and memory in bytes
+type diffValues struct {
+	HasMetrics         bool
+	CPUUsageMilli      int64
+	CPURequestMilli    int64
+	CPULimitMilli      int64
+	MemoryUsageBytes   int64
+	MemoryRequestBytes int64
+	MemoryLimitBytes   int64
+}
+
+// CombinedDiffData represents a pod, workload or node in two snapshots.
+// Before is zero for added rows and After is zero for removed rows.
+type CombinedDiffData struct {
+	Namespace string // Empty for nodes
+	Kind      string // Set only when comparing workloads
+	Name      string
+	Change    string // added, removed, changed or unchanged
+	Before    diffValues
+	After     diffValues
+}
+
+// DiffOptions holds the options of the diff command
+type DiffOptions struct {
+	By            string // pod, workload or node
+	Namespace     string // Empty means every recorded namespace
+	SortBy        string
+	Relative      bool // Sort by the relative change instead of the absolute one
+	ShowUnchanged bool
+	NoHeaders     bool
+	Output        string
+}
+
+// diffDelta returns the change of a value between the two sides of a row, and false when it is unknown:
+// usage is unknown when a side that exists has no metrics, a missing side counts as zero
+func diffDelta(d CombinedDiffData, value func(diffValues) int64, usage bool) (int64, bool) {
+	beforeKnown := d.Change == diffAdded || d.Before.HasMetrics
+	afterKnown := d.Change == diffRemoved || d.After.HasMetrics
+	if usage && (!beforeKnown || !afterKnown) {
+		return 0, false
+	}
+	return value(d.After) - value(d.Before), true
+}
+
+// diffPercent returns the change of a value as a percentage of its value before, and false when
+// it is unknown or there is nothing to compare against (an added row, an unset request, ...)
+func diffPercent(d CombinedDiffData, value func(diffValues) int64, usage bool) (float64, bool) {
+	delta, ok := diffDelta(d, value, usage)
+	if !ok || value(d.Before) == 0 {
+		return 0, false
+	}
+	return float64(delta) * 100 / float64(value(d.Before)), true
+}
+
+// diffFields maps the numeric --sort-by fields of the diff command to the values they compare,
+// and whether the value is usage
+var diffFields = map[string]struct {
+	value func(diffValues) int64
+	usage bool
+}{
+	"cpu":            {func(v diffValues) int64 { return v.CPUUsageMilli }, true},
+	"memory":         {func(v diffValues) int64 { return v.MemoryUsageBytes }, true},
+	"cpu-request":    {func(v diffValues) int64 { return v.CPURequestMilli }, false},
+	"cpu-limit":      {func(v diffValues) int64 { return v.CPULimitMilli }, false},
+	"memory-request": {func(v diffValues) int64 { return v.MemoryRequestBytes }, false},
+	"memory-limit":   {func(v diffValues) int64 { return v.MemoryLimitBytes }, false},
+}
+
+// diffSortValue returns the value a --sort-by field sorts diff rows by: the signed change,
+// or the signed relative change with relative
+func diffSortValue(field string, relative bool) (sortValue[CombinedDiffData], bool) {
+	f, ok := diffFields[field]
+	if !ok {
+		return nil, false
+	}
+	if relative {
+		return func(d CombinedDiffData) (float64, bool) { return diffPercent(d, f.value, f.usage) }, true
+	}
+	return func(d CombinedDiffData) (float64, bool) {
+		delta, ok := diffDelta(d, f.value, f.usage)
+		return float64(delta), ok
+	}, true
+}
+
+// RunDiff executes the diff command on two snapshots
+func RunDiff(before, after *pkg.Snapshot, opts DiffOptions) error {
+	var combined []CombinedDiffData
+	switch opts.By {
+	case diffByNode:
+		if err := checkSnapshotNodes(before); err != nil {
+			return err
+		}
+		if err := checkSnapshotNodes(after); err != nil {
+			return err
+		}
+		combined = diffNodes(snapshotNodeData(before), snapshotNodeData(after))
+	case diffByWorkload:
+		combined = diffWorkloads(snapshotWorkloadData(before, opts.Namespace),
+			snapshotWorkloadData(after, opts.Namespace))
+	default:
+		combined = diffPods(snapshotPodData(before, opts.Namespace), snapshotPodData(after, opts.Namespace))
+	}
+
+	if !opts.ShowUnchanged {
+		combined = filterUnchanged(combined)
+	}
+	sortDiffData(combined, opts.SortBy, opts.Relative)
+
+	return printDiffData(combined, before.Timestamp, after.Timestamp, opts)
+}
+
+// snapshotPodData combines the pods of a snapshot in the namespace (empty for all)
+func snapshotPodData(snapshot *pkg.Snapshot, namespace string) []CombinedPodData {
+	metrics, resources := snapshot.Pods(namespace, nil)
+	return combineMetricsAndResources(metrics, resources)
+}
+
+// snapshotWorkloadData combines the pods of a snapshot in the namespace (empty for all) per workload
+func snapshotWorkloadData(snapshot *pkg.Snapshot, namespace string) []CombinedWorkloadData {
+	return combineWorkloads(snapshotPodData(snapshot, namespace), snapshot.PodWorkloads())
+}
+
+// snapshotNodeData combines the nodes of a snapshot
+func snapshotNodeData(snapshot *pkg.Snapshot) []CombinedNodeData {
+	metrics, resources, nodes := snapshot.NodeData(nil)
+	return combineNodeMetricsAndResources(metrics, resources, nodes, false)
+}
+
+// podDiffValues returns the values a diff compares of a pod or a group of pods
+func podDiffValues(d CombinedPodData) diffValues {
+	return diffValues{
+		HasMetrics:         d.HasMetrics,
+		CPUUsageMilli:      d.CPUUsageMilli,
+		CPURequestMilli:    d.CPURequestMilli,
+		CPULimitMilli:      d.CPULimitMilli,
+		MemoryUsageBytes:   d.MemoryUsageBytes,
+		MemoryRequestBytes: d.MemoryRequestBytes,
+		MemoryLimitBytes:   d.MemoryLimitBytes,
+	}
+}
+
+// diffPods compares pods by namespace and name
+func diffPods(before, after []CombinedPodData) []CombinedDiffData {
+	row := func(d CombinedPodData) CombinedDiffData {
+		return CombinedDiffData{Namespace: d.Namespace, Name: d.Name, After: podDiffValues(d)}
+	}
+	return diffRows(before, after, row)
+}
+
+// diffWorkloads compares workloads by namespace, kind and name
+func diffWorkloads(before, after []CombinedWorkloadData) []CombinedDiffData {
+	row := func(d CombinedWorkloadData) CombinedDiffData {
+		return CombinedDiffData{Namespace: d.Namespace, Kind: d.Kind, Name: d.Name, After: podDiffValues(d.CombinedPodData)}
+	}
+	return diffRows(before, after, row)
+}
+
+// diffNodes compares nodes by name
+func diffNodes(before, after []CombinedNodeData) []CombinedDiffData {
+	row := func(d CombinedNodeData) CombinedDiffData {
+		return CombinedDiffData{Name: d.Name, After: diffValues{
+			HasMetrics:         d.HasMetrics,
+			CPUUsageMilli:      d.CPUUsageMilli,
+			CPURequestMilli:    d.CPURequestMilli,
+			CPULimitMilli:      d.CPULimitMilli,
+			MemoryUsageBytes:   d.MemoryUsageBytes,
+			MemoryRequestBytes: d.MemoryRequestBytes,
+			MemoryLimitBytes:   d.MemoryLimitBytes,
+		}}
+	}
+	return diffRows(before, after, row)
+}
+
+// diffRows matches the entries of both sides by namespace, kind and name and marks each row.
+// row returns the identity of an entry with its values in After.
+func diffRows[T any](before, after []T, row func(T) CombinedDiffData) []CombinedDiffData {
+	combined := make([]CombinedDiffData, 0, len(after))
+	index := make(map[string]int, len(after))
+	key := func(d CombinedDiffData) string { return d.Namespace + "/" + d.Kind + "/" + d.Name }
+
+	for _, entry := range after {
+		d := row(entry)
+		d.Change = diffAdded
+		index[key(d)] = len(combined)
+		combined = append(combined, d)
+	}
+	for _, entry := range before {
+		d := row(entry)
+		i, ok := index[key(d)]
+		if !ok {
+			d.Before, d.After = d.After, diffValues{}
+			d.Change = diffRemoved
+			combined = append(combined, d)
+			continue
+		}
+		combined[i].Before = d.After
+		combined[i].Change = diffChanged
+		if combined[i].Before == combined[i].After {
+			combined[i].Change = diffUnchanged
+		}
+	}
+
+	return combined
+}
+
+// filterUnchanged drops the rows whose usage, requests and limits did not change
+func filterUnchanged(data []CombinedDiffData) []CombinedDiffData {
+	filtered := make([]CombinedDiffData, 0, len(data))
+	for _, d := range data {
+		if d.Change != diffUnchanged {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// sortDiffData sorts the diff rows based on the sortBy field.
+// Ties and unknown values keep the default namespace/kind/name order.
+func sortDiffData(data []CombinedDiffData, sortBy string, relative bool) {
+	sort.Slice(data, func(i, j int) bool {
+		if data[i].Namespace != data[j].Namespace {
+			return data[i].Namespace < data[j].Namespace
+		}
+		if data[i].Kind != data[j].Kind {
+			return data[i].Kind < data[j].Kind
+		}
+		return data[i].Name < data[j].Name
+	})
+
+	field, descending := parseSortBy(sortBy)
+	switch field {
+	case "namespace":
+		if descending {
+			sort.SliceStable(data, func(i, j int) bool { return data[i].Namespace > data[j].Namespace })
+		}
+	case "name":
+		sort.SliceStable(data, func(i, j int) bool {
+			if descending {
+				return data[i].Name > data[j].Name
+			}
+			return data[i].Name < data[j].Name
+		})
+	case "change":
+		// Descending, the default, lists added rows first
+		order := map[string]int{diffAdded: 3, diffRemoved: 2, diffChanged: 1, diffUnchanged: 0}
+		sort.SliceStable(data, func(i, j int) bool {
+			if descending {
+				return order[data[i].Change] > order[data[j].Change]
+			}
+			return order[data[i].Change] < order[data[j].Change]
+		})
+	default:
+		if value, ok := diffSortValue(field, relative); ok {
+			sortByValue(data, value, descending)
+		}
+	}
+}
+
+// formatDiff formats the change of a value with its sign and the relative change, e.g. +150m (+60%).
+// Unknown usage is "<unknown>" and a value unset on both sides is "-".
+func formatDiff(d CombinedDiffData, field string, format func(int64) string) string {
+	f := diffFields[field]
+	delta, ok := diffDelta(d, f.value, f.usage)
+	if !ok {
+		return unknownValue
+	}
+	if !f.usage && f.value(d.Before) == 0 && f.value(d.After) == 0 {
+		return "-"
+	}
+
+	formatted := formatDelta(delta, format)
+	if percent, ok := diffPercent(d, f.value, f.usage); ok && delta != 0 {
+		formatted += fmt.Sprintf(" (%+.0f%%)", percent)
+	}
+	return formatted
+}
+
+// formatDelta formats a difference with its sign, e.g. +150m or -64.00Mi
+func formatDelta(delta int64, format func(int64) string) string {
+	switch {
+	case delta > 0:
+		return "+" + format(delta)
+	case delta < 0:
+		return "-" + format(-delta)
+	}
+	return "0"
+}
+
+// columns formats the changes of the usage, requests and limits of a diff row
+func (d CombinedDiffData) columns() resourceColumns {
+	return resourceColumns{
+		CPUUsage:      formatDiff(d, "cpu", pkg.FormatCPU),
+		CPURequest:    formatDiff(d, "cpu-request", pkg.FormatCPU),
+		CPULimit:      formatDiff(d, "cpu-limit", pkg.FormatCPU),
+		MemoryUsage:   formatDiff(d, "memory", pkg.FormatMemory),
+		MemoryRequest: formatDiff(d, "memory-request", pkg.FormatMemory),
+		MemoryLimit:   formatDiff(d, "memory-limit", pkg.FormatMemory),
+	}
+}
+
+// printDiffData prints the diff rows in the requested output format
+func printDiffData(combined []CombinedDiffData, before, after time.Time, opts DiffOptions) error {
+	if isStructuredOutput(opts.Output) {
+		return printStructured(newDiffList(combined, opts.By, before, after), opts.Output)
+	}
+
+	if len(combined) == 0 {
+		fmt.Fprintf(os.Stderr, "No changes found between %s and %s\n",
+			before.Format(time.RFC3339), after.Format(time.RFC3339))
+		return nil
+	}
+
+	// Print table, with a NAMESPACE column when comparing pods or workloads across all namespaces
+	printDiffTable(combined, diffTableOptions{
+		NoHeaders:     opts.NoHeaders,
+		ShowNamespace: opts.By != diffByNode && opts.Namespace == "",
+		ShowKind:      opts.By == diffByWorkload,
+	})
+
+	return nil
+}
+
+// diffTableOptions controls which columns printDiffTable prints
+type diffTableOptions struct {
+	NoHeaders     bool
+	ShowNamespace bool // Add a leading NAMESPACE column
+	ShowKind      bool // Add a KIND column before the name
+}
+
+// printDiffTable prints the diff rows in a formatted table
+func printDiffTable(data []CombinedDiffData, opts diffTableOptions) {
+	// Calculate column widths
+	namespaceWidth := 20
+	kindWidth := 11
+	nameWidth := 40
+	changeWidth := 9
+	cpuWidth := 16
+	memWidth := 18
+
+	for _, d := range data {
+		if len(d.Namespace) > namespaceWidth {
+			namespaceWidth = len(d.Namespace)
+		}
+		if len(d.Kind) > kindWidth {
+			kindWidth = len(d.Kind)
+		}
+		if len(d.Name) > nameWidth {
+			nameWidth = len(d.Name)
+		}
+	}
+
+	// Print header unless --no-headers is set
+	if !opts.NoHeaders {
+		var header string
+		if opts.ShowNamespace {
+			header = fmt.Sprintf("%-*s  ", namespaceWidth, "NAMESPACE")
+		}
+		if opts.ShowKind {
+			header += fmt.Sprintf("%-*s  ", kindWidth, "KIND")
+		}
+		header += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
+			nameWidth, "NAME",
+			changeWidth, "CHANGE",
+			cpuWidth, "CPU(cores)",
+			cpuWidth, "CPU REQUEST",
+			cpuWidth, "CPU LIMIT",
+			memWidth, "MEMORY(bytes)",
+			memWidth, "MEMORY REQUEST",
+			memWidth, "MEMORY LIMIT",
+		)
+		fmt.Println(header)
+	}
+
+	// Print rows
+	for _, d := range data {
+		columns := d.columns()
+		var row string
+		if opts.ShowNamespace {
+			row = fmt.Sprintf("%-*s  ", namespaceWidth, d.Namespace)
+		}
+		if opts.ShowKind {
+			row += fmt.Sprintf("%-*s  ", kindWidth, d.Kind)
+		}
+		row += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
+			nameWidth, d.Name,
+			changeWidth, d.Change,
+			cpuWidth, columns.CPUUsage,
+			cpuWidth, columns.CPURequest,
+			cpuWidth, columns.CPULimit,
+			memWidth, columns.MemoryUsage,
+			memWidth, columns.MemoryRequest,
+			memWidth, columns.MemoryLimit,
+		)
+		fmt.Println(row)
+	}
+}
+
+// captureLiveSnapshot takes a snapshot of the cluster to compare a recording with
+func captureLiveSnapshot(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	namespace, labelSelector string,
+	nodes bool,
+) (*pkg.Snapshot, error) {
+	if err := source.Check(ctx); err != nil {
+		return nil, err
+	}
+	return captureSnapshot(ctx, clientset, source,
+		RecordOptions{Namespace: namespace, LabelSelector: labelSelector, Nodes: nodes})
+}
+
+// NewDiffCommand creates the diff command
+func NewDiffCommand() *cobra.Command {
+	factory := newClientFactory()
+	var opts DiffOptions
+	var sourceOpts metricsSourceOptions
+	var beforeAt, afterAt string
+
+	cmd := &cobra.Command{
+		Use:   "diff BEFORE [AFTER]",
+		Short: "Compare the usage, requests and limits of two snapshots, or of a snapshot and the cluster",
+		Long: `Compare the usage, requests and limits of two snapshots, or of a snapshot and the cluster.
+BEFORE and AFTER are recordings made with 'rltop record' ('-' for stdin); their last snapshot is compared,
+or the last one taken at or before --before-at and --after-at. Without AFTER, BEFORE is compared with the
+current state of the cluster, in the namespace given with --namespace or else the namespace BEFORE recorded,
+and with the label selector BEFORE was recorded with.
+
+Pods are matched by namespace and name, workloads by namespace, kind and name, and nodes by name. Each row
+is marked added, removed or changed and shows the change of the usage, requests and limits, with the
+relative change in parentheses. Unchanged rows are hidden unless --show-unchanged is set.
+
+Examples:
+  # Compare the pods before and after a deploy
+  kubectl rltop diff before.jsonl after.jsonl
+
+  # Compare the requests per workload of a recording with the cluster now, largest increase first
+  kubectl rltop diff before.jsonl --by=workload --sort-by=cpu-request
+
+  # Compare the nodes at two times of a recording, largest relative drop in memory usage first
+  kubectl rltop diff incident.jsonl incident.jsonl --by=node --after-at=2024-05-01T12:00:00Z \
+    --sort-by=memory:asc --relative`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(opts.Output, diffOutputFormats); err != nil {
+				return err
+			}
+			if err := validateDiffBy(opts.By); err != nil {
+				return err
+			}
+			if err := validateSortBy(opts.SortBy, diffSortFields); err != nil {
+				return err
+			}
+			if err := sourceOpts.validate(); err != nil {
+				return err
+			}
+
+			live := len(args) == 1
+			if live && afterAt != "" {
+				return errors.New("--after-at requires an AFTER recording")
+			}
+			if !live && args[0] == "-" && args[1] == "-" {
+				return errors.New("stdin can only be read once: BEFORE and AFTER cannot both be '-'")
+			}
+			if !live {
+				for _, name := range []string{"metrics-source", "prometheus-url"} {
+					if cmd.Flags().Changed(name) {
+						return fmt.Errorf("--%s is only used to compare with the cluster, without AFTER", name)
+					}
+				}
+			}
+			beforeTime, err := parseSnapshotTime("--before-at", beforeAt)
+			if err != nil {
+				return err
+			}
+			afterTime, err := parseSnapshotTime("--after-at", afterAt)
+			if err != nil {
+				return err
+			}
+
+			before, err := readSnapshotFile(args[0], beforeTime)
+			if err != nil {
+				return err
+			}
+			if !live {
+				after, err := readSnapshotFile(args[1], afterTime)
+				if err != nil {
+					return err
+				}
+				return RunDiff(before, after, opts)
+			}
+
+			// The cluster is captured like the recording, with its label selector, unless another namespace
+			// is asked for
+			namespace := opts.Namespace
+			if namespace == "" {
+				namespace = before.Namespace
+			}
+
+			clientset, metricsClient, err := factory.Clients()
+			if err != nil {
+				return err
+			}
+			source := sourceOpts.newSource(clientset, metricsClient, pkg.UsageWindow{})
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			after, err := captureLiveSnapshot(ctx, clientset, source, namespace, before.LabelSelector,
+				opts.By == diffByNode)
+			if err != nil {
+				return err
+			}
+			return RunDiff(before, after, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.By, "by", diffByPod,
+		"Level to compare at. One of: "+strings.Join(diffLevels, ", ")+".")
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "",
+		"If non-empty, only compare the pods and workloads of this namespace (default: every recorded namespace)")
+	cmd.Flags().StringVar(&opts.SortBy, "sort-by", "",
+		"If non-empty, sort rows using specified field. One of: "+strings.Join(diffSortFields, ", ")+". "+
+			"Numeric fields sort by the change, largest increase first; append ':asc' for the largest decrease "+
+			"first. 'change' orders added, removed then changed rows.")
+	cmd.Flags().BoolVar(&opts.Relative, "relative", false,
+		"If present, numeric --sort-by fields sort by the relative change instead of the absolute one. "+
+			"Rows with nothing to compare against (added rows, values unset before) sort last.")
+	cmd.Flags().BoolVar(&opts.ShowUnchanged, "show-unchanged", false,
+		"If present, also list the rows whose usage, requests and limits did not change.")
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"If present, print output without headers.")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "",
+		"Output format. One of: (json, yaml). Defaults to a human-readable table.")
+	cmd.Flags().StringVar(&beforeAt, "before-at", "",
+		"Compare the last snapshot of BEFORE taken at or before this RFC 3339 time instead of its last one.")
+	cmd.Flags().StringVar(&afterAt, "after-at", "",
+		"Compare the last snapshot of AFTER taken at or before this RFC 3339 time instead of its last one.")
+	addMetricsSourceFlags(cmd, &sourceOpts)
+	factory.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// validateDiffBy returns an error if the --by value is not one of the supported levels
+func validateDiffBy(by string) error {
+	for _, level := range diffLevels {
+		if by == level {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported --by level %q, must be one of: %s", by, strings.Join(diffLevels, ", "))
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDiffPods(t *testing.T) {
+	before := []CombinedPodData{
+		{Namespace: "default", Name: "web-1", HasMetrics: true, CPUUsageMilli: 100, CPURequestMilli: 250},
+		{Namespace: "default", Name: "web-2", HasMetrics: true, CPUUsageMilli: 50, CPURequestMilli: 250},
+		{Namespace: "default", Name: "old", HasMetrics: true, CPUUsageMilli: 10, MemoryRequestBytes: 64 << 20},
+		{Namespace: "default", Name: "pending", CPURequestMilli: 100},
+	}
+	after := []CombinedPodData{
+		{Namespace: "default", Name: "web-1", HasMetrics: true, CPUUsageMilli: 150, CPURequestMilli: 500},
+		{Namespace: "default", Name: "web-2", HasMetrics: true, CPUUsageMilli: 50, CPURequestMilli: 250},
+		{Namespace: "default", Name: "new", HasMetrics: true, CPUUsageMilli: 20},
+		{Namespace: "default", Name: "pending", HasMetrics: true, CPUUsageMilli: 30, CPURequestMilli: 100},
+	}
+
+	rows := make(map[string]CombinedDiffData)
+	for _, d := range diffPods(before, after) {
+		rows[d.Name] = d
+	}
+	if len(rows) != 5 {
+		t.Fatalf("diffPods() returned %d rows, want 5: %+v", len(rows), rows)
+	}
+
+	tests := []struct {
+		name        string
+		wantChange  string
+		wantCPU     string
+		wantRequest string
+	}{
+		{name: "web-1", wantChange: diffChanged, wantCPU: "+50m (+50%)", wantRequest: "+250m (+100%)"},
+		{name: "web-2", wantChange: diffUnchanged, wantCPU: "0", wantRequest: "0"},
+		{name: "old", wantChange: diffRemoved, wantCPU: "-10m (-100%)", wantRequest: "-"},
+		{name: "new", wantChange: diffAdded, wantCPU: "+20m", wantRequest: "-"},
+		// Usage without metrics before cannot be compared
+		{name: "pending", wantChange: diffChanged, wantCPU: unknownValue, wantRequest: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := rows[tt.name]
+			columns := d.columns()
+			if d.Change != tt.wantChange || columns.CPUUsage != tt.wantCPU || columns.CPURequest != tt.wantRequest {
+				t.Errorf("%s = %s, CPU %q, CPU request %q, want %s, %q, %q", tt.name,
+					d.Change, columns.CPUUsage, columns.CPURequest, tt.wantChange, tt.wantCPU, tt.wantRequest)
+			}
+		})
+	}
+
+	if got := rows["old"].columns().MemoryRequest; got != "-64.00Mi (-100%)" {
+		t.Errorf("removed pod memory request = %q, want -64.00Mi (-100%%)", got)
+	}
+	if filtered := filterUnchanged(diffPods(before, after)); len(filtered) != 4 {
+		t.Errorf("filterUnchanged() kept %d rows, want 4", len(filtered))
+	}
+}
+
+func TestSortDiffData(t *testing.T) {
+	data := []CombinedDiffData{
+		{Name: "small", Change: diffChanged, Before: diffValues{CPURequestMilli: 100},
+			After: diffValues{CPURequestMilli: 300}},
+		{Name: "large", Change: diffChanged, Before: diffValues{CPURequestMilli: 2000},
+			After: diffValues{CPURequestMilli: 2500}},
+		{Name: "shrunk", Change: diffChanged, Before: diffValues{CPURequestMilli: 1000},
+			After: diffValues{CPURequestMilli: 400}},
+		{Name: "added", Change: diffAdded, After: diffValues{CPURequestMilli: 100}},
+	}
+
+	tests := []struct {
+		sortBy   string
+		relative bool
+		want     []string
+	}{
+		{sortBy: "", want: []string{"added", "large", "shrunk", "small"}},
+		{sortBy: "cpu-request", want: []string{"large", "small", "added", "shrunk"}},
+		{sortBy: "cpu-request:asc", want: []string{"shrunk", "added", "small", "large"}},
+		// Added rows have nothing to compare against and sort last
+		{sortBy: "cpu-request", relative: true, want: []string{"small", "large", "shrunk", "added"}},
+		{sortBy: "change", want: []string{"added", "large", "shrunk", "small"}},
+	}
+	for _, tt := range tests {
+		sorted := append([]CombinedDiffData(nil), data...)
+		sortDiffData(sorted, tt.sortBy, tt.relative)
+		var names []string
+		for _, d := range sorted {
+			names = append(names, d.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("sortDiffData(%q, relative=%v) = %v, want %v", tt.sortBy, tt.relative, names, tt.want)
+		}
+	}
+}
+
+func TestRunDiffByWorkload(t *testing.T) {
+	// The deploy replaced the pods and the ReplicaSet of the Deployment web
+	before := &pkg.Snapshot{
+		Timestamp:  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		PodMetrics: []pkg.PodMetrics{{Namespace: "default", Name: "web-6c4f-aaaaa", CPUMilli: 100}},
+		PodResources: []pkg.PodResources{
+			{Namespace: "default", Name: "web-6c4f-aaaaa", CPURequestMilli: 250, MemoryRequestBytes: 128 << 20},
+		},
+		Workloads: map[string]pkg.Workload{"default/web-6c4f-aaaaa": {Kind: "Deployment", Name: "web"}},
+	}
+	after := &pkg.Snapshot{
+		Timestamp:  time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC),
+		PodMetrics: []pkg.PodMetrics{{Namespace: "default", Name: "web-7d9f-bbbbb", CPUMilli: 120}},
+		PodResources: []pkg.PodResources{
+			{Namespace: "default", Name: "web-7d9f-bbbbb", CPURequestMilli: 500, MemoryRequestBytes: 128 << 20},
+		},
+		Workloads: map[string]pkg.Workload{"default/web-7d9f-bbbbb": {Kind: "Deployment", Name: "web"}},
+	}
+
+	out := captureStdout(t, func() {
+		if err := RunDiff(before, after, DiffOptions{By: diffByWorkload}); err != nil {
+			t.Errorf("RunDiff() error = %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "KIND") || !strings.Contains(lines[0], "CHANGE") {
+		t.Fatalf("RunDiff() output = %q, want a header and the Deployment", out)
+	}
+	for _, want := range []string{"Deployment", "web", "changed", "+20m (+20%)", "+250m (+100%)"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("RunDiff() row = %q, want %q", lines[1], want)
+		}
+	}
+
+	out = captureStdout(t, func() {
+		if err := RunDiff(before, after, DiffOptions{By: diffByPod, Output: outputJSON}); err != nil {
+			t.Errorf("RunDiff() error = %v", err)
+		}
+	})
+	for _, want := range []string{`"kind": "DiffList"`, `"change": "added"`, `"change": "removed"`,
+		`"before": "2024-05-01T12:00:00Z"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("RunDiff() -o json = %s, want %s", out, want)
+		}
+	}
+
+	if err := RunDiff(before, after, DiffOptions{By: diffByNode}); err == nil {
+		t.Error("RunDiff() by node should fail on snapshots recorded without nodes")
+	}
+}
+
+func TestNewDiffList(t *testing.T) {
+	data := []CombinedDiffData{{
+		Namespace: "default",
+		Name:      "web-1",
+		Change:    diffChanged,
+		Before:    diffValues{HasMetrics: true, CPUUsageMilli: 200, MemoryRequestBytes: 128 << 20},
+		After:     diffValues{CPUUsageMilli: 0, MemoryRequestBytes: 256 << 20},
+	}}
+	list := newDiffList(data, diffByPod, time.Time{}, time.Time{})
+
+	item := list.Items[0]
+	if item.CPU.Usage != nil || item.CPU.Request != nil {
+		t.Errorf("CPU = %+v, want unknown usage and unset requests omitted", item.CPU)
+	}
+	request := item.Memory.Request
+	if request == nil || request.Delta.Bytes != 128<<20 || request.Percent == nil || request.Percent.Percent != 100 {
+		t.Fatalf("memory request change = %+v, want +128Mi (+100%%)", request)
+	}
+	if request.Before.Bytes != 128<<20 || request.After.Bytes != 256<<20 || request.Delta.Formatted != "+128.00Mi" {
+		t.Errorf("memory request change = %+v", request)
+	}
+}
+
+func TestCaptureLiveSnapshot(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-1", Namespace: "default", Labels: map[string]string{"app": "db"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	// A recording made with -l is compared with the same pods only
+	snapshot, err := captureLiveSnapshot(context.Background(), clientset, staticSource{}, "default", "app=web", false)
+	if err != nil {
+		t.Fatalf("captureLiveSnapshot() error = %v", err)
+	}
+	if len(snapshot.PodResources) != 1 || snapshot.PodResources[0].Name != "web-1" {
+		t.Errorf("captureLiveSnapshot() pods = %+v, want only web-1", snapshot.PodResources)
+	}
+	if snapshot.LabelSelector != "app=web" {
+		t.Errorf("captureLiveSnapshot() label selector = %q, want app=web", snapshot.LabelSelector)
+	}
+}
+
+func TestValidateDiffBy(t *testing.T) {
+	for _, by := range diffLevels {
+		if err := validateDiffBy(by); err != nil {
+			t.Errorf("validateDiffBy(%q) error = %v", by, err)
+		}
+	}
+	if err := validateDiffBy("namespace"); err == nil {
+		t.Error("validateDiffBy(namespace) should fail")
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Content types of the Prometheus text format and of OpenMetrics, served by 'rltop serve'
+const (
+	contentTypeText        = "text/plain; version=0.0.4; charset=utf-8"
+	contentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// Types of the metric families written by expositionWriter
+const (
+	metricGauge   = "gauge"
+	metricCounter = "counter"
+)
+
+// metricLabel is a label of a sample, in the order it is written
+type metricLabel struct {
+	Name  string
+	Value string
+}
+
+// expositionWriter writes metric families in the Prometheus text format, or in OpenMetrics
+// where counters are declared without their _total suffix and the exposition ends with "# EOF"
+type expositionWriter struct {
+	buf         bytes.Buffer
+	openMetrics bool
+}
+
+// family writes the HELP and TYPE lines of a metric family. Counter names end with _total.
+func (w *expositionWriter) family(name, metricType, help string) {
+	if w.openMetrics && metricType == metricCounter {
+		name = strings.TrimSuffix(name, "_total")
+	}
+	fmt.Fprintf(&w.buf, "# HELP %s %s\n", name, escapeHelp(help))
+	fmt.Fprintf(&w.buf, "# TYPE %s %s\n", name, metricType)
+}
+
+// sample writes a sample of the current family
+func (w *expositionWriter) sample(name string, value float64, labels ...metricLabel) {
+	w.buf.WriteString(name)
+	if len(labels) > 0 {
+		w.buf.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			fmt.Fprintf(&w.buf, "%s=\"%s\"", l.Name, escapeLabelValue(l.Value))
+		}
+		w.buf.WriteByte('}')
+	}
+	w.buf.WriteByte(' ')
+	w.buf.WriteString(formatSampleValue(value))
+	w.buf.WriteByte('\n')
+}
+
+// finish returns the exposition, terminated with "# EOF" in OpenMetrics
+func (w *expositionWriter) finish() []byte {
+	if w.openMetrics {
+		w.buf.WriteString("# EOF\n")
+	}
+	return w.buf.Bytes()
+}
+
+// formatSampleValue formats a sample value; infinities are spelled +Inf and -Inf as both formats expect
+func formatSampleValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escapeHelp escapes backslashes and line feeds in a HELP text
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+// escapeLabelValue escapes backslashes, double quotes and line feeds in a label value
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// acceptsOpenMetrics reports whether the Accept header of a scrape asks for OpenMetrics
+func acceptsOpenMetrics(accept string) bool {
+	for _, mediaType := range strings.Split(accept, ",") {
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		if strings.TrimSpace(mediaType) == "application/openmetrics-text" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// clientFactory builds Kubernetes clients from the kubeconfig and the standard kubectl
+// connection flags (--kubeconfig, --context, --cluster, --user, --as, --request-timeout, ...)
+type clientFactory struct {
+	loadingRules *clientcmd.ClientConfigLoadingRules
+	overrides    *clientcmd.ConfigOverrides
+	clientConfig clientcmd.ClientConfig
+}
+
+// newClientFactory creates a client factory using the default kubeconfig loading rules
+func newClientFactory() *clientFactory {
+	return &clientFactory{
+		loadingRules: clientcmd.NewDefaultClientConfigLoadingRules(),
+		overrides:    &clientcmd.ConfigOverrides{},
+	}
+}
+
+// AddFlags adds the standard kubectl connection flags to flags.
+// --namespace is left to each command since not every command is namespaced.
+func (f *clientFactory) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.loadingRules.ExplicitPath, "kubeconfig", "",
+		"Path to the kubeconfig file to use for CLI requests.")
+
+	overrideFlags := clientcmd.RecommendedConfigOverrideFlags("")
+	overrideFlags.ContextOverrideFlags.Namespace.LongName = ""
+	overrideFlags.ClusterOverrideFlags.APIServer.ShortName = "s"
+	clientcmd.BindOverrideFlags(f.overrides, flags, overrideFlags)
+}
+
+// ClientConfig returns the kubeconfig-backed client config, honoring the connection flags.
+// It uses the RESTClientGetter pattern kubectl plugins use, which handles exec plugins properly.
+func (f *clientFactory) ClientConfig() clientcmd.ClientConfig {
+	if f.clientConfig == nil {
+		f.clientConfig = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(f.loadingRules, f.overrides)
+	}
+	return f.clientConfig
+}
+
+// Namespace returns the namespace of the selected context, or "default" if it has none
+func (f *clientFactory) Namespace() string {
+	ns, _, err := f.ClientConfig().Namespace()
+	if err != nil || ns == "" {
+		return "default"
+	}
+	return ns
+}
+
+// RESTConfig returns the REST config for the selected cluster
+func (f *clientFactory) RESTConfig() (*rest.Config, error) {
+	config, err := f.ClientConfig().ClientConfig()
+	if err != nil {
+		// Provide helpful error message for common exec plugin issues
+		if isExecPluginVersionError(err) {
+			return nil, fmt.Errorf("failed to load kubeconfig: %w. "+
+				"Your kubeconfig uses an exec plugin with an outdated API version. "+
+				"To fix this, update your kubeconfig by running: "+
+				"kubectl config view --raw > ~/.kube/config.new && "+
+				"mv ~/.kube/config.new ~/.kube/config. "+
+				"Or regenerate your kubeconfig using your cloud provider's CLI tool", err)
+		}
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	return config, nil
+}
+
+// Clients creates the Kubernetes and Metrics API clients
+func (f *clientFactory) Clients() (kubernetes.Interface, metricsclientset.Interface, error) {
+	config, err := f.RESTConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		if isExecPluginVersionError(err) {
+			return nil, nil, fmt.Errorf("failed to create kubernetes client: %w. "+
+				"Your kubeconfig uses an exec plugin with an outdated API version (v1alpha1). "+
+				"This version of kubectl-rltop requires exec plugins to use v1beta1 or v1. "+
+				"To fix this, update your kubeconfig: "+
+				"1. Run: kubectl config view --raw > ~/.kube/config.new "+
+				"2. Check the file and update any exec plugin apiVersion from v1alpha1 to v1beta1 "+
+				"3. Replace: mv ~/.kube/config.new ~/.kube/config. "+
+				"Or regenerate your kubeconfig using your cloud provider's CLI tool", err)
+		}
+		return nil, nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	metricsClient, err := metricsclientset.NewForConfig(config)
+	if err != nil {
+		if isExecPluginVersionError(err) {
+			return nil, nil, fmt.Errorf("failed to create metrics client: %w. "+
+				"Your kubeconfig uses an exec plugin with an outdated API version. "+
+				"See the error above for instructions on how to fix this", err)
+		}
+		return nil, nil, fmt.Errorf("failed to create metrics client: %w", err)
+	}
+
+	return clientset, metricsClient, nil
+}
+
+// DynamicClient creates a dynamic client, used for custom resources such as VerticalPodAutoscalers
+func (f *clientFactory) DynamicClient() (dynamic.Interface, error) {
+	config, err := f.RESTConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	return dynamicClient, nil
+}
+
+// isExecPluginVersionError reports whether err is caused by an exec plugin with an unsupported apiVersion
+func isExecPluginVersionError(err error) bool {
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "exec plugin") && strings.Contains(errMsg, "apiVersion")
+}
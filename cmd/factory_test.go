@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestClientFactoryFlags(t *testing.T) {
+	f := newClientFactory()
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f.AddFlags(flags)
+
+	for _, name := range []string{"kubeconfig", "context", "cluster", "user", "as", "request-timeout", "server", "token"} {
+		if flags.Lookup(name) == nil {
+			t.Errorf("AddFlags() did not register --%s", name)
+		}
+	}
+	// --namespace is registered by each command, not by the factory
+	if flags.Lookup("namespace") != nil {
+		t.Error("AddFlags() registered --namespace")
+	}
+}
+
+func TestClientFactoryContextOverride(t *testing.T) {
+	config := clientcmdapi.NewConfig()
+	config.Clusters["dev"] = &clientcmdapi.Cluster{Server: "https://dev.example.com"}
+	config.Clusters["prod"] = &clientcmdapi.Cluster{Server: "https://prod.example.com"}
+	config.AuthInfos["admin"] = &clientcmdapi.AuthInfo{Token: "secret"}
+	config.Contexts["dev"] = &clientcmdapi.Context{Cluster: "dev", AuthInfo: "admin", Namespace: "team-a"}
+	config.Contexts["prod"] = &clientcmdapi.Context{Cluster: "prod", AuthInfo: "admin"}
+	config.CurrentContext = "dev"
+
+	path := t.TempDir() + "/config"
+	if err := clientcmd.WriteToFile(*config, path); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		wantHost      string
+		wantNamespace string
+	}{
+		{"current context", []string{"--kubeconfig", path}, "https://dev.example.com", "team-a"},
+		{"context override", []string{"--kubeconfig", path, "--context", "prod"}, "https://prod.example.com", "default"},
+		{
+			"server override", []string{"--kubeconfig", path, "-s", "https://other.example.com"},
+			"https://other.example.com", "team-a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newClientFactory()
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			f.AddFlags(flags)
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			config, err := f.RESTConfig()
+			if err != nil {
+				t.Fatalf("RESTConfig() error = %v", err)
+			}
+			if config.Host != tt.wantHost {
+				t.Errorf("RESTConfig().Host = %q, want %q", config.Host, tt.wantHost)
+			}
+			if got := f.Namespace(); got != tt.wantNamespace {
+				t.Errorf("Namespace() = %q, want %q", got, tt.wantNamespace)
+			}
+		})
+	}
+}
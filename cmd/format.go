@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"github.com/veditoid/kubectl-rltop/pkg"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const unknownValue = "<unknown>"
+
+// resourceColumns holds the formatted usage, request and limit cells of a table row
+type resourceColumns struct {
+	CPUUsage      string
+	CPURequest    string
+	CPULimit      string
+	MemoryUsage   string
+	MemoryRequest string
+	MemoryLimit   string
+}
+
+// columns formats the usage, requests and limits of a pod (or container) row
+func (d CombinedPodData) columns() resourceColumns {
+	return formatResourceColumns(d.HasMetrics,
+		d.CPUUsageMilli, d.CPURequestMilli, d.CPULimitMilli,
+		d.MemoryUsageBytes, d.MemoryRequestBytes, d.MemoryLimitBytes)
+}
+
+// columns formats the usage and aggregated requests and limits of a node row
+func (d CombinedNodeData) columns() resourceColumns {
+	return formatResourceColumns(d.HasMetrics,
+		d.CPUUsageMilli, d.CPURequestMilli, d.CPULimitMilli,
+		d.MemoryUsageBytes, d.MemoryRequestBytes, d.MemoryLimitBytes)
+}
+
+// percentages formats node usage as a percentage of allocatable (or capacity)
+func (d CombinedNodeData) percentages() (cpuPercent, memoryPercent string) {
+	if !d.HasMetrics {
+		return unknownValue, unknownValue
+	}
+	return pkg.FormatUtilization(d.CPUUsageMilli, d.CPUAllocatableMilli),
+		pkg.FormatUtilization(d.MemoryUsageBytes, d.MemoryAllocatableBytes)
+}
+
+// commitments formats the aggregated requests and limits as a percentage of allocatable (or capacity),
+// like the "Allocated resources" section of 'kubectl describe node'. Limits above 100% mean overcommitted.
+func (d CombinedNodeData) commitments() (cpuRequest, cpuLimit, memoryRequest, memoryLimit string) {
+	return pkg.FormatUtilization(d.CPURequestMilli, d.CPUAllocatableMilli),
+		pkg.FormatUtilization(d.CPULimitMilli, d.CPUAllocatableMilli),
+		pkg.FormatUtilization(d.MemoryRequestBytes, d.MemoryAllocatableBytes),
+		pkg.FormatUtilization(d.MemoryLimitBytes, d.MemoryAllocatableBytes)
+}
+
+// formatResourceColumns formats raw millicores and bytes for display.
+// Usage is "<unknown>" without metrics and unset requests and limits are "-".
+// Memory requests and limits use the unit of the memory usage so they compare at a glance.
+func formatResourceColumns(
+	hasMetrics bool,
+	cpuUsage, cpuRequest, cpuLimit int64,
+	memoryUsage, memoryRequest, memoryLimit int64,
+) resourceColumns {
+	columns := resourceColumns{
+		CPUUsage:      unknownValue,
+		CPURequest:    formatCPUMilli(cpuRequest),
+		CPULimit:      formatCPUMilli(cpuLimit),
+		MemoryUsage:   unknownValue,
+		MemoryRequest: formatMemoryBytes(memoryRequest),
+		MemoryLimit:   formatMemoryBytes(memoryLimit),
+	}
+	if hasMetrics {
+		unit := pkg.MemoryUnit(memoryUsage)
+		columns.CPUUsage = pkg.FormatCPU(cpuUsage)
+		columns.MemoryUsage = pkg.FormatMemory(memoryUsage)
+		columns.MemoryRequest = formatMemoryBytesIn(memoryRequest, unit)
+		columns.MemoryLimit = formatMemoryBytesIn(memoryLimit, unit)
+	}
+	return columns
+}
+
+// formatCPUMilli formats millicores the same way requests and limits are formatted
+func formatCPUMilli(millicores int64) string {
+	return pkg.FormatResourceQuantity(*resource.NewMilliQuantity(millicores, resource.DecimalSI), true)
+}
+
+// formatMemoryBytes formats bytes in Mi, the default unit used for memory columns
+func formatMemoryBytes(bytes int64) string {
+	return formatMemoryBytesIn(bytes, "Mi")
+}
+
+// formatMemoryBytesIn formats bytes in the given unit (Gi, Mi or Ki)
+func formatMemoryBytesIn(bytes int64, unit string) string {
+	return pkg.FormatMemoryInUnit(*resource.NewQuantity(bytes, resource.BinarySI), unit)
+}
+
+// usageHeaders returns the headers of the CPU and memory usage columns, naming the statistic
+// when usage is aggregated over a window, e.g. CPU(p95)
+func usageHeaders(usage pkg.UsageWindow) (cpu, memory string) {
+	if usage.IsZero() {
+		return "CPU(cores)", "MEMORY(bytes)"
+	}
+	return "CPU(" + string(usage.Stat) + ")", "MEMORY(" + string(usage.Stat) + ")"
+}
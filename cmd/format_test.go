@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestFormatResourceColumns(t *testing.T) {
+	tests := []struct {
+		name string
+		data CombinedPodData
+		want resourceColumns
+	}{
+		{
+			name: "whole cores and memory in the usage unit",
+			data: CombinedPodData{
+				HasMetrics: true, CPUUsageMilli: 2000, CPURequestMilli: 1500, CPULimitMilli: 4000,
+				MemoryUsageBytes: 3 << 30, MemoryRequestBytes: 2 << 30, MemoryLimitBytes: 512 << 20,
+			},
+			want: resourceColumns{
+				CPUUsage: "2", CPURequest: "1500m", CPULimit: "4000m",
+				MemoryUsage: "3.00Gi", MemoryRequest: "2.00Gi", MemoryLimit: "0.50Gi",
+			},
+		},
+		{
+			name: "unset requests and limits",
+			data: CombinedPodData{HasMetrics: true, CPUUsageMilli: 5, MemoryUsageBytes: 512},
+			want: resourceColumns{
+				CPUUsage: "5m", CPURequest: "-", CPULimit: "-",
+				MemoryUsage: "512B", MemoryRequest: "-", MemoryLimit: "-",
+			},
+		},
+		{
+			name: "no metrics",
+			data: CombinedPodData{CPURequestMilli: 100, MemoryRequestBytes: 1 << 30},
+			want: resourceColumns{
+				CPUUsage: unknownValue, CPURequest: "100m", CPULimit: "-",
+				MemoryUsage: unknownValue, MemoryRequest: "1024.00Mi", MemoryLimit: "-",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.data.columns(); got != tt.want {
+				t.Errorf("columns() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
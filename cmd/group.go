@@ -0,0 +1,101 @@
+package cmd
+
+import "github.com/veditoid/kubectl-rltop/pkg"
+
+// podGroup sums the usage, requests and limits of a group of pods (a workload, a namespace).
+// The embedded CombinedPodData holds the name of the group and the totals.
+type podGroup struct {
+	CombinedPodData
+	Pods            int // Number of pods in the group
+	PodsWithMetrics int // Number of pods the usage is summed over
+
+	// Requests and limits of the pods with metrics, which usage is compared against
+	MeasuredCPURequestMilli    int64
+	MeasuredCPULimitMilli      int64
+	MeasuredMemoryRequestBytes int64
+	MeasuredMemoryLimitBytes   int64
+}
+
+// add adds a pod to the group totals
+func (g *podGroup) add(p CombinedPodData) {
+	g.Pods++
+	if p.HasMetrics {
+		g.PodsWithMetrics++
+		g.HasMetrics = true
+		g.CPUUsageMilli += p.CPUUsageMilli
+		g.MemoryUsageBytes += p.MemoryUsageBytes
+		g.MeasuredCPURequestMilli += p.CPURequestMilli
+		g.MeasuredCPULimitMilli += p.CPULimitMilli
+		g.MeasuredMemoryRequestBytes += p.MemoryRequestBytes
+		g.MeasuredMemoryLimitBytes += p.MemoryLimitBytes
+	}
+	g.CPURequestMilli += p.CPURequestMilli
+	g.CPULimitMilli += p.CPULimitMilli
+	g.MemoryRequestBytes += p.MemoryRequestBytes
+	g.MemoryLimitBytes += p.MemoryLimitBytes
+}
+
+// measured returns the usage of the group with the requests and limits of the pods with metrics,
+// so pods without metrics don't lower the utilization
+func (g podGroup) measured() CombinedPodData {
+	measured := g.CombinedPodData
+	measured.CPURequestMilli = g.MeasuredCPURequestMilli
+	measured.CPULimitMilli = g.MeasuredCPULimitMilli
+	measured.MemoryRequestBytes = g.MeasuredMemoryRequestBytes
+	measured.MemoryLimitBytes = g.MeasuredMemoryLimitBytes
+	return measured
+}
+
+// utilization returns usage as a percentage of the requests and limits of the pods with metrics
+func (g podGroup) utilization() (cpuRequest, cpuLimit, memoryRequest, memoryLimit string) {
+	return g.measured().utilization()
+}
+
+// averages returns the average CPU and memory usage per pod with metrics
+func (g podGroup) averages() (cpuMilli, memoryBytes int64, ok bool) {
+	if g.PodsWithMetrics == 0 {
+		return 0, 0, false
+	}
+	count := int64(g.PodsWithMetrics)
+	return g.CPUUsageMilli / count, g.MemoryUsageBytes / count, true
+}
+
+// formattedAverages formats the average usage per pod, "<unknown>" without metrics
+func (g podGroup) formattedAverages() (cpuAverage, memoryAverage string) {
+	cpuMilli, memoryBytes, ok := g.averages()
+	if !ok {
+		return unknownValue, unknownValue
+	}
+	return pkg.FormatCPU(cpuMilli), pkg.FormatMemory(memoryBytes)
+}
+
+// measuredSortFields are the --sort-by fields comparing usage with requests, computed on the measured pods
+var measuredSortFields = map[string]bool{
+	"cpu-util":        true,
+	"memory-util":     true,
+	"cpu-headroom":    true,
+	"memory-headroom": true,
+}
+
+// groupSortValues returns the numeric --sort-by fields of a command listing pod groups:
+// the pod fields applied to the group totals, plus the number of pods. Utilization and headroom
+// compare usage with the requests of the pods with metrics only.
+func groupSortValues[T any](group func(T) podGroup) map[string]sortValue[T] {
+	values := map[string]sortValue[T]{
+		"pods": func(d T) (float64, bool) {
+			return float64(group(d).Pods), true
+		},
+	}
+	for field, value := range podSortValues {
+		if measuredSortFields[field] {
+			values[field] = func(d T) (float64, bool) {
+				return value(group(d).measured())
+			}
+			continue
+		}
+		values[field] = func(d T) (float64, bool) {
+			return value(group(d).CombinedPodData)
+		}
+	}
+	return values
+}
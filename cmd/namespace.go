@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/veditoid/kubectl-rltop/pkg"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CombinedNamespaceData represents the pods of a namespace aggregated together.
+// The embedded group holds the namespace (as Name) and the totals of its pods.
+type CombinedNamespaceData struct {
+	podGroup
+	Quota *pkg.NamespaceQuota // Set with --quota when the namespace has a ResourceQuota
+}
+
+// NamespaceOptions holds the options of the namespace command
+type NamespaceOptions struct {
+	LabelSelector  string
+	NamespaceNames []string
+	Quota          bool
+	SortBy         string
+	NoHeaders      bool
+	Output         string
+	Watch          bool
+	Interval       time.Duration
+}
+
+// RunNamespace executes the namespace command
+func RunNamespace(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	opts NamespaceOptions,
+) error {
+	// Check the metrics source is available (only once, also in watch mode)
+	if err := source.Check(ctx); err != nil {
+		return err
+	}
+
+	refresh := func(ctx context.Context) error {
+		combined, err := fetchNamespaceData(ctx, clientset, source, opts)
+		if err != nil {
+			return err
+		}
+		return printNamespaceData(combined, opts)
+	}
+
+	if opts.Watch {
+		return runWatch(ctx, opts.Interval, opts.Output, refresh)
+	}
+	return refresh(ctx)
+}
+
+// fetchNamespaceData fetches pod metrics and resources (and quotas with --quota) and returns
+// the pods aggregated per namespace, filtered and sorted
+func fetchNamespaceData(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	opts NamespaceOptions,
+) ([]CombinedNamespaceData, error) {
+	// A single namespace is queried directly, which also works without cluster-wide permissions
+	namespace := ""
+	if len(opts.NamespaceNames) == 1 {
+		namespace = opts.NamespaceNames[0]
+	}
+
+	metrics, resources, err := fetchPodMetricsAndResources(ctx, clientset, source,
+		namespace, opts.LabelSelector, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var quotas map[string]*pkg.NamespaceQuota
+	if opts.Quota {
+		quotas, err = pkg.GetNamespaceQuotas(ctx, clientset, namespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	combined := combineNamespaces(combineMetricsAndResources(metrics, resources), quotas)
+	combined = filterNamespaces(combined, opts.NamespaceNames)
+
+	// Sort based on sortBy parameter (default: by namespace name)
+	sortNamespaceData(combined, opts.SortBy)
+
+	return combined, nil
+}
+
+// printNamespaceData prints the combined namespace data in the requested output format
+func printNamespaceData(combined []CombinedNamespaceData, opts NamespaceOptions) error {
+	if isStructuredOutput(opts.Output) {
+		return printStructured(newNamespaceUsageList(combined), opts.Output)
+	}
+
+	if len(combined) == 0 {
+		fmt.Fprintf(os.Stderr, "No namespaces found\n")
+		return nil
+	}
+
+	printNamespaceTable(combined, namespaceTableOptions{
+		NoHeaders: opts.NoHeaders,
+		Quota:     opts.Quota,
+	})
+
+	return nil
+}
+
+// combineNamespaces sums the pods of every namespace and joins the namespace quotas.
+// Terminated pods are left out, as the quotas leave them out; namespaces with a quota but no
+// pods are listed too.
+func combineNamespaces(pods []CombinedPodData, quotas map[string]*pkg.NamespaceQuota) []CombinedNamespaceData {
+	combined := make([]CombinedNamespaceData, 0)
+	index := make(map[string]int)
+
+	entry := func(namespace string) *CombinedNamespaceData {
+		i, ok := index[namespace]
+		if !ok {
+			i = len(combined)
+			index[namespace] = i
+			combined = append(combined, CombinedNamespaceData{
+				podGroup: podGroup{CombinedPodData: CombinedPodData{Name: namespace}},
+				Quota:    quotas[namespace],
+			})
+		}
+		return &combined[i]
+	}
+
+	for _, p := range pods {
+		if p.Terminated {
+			continue
+		}
+		entry(p.Namespace).add(p)
+	}
+	for namespace := range quotas {
+		entry(namespace)
+	}
+
+	return combined
+}
+
+// filterNamespaces keeps the namespaces in names; no names keeps every namespace
+func filterNamespaces(data []CombinedNamespaceData, names []string) []CombinedNamespaceData {
+	if len(names) == 0 {
+		return data
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	filtered := make([]CombinedNamespaceData, 0, len(names))
+	for _, d := range data {
+		if wanted[d.Name] {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// efficiency returns usage as a percentage of the CPU and memory requests of the pods with metrics,
+// "-" when there is no usage or no request to compare against
+func (d CombinedNamespaceData) efficiency() (cpuPercent, memoryPercent string) {
+	cpuRequest, _, memoryRequest, _ := d.utilization()
+	return cpuRequest, memoryRequest
+}
+
+// quotaColumns formats the namespace quota as used/hard, "-" for resources without a quota
+func (d CombinedNamespaceData) quotaColumns() (cpuRequest, cpuLimit, memoryRequest, memoryLimit string) {
+	if d.Quota == nil {
+		return "-", "-", "-", "-"
+	}
+	return formatCPUQuota(d.Quota.CPURequest),
+		formatCPUQuota(d.Quota.CPULimit),
+		formatMemoryQuota(d.Quota.MemoryRequest),
+		formatMemoryQuota(d.Quota.MemoryLimit)
+}
+
+// formatCPUQuota formats a CPU quota as used/hard
+func formatCPUQuota(q pkg.QuotaUsage) string {
+	if !q.Limited {
+		return "-"
+	}
+	return formatCPUMilli(q.Used) + "/" + formatCPUMilli(q.Hard)
+}
+
+// formatMemoryQuota formats a memory quota as used/hard, both in the unit of the hard value
+func formatMemoryQuota(q pkg.QuotaUsage) string {
+	if !q.Limited {
+		return "-"
+	}
+	unit := pkg.MemoryUnit(q.Hard)
+	return formatMemoryBytesIn(q.Used, unit) + "/" + formatMemoryBytesIn(q.Hard, unit)
+}
+
+// namespaceTableOptions controls which columns printNamespaceTable prints
+type namespaceTableOptions struct {
+	NoHeaders bool
+	Quota     bool // Add used/hard ResourceQuota columns
+}
+
+// printNamespaceTable prints the combined namespace data in a formatted table
+func printNamespaceTable(data []CombinedNamespaceData, opts namespaceTableOptions) {
+	// Calculate column widths
+	nameWidth := 30
+	podsWidth := 4
+	cpuWidth := 12
+	memWidth := 15
+	percentWidth := 8
+	quotaWidth := 20
+
+	for _, d := range data {
+		if len(d.Name) > nameWidth {
+			nameWidth = len(d.Name)
+		}
+	}
+
+	// Print header unless --no-headers is set
+	if !opts.NoHeaders {
+		header := fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
+			nameWidth, "NAME",
+			podsWidth, "PODS",
+			cpuWidth, "CPU(cores)",
+			cpuWidth, "CPU REQUEST",
+			cpuWidth, "CPU LIMIT",
+			memWidth, "MEMORY(bytes)",
+			memWidth, "MEMORY REQUEST",
+			memWidth, "MEMORY LIMIT",
+			percentWidth, "CPU EFF%",
+			percentWidth, "MEM EFF%",
+		)
+		if opts.Quota {
+			header += fmt.Sprintf("  %-*s  %-*s  %-*s  %-*s",
+				quotaWidth, "CPU REQ QUOTA",
+				quotaWidth, "CPU LIM QUOTA",
+				quotaWidth, "MEM REQ QUOTA",
+				quotaWidth, "MEM LIM QUOTA",
+			)
+		}
+		fmt.Println(header)
+	}
+
+	// Print rows
+	for _, d := range data {
+		columns := d.columns()
+		cpuEfficiency, memoryEfficiency := d.efficiency()
+		row := fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
+			nameWidth, d.Name,
+			podsWidth, strconv.Itoa(d.Pods),
+			cpuWidth, columns.CPUUsage,
+			cpuWidth, columns.CPURequest,
+			cpuWidth, columns.CPULimit,
+			memWidth, columns.MemoryUsage,
+			memWidth, columns.MemoryRequest,
+			memWidth, columns.MemoryLimit,
+			percentWidth, cpuEfficiency,
+			percentWidth, memoryEfficiency,
+		)
+		if opts.Quota {
+			cpuRequestQuota, cpuLimitQuota, memoryRequestQuota, memoryLimitQuota := d.quotaColumns()
+			row += fmt.Sprintf("  %-*s  %-*s  %-*s  %-*s",
+				quotaWidth, cpuRequestQuota,
+				quotaWidth, cpuLimitQuota,
+				quotaWidth, memoryRequestQuota,
+				quotaWidth, memoryLimitQuota,
+			)
+		}
+		fmt.Println(row)
+	}
+}
+
+// sortNamespaceData sorts the combined namespace data based on the sortBy field.
+// Ties and unknown values keep the default name order.
+func sortNamespaceData(data []CombinedNamespaceData, sortBy string) {
+	sort.Slice(data, func(i, j int) bool {
+		return data[i].Name < data[j].Name
+	})
+
+	field, descending := parseSortBy(sortBy)
+	if field == "name" {
+		if descending {
+			sort.SliceStable(data, func(i, j int) bool { return data[i].Name > data[j].Name })
+		}
+		return
+	}
+	if value, ok := namespaceSortValues[field]; ok {
+		sortByValue(data, value, descending)
+	}
+}
+
+// NewNamespaceCommand creates a new namespace command
+func NewNamespaceCommand() *cobra.Command {
+	factory := newClientFactory()
+	var opts NamespaceOptions
+	var sourceOpts metricsSourceOptions
+
+	cmd := &cobra.Command{
+		Use:     "namespace [NAME | -l label]",
+		Aliases: []string{"namespaces", "ns"},
+		Short:   "Display resource usage (CPU, memory) and requests/limits aggregated per namespace",
+		Long: `Display resource usage (CPU, memory) and requests/limits aggregated per namespace.
+Each row shows the number of pods, the total usage, requests and limits, and the usage as a
+percentage of the requests (efficiency). --quota adds the used and hard values of the
+namespace ResourceQuotas.
+
+You can use 'namespace', 'namespaces', or 'ns' as the command name, just like kubectl.
+
+Examples:
+  # Show usage per namespace
+  kubectl rltop namespace
+
+  # Show the least efficient namespaces first
+  kubectl rltop ns --sort-by=cpu-util:asc
+
+  # Show a given namespace with its quota
+  kubectl rltop ns NAMESPACE --quota`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(opts.Output, namespaceOutputFormats); err != nil {
+				return err
+			}
+			if err := sourceOpts.validate(); err != nil {
+				return err
+			}
+			if err := validateWatchInterval(opts.Watch, opts.Interval); err != nil {
+				return err
+			}
+			if err := validateSortBy(opts.SortBy, namespaceSortFields); err != nil {
+				return err
+			}
+
+			// Extract namespace names from args
+			if len(args) > 0 {
+				opts.NamespaceNames = args
+			}
+
+			clientset, metricsClient, err := factory.Clients()
+			if err != nil {
+				return err
+			}
+			source := sourceOpts.newSource(clientset, metricsClient, pkg.UsageWindow{})
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			return RunNamespace(ctx, clientset, source, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.LabelSelector, "selector", "l", "",
+		"Selector (label query) on the pods to aggregate, supports '=', '==', and '!='.(e.g. -l key1=value1)")
+	cmd.Flags().BoolVar(&opts.Quota, "quota", false,
+		"If present, add the used/hard values of the namespace ResourceQuotas for CPU and memory requests and limits.")
+	cmd.Flags().StringVar(&opts.SortBy, "sort-by", "",
+		"If non-empty, sort namespaces list using specified field. One of: "+strings.Join(namespaceSortFields, ", ")+". "+
+			"'cpu-util' and 'memory-util' sort by efficiency (usage as a percentage of requests). "+
+			"Append ':asc' or ':desc' to choose the direction (numeric fields default to descending, names to ascending).")
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"If present, print output without headers.")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "",
+		"Output format. One of: (json, yaml).")
+	addWatchFlags(cmd, &opts.Watch, &opts.Interval)
+	addMetricsSourceFlags(cmd, &sourceOpts)
+	factory.AddFlags(cmd.Flags())
+
+	return cmd
+}
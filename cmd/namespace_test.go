@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+)
+
+func TestCombineNamespaces(t *testing.T) {
+	pods := []CombinedPodData{
+		{Namespace: "team-a", Name: "web-1", HasMetrics: true, CPUUsageMilli: 100, CPURequestMilli: 400},
+		{Namespace: "team-a", Name: "web-2", CPURequestMilli: 400},
+		{Namespace: "team-b", Name: "db-0", HasMetrics: true, CPUUsageMilli: 50, CPURequestMilli: 100},
+		// A completed Job pod holds no resources and is not counted by the quota
+		{Namespace: "team-a", Name: "backup-1", Terminated: true, CPURequestMilli: 1000},
+	}
+	quotas := map[string]*pkg.NamespaceQuota{
+		"team-a": {Namespace: "team-a", CPURequest: pkg.QuotaUsage{Limited: true, Used: 800, Hard: 2000}},
+		"empty":  {Namespace: "empty", CPURequest: pkg.QuotaUsage{Limited: true, Hard: 1000}},
+	}
+
+	combined := combineNamespaces(pods, quotas)
+	sortNamespaceData(combined, "")
+
+	if len(combined) != 3 {
+		t.Fatalf("combineNamespaces() returned %d namespaces, want 3: %+v", len(combined), combined)
+	}
+	if empty := combined[0]; empty.Name != "empty" || empty.Pods != 0 || empty.Quota == nil {
+		t.Errorf("combineNamespaces() namespace with a quota but no pods = %+v", empty)
+	}
+	teamA := combined[1]
+	if teamA.Name != "team-a" || teamA.Pods != 2 || teamA.CPUUsageMilli != 100 || teamA.CPURequestMilli != 800 {
+		t.Errorf("combineNamespaces() team-a = %+v", teamA)
+	}
+	if teamA.Quota == nil || teamA.Quota.CPURequest.Hard != 2000 {
+		t.Errorf("combineNamespaces() team-a quota = %+v", teamA.Quota)
+	}
+	// web-2 has no metrics: its request is left out of the efficiency
+	if cpu, _ := teamA.efficiency(); cpu != "25%" {
+		t.Errorf("efficiency() cpu = %s, want 25%%", cpu)
+	}
+	if teamB := combined[2]; teamB.Quota != nil {
+		t.Errorf("combineNamespaces() team-b should have no quota: %+v", teamB.Quota)
+	}
+
+	if filtered := filterNamespaces(combined, []string{"team-b"}); len(filtered) != 1 || filtered[0].Name != "team-b" {
+		t.Errorf("filterNamespaces() = %+v", filtered)
+	}
+}
+
+func TestFormatQuota(t *testing.T) {
+	if got := formatCPUQuota(pkg.QuotaUsage{Limited: true, Used: 1500, Hard: 4000}); got != "1500m/4000m" {
+		t.Errorf("formatCPUQuota() = %s, want 1500m/4000m", got)
+	}
+	if got := formatCPUQuota(pkg.QuotaUsage{}); got != "-" {
+		t.Errorf("formatCPUQuota() without quota = %s, want -", got)
+	}
+	if got := formatMemoryQuota(pkg.QuotaUsage{Limited: true, Used: 512 << 20, Hard: 2 << 30}); got != "0.50Gi/2.00Gi" {
+		t.Errorf("formatMemoryQuota() = %s, want 0.50Gi/2.00Gi", got)
+	}
+}
+
+func TestPrintNamespaceTableQuota(t *testing.T) {
+	data := []CombinedNamespaceData{
+		{
+			podGroup: podGroup{CombinedPodData: CombinedPodData{Name: "team-a"}, Pods: 2},
+			Quota:    &pkg.NamespaceQuota{CPURequest: pkg.QuotaUsage{Limited: true, Used: 800, Hard: 2000}},
+		},
+	}
+
+	output := captureStdout(t, func() {
+		printNamespaceTable(data, namespaceTableOptions{Quota: true})
+	})
+
+	for _, col := range []string{"PODS", "CPU EFF%", "MEM EFF%", "CPU REQ QUOTA", "MEM LIM QUOTA", "800m/2000m"} {
+		if !strings.Contains(output, col) {
+			t.Errorf("printNamespaceTable() output missing %s. Output: %s", col, output)
+		}
+	}
+}
+
+func TestNewNamespaceUsageList(t *testing.T) {
+	data := []CombinedNamespaceData{
+		{
+			podGroup: podGroup{
+				CombinedPodData: CombinedPodData{
+					Name: "team-a", HasMetrics: true, CPUUsageMilli: 100, CPURequestMilli: 800,
+				},
+				Pods:                    2,
+				PodsWithMetrics:         1,
+				MeasuredCPURequestMilli: 400,
+			},
+			Quota: &pkg.NamespaceQuota{CPURequest: pkg.QuotaUsage{Limited: true, Used: 800, Hard: 2000}},
+		},
+		{podGroup: podGroup{CombinedPodData: CombinedPodData{Name: "team-b"}, Pods: 1}},
+	}
+
+	list := newNamespaceUsageList(data)
+	if list.Kind != "NamespaceUsageList" || len(list.Items) != 2 {
+		t.Fatalf("newNamespaceUsageList() = %+v", list)
+	}
+	teamA := list.Items[0]
+	if u := teamA.CPU.RequestUtilization; u == nil || u.Percent != 25 {
+		t.Errorf("newNamespaceUsageList() team-a efficiency = %+v", u)
+	}
+	if q := teamA.Quota; q == nil || q.CPURequest == nil || q.CPURequest.Hard.Millicores != 2000 || q.CPULimit != nil {
+		t.Errorf("newNamespaceUsageList() team-a quota = %+v", q)
+	}
+	if list.Items[1].Quota != nil {
+		t.Errorf("newNamespaceUsageList() quota should be omitted without a ResourceQuota")
+	}
+}
@@ -5,46 +5,93 @@ import (
 	"fmt"
 	"os"
 	"sort"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/veditoid/kubectl-rltop/pkg"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
-	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 // CombinedNodeData represents combined node metrics and aggregated pod resources
 type CombinedNodeData struct {
-	Name          string
-	CPUUsage      string
-	CPUPercent    string
-	CPURequest    string
-	CPULimit      string
-	MemoryUsage   string
-	MemoryPercent string
-	MemoryRequest string
-	MemoryLimit   string
+	Name   string
+	Status string // Ready, NotReady or Unknown, plus SchedulingDisabled; empty if the node object is missing
+
+	// CPU in millicores and memory in bytes, formatted only when printed.
+	// Allocatable holds capacity instead when --show-capacity is set; zero means unknown.
+	HasMetrics             bool
+	CPUUsageMilli          int64
+	CPURequestMilli        int64
+	CPULimitMilli          int64
+	CPUAllocatableMilli    int64
+	MemoryUsageBytes       int64
+	MemoryRequestBytes     int64
+	MemoryLimitBytes       int64
+	MemoryAllocatableBytes int64
+}
+
+// NodeOptions holds the options of the node command
+type NodeOptions struct {
+	LabelSelector string
+	NodeNames     []string
+	ShowCapacity  bool
+	// Count requests of Succeeded and Failed pods in the node totals
+	IncludeTerminated bool
+	// List the pods scheduled on each node under it
+	Pods bool
+	// Aggregate usage over a window (--window, --stat); zero for the current usage
+	Usage     pkg.UsageWindow
+	SortBy    string
+	NoHeaders bool
+	Output    string
+	Watch     bool
+	Interval  time.Duration
 }
 
 // RunNode executes the node command
 func RunNode(
 	ctx context.Context,
 	clientset kubernetes.Interface,
-	metricsClient metricsclientset.Interface,
-	labelSelector string,
-	nodeNames []string,
-	showCapacity bool,
-	sortBy string,
-	noHeaders bool,
+	source pkg.MetricsSource,
+	opts NodeOptions,
 ) error {
-	// Check if Metrics API is available
-	if err := pkg.CheckMetricsAPIAvailable(ctx, clientset); err != nil {
-		return fmt.Errorf("metrics API not available: %w\nPlease ensure metrics-server is installed in your cluster", err)
+	// Check the metrics source is available (only once, also in watch mode)
+	if err := source.Check(ctx); err != nil {
+		return err
 	}
 
+	refresh := func(ctx context.Context) error {
+		if opts.Pods {
+			data, err := fetchNodePodsData(ctx, clientset, source, opts)
+			if err != nil {
+				return err
+			}
+			return printNodePodsData(data, opts)
+		}
+
+		combined, err := fetchNodeData(ctx, clientset, source, opts)
+		if err != nil {
+			return err
+		}
+		return printNodeData(combined, opts)
+	}
+
+	if opts.Watch {
+		return runWatch(ctx, opts.Interval, opts.Output, refresh)
+	}
+	return refresh(ctx)
+}
+
+// fetchNodeData fetches node metrics, node resources, and aggregated pod resources in parallel
+// and returns them combined and sorted
+func fetchNodeData(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	opts NodeOptions,
+) ([]CombinedNodeData, error) {
 	// Fetch node metrics, node resources, and aggregated pod resources in parallel
 	nodeMetricsChan := make(chan []pkg.NodeMetrics, 1)
 	nodeResourcesChan := make(chan map[string]*pkg.NodeAggregatedResources, 1)
@@ -52,7 +99,7 @@ func RunNode(
 	errChan := make(chan error, 3)
 
 	go func() {
-		metrics, err := pkg.GetNodeMetrics(ctx, metricsClient, labelSelector, nodeNames)
+		metrics, err := source.NodeMetrics(ctx, opts.LabelSelector, opts.NodeNames)
 		if err != nil {
 			errChan <- err
 			return
@@ -61,7 +108,7 @@ func RunNode(
 	}()
 
 	go func() {
-		resources, err := pkg.AggregatePodResourcesByNode(ctx, clientset)
+		resources, err := pkg.AggregatePodResourcesByNode(ctx, clientset, opts.IncludeTerminated)
 		if err != nil {
 			errChan <- err
 			return
@@ -70,7 +117,7 @@ func RunNode(
 	}()
 
 	go func() {
-		nodes, err := pkg.GetNodeResources(ctx, clientset, labelSelector, nodeNames, showCapacity)
+		nodes, err := pkg.GetNodeResources(ctx, clientset, opts.LabelSelector, opts.NodeNames, opts.ShowCapacity)
 		if err != nil {
 			errChan <- err
 			return
@@ -86,7 +133,7 @@ func RunNode(
 	for i := 0; i < 3; i++ {
 		select {
 		case err := <-errChan:
-			return err
+			return nil, err
 		case nodeMetrics = <-nodeMetricsChan:
 		case nodeResources = <-nodeResourcesChan:
 		case nodes = <-nodesChan:
@@ -94,210 +141,202 @@ func RunNode(
 	}
 
 	// Combine metrics and resources
-	combined := combineNodeMetricsAndResources(nodeMetrics, nodeResources, nodes, showCapacity)
+	combined := combineNodeMetricsAndResources(nodeMetrics, nodeResources, nodes, opts.ShowCapacity)
+
+	// Sort based on sortBy parameter (default: by node name)
+	sortNodeData(combined, opts.SortBy)
+
+	return combined, nil
+}
+
+// printNodeData prints the combined node data in the requested output format
+func printNodeData(combined []CombinedNodeData, opts NodeOptions) error {
+	if isStructuredOutput(opts.Output) {
+		list := newNodeUsageList(combined)
+		list.Aggregation = newUsageAggregation(opts.Usage)
+		return printStructured(list, opts.Output)
+	}
 
 	if len(combined) == 0 {
 		fmt.Fprintf(os.Stderr, "No nodes found\n")
 		return nil
 	}
 
-	// Sort based on sortBy parameter
-	if sortBy != "" {
-		sortNodeData(combined, sortBy)
-	} else {
-		// Default: sort by node name
-		sort.Slice(combined, func(i, j int) bool {
-			return combined[i].Name < combined[j].Name
-		})
-	}
-
 	// Print table
-	printNodeTable(combined, noHeaders)
+	printNodeTable(combined, nodeTableOptions{
+		NoHeaders: opts.NoHeaders,
+		Wide:      opts.Output == outputWide,
+		Usage:     opts.Usage,
+	})
 
 	return nil
 }
 
-// combineNodeMetricsAndResources merges node metrics with aggregated pod resources
+// combineNodeMetricsAndResources merges node metrics with aggregated pod resources.
+// Every node is listed, including nodes metrics-server has not scraped yet (NotReady, just joined,
+// kubelet down), since their pods still hold requests.
 func combineNodeMetricsAndResources(
 	metrics []pkg.NodeMetrics,
 	resources map[string]*pkg.NodeAggregatedResources,
 	nodes map[string]*corev1.Node,
 	showCapacity bool,
 ) []CombinedNodeData {
-	combined := make([]CombinedNodeData, 0, len(metrics))
+	metricsMap := make(map[string]pkg.NodeMetrics, len(metrics))
+	for _, m := range metrics {
+		metricsMap[m.Name] = m
+	}
 
+	// Nodes from the node list, plus nodes that only show up in metrics
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
 	for _, m := range metrics {
-		node := nodes[m.Name]
-		aggResources := resources[m.Name]
-
-		// Calculate percentages if we have node info
-		cpuPercent := "-"
-		memoryPercent := "-"
-		if node != nil {
-			// Parse CPU usage to get millicores
-			cpuUsageMilli := parseCPUValueForNode(m.CPU)
-			memoryUsageBytes := parseMemoryValueForNode(m.Memory)
-			cpuPercent, memoryPercent = pkg.CalculateNodePercentages(node, int64(cpuUsageMilli), memoryUsageBytes, showCapacity)
+		if _, ok := nodes[m.Name]; !ok {
+			names = append(names, m.Name)
 		}
+	}
 
-		// Format aggregated resources
-		var cpuRequest, cpuLimit, memRequest, memLimit string
-		if aggResources != nil {
-			if !aggResources.CPURequest.IsZero() {
-				cpuRequest = pkg.FormatResourceQuantity(aggResources.CPURequest, true)
-			} else {
-				cpuRequest = "-"
-			}
-			if !aggResources.CPULimit.IsZero() {
-				cpuLimit = pkg.FormatResourceQuantity(aggResources.CPULimit, true)
-			} else {
-				cpuLimit = "-"
-			}
-
-			// Normalize memory to match usage unit
-			memoryUnit := pkg.ExtractMemoryUnit(m.Memory)
-			if !aggResources.MemoryRequest.IsZero() {
-				memRequest = pkg.FormatMemoryInUnit(aggResources.MemoryRequest, memoryUnit)
-			} else {
-				memRequest = "-"
-			}
-			if !aggResources.MemoryLimit.IsZero() {
-				memLimit = pkg.FormatMemoryInUnit(aggResources.MemoryLimit, memoryUnit)
-			} else {
-				memLimit = "-"
-			}
-		} else {
-			cpuRequest = "-"
-			cpuLimit = "-"
-			memRequest = "-"
-			memLimit = "-"
+	combined := make([]CombinedNodeData, 0, len(names))
+	for _, name := range names {
+		row := CombinedNodeData{Name: name}
+		if m, ok := metricsMap[name]; ok {
+			row.HasMetrics = true
+			row.CPUUsageMilli = m.CPUMilli
+			row.MemoryUsageBytes = m.MemoryBytes
 		}
-
-		combined = append(combined, CombinedNodeData{
-			Name:          m.Name,
-			CPUUsage:      m.CPU,
-			CPUPercent:    cpuPercent,
-			CPURequest:    cpuRequest,
-			CPULimit:      cpuLimit,
-			MemoryUsage:   m.Memory,
-			MemoryPercent: memoryPercent,
-			MemoryRequest: memRequest,
-			MemoryLimit:   memLimit,
-		})
+		if node := nodes[name]; node != nil {
+			row.Status = pkg.NodeStatus(node)
+			row.CPUAllocatableMilli, row.MemoryAllocatableBytes = pkg.NodeTotals(node, showCapacity)
+		}
+		if aggResources := resources[name]; aggResources != nil {
+			row.CPURequestMilli = aggResources.CPURequest.MilliValue()
+			row.CPULimitMilli = aggResources.CPULimit.MilliValue()
+			row.MemoryRequestBytes = aggResources.MemoryRequest.Value()
+			row.MemoryLimitBytes = aggResources.MemoryLimit.Value()
+		}
+		combined = append(combined, row)
 	}
 
 	return combined
 }
 
+// nodeTableOptions controls which columns printNodeTable prints
+type nodeTableOptions struct {
+	NoHeaders bool
+	Wide      bool            // Add a STATUS column and request/limit percentages of allocatable
+	Usage     pkg.UsageWindow // Labels the usage columns with the statistic when aggregated over a window
+}
+
 // printNodeTable prints the combined node data in a formatted table
-func printNodeTable(data []CombinedNodeData, noHeaders bool) {
+func printNodeTable(data []CombinedNodeData, opts nodeTableOptions) {
 	// Calculate column widths
 	nameWidth := 50
+	statusWidth := 8
 	cpuWidth := 12
 	percentWidth := 7
 	memWidth := 15
+	commitmentWidth := 8
 
 	for _, d := range data {
 		if len(d.Name) > nameWidth {
 			nameWidth = len(d.Name)
 		}
+		if len(d.Status) > statusWidth {
+			statusWidth = len(d.Status)
+		}
 	}
 
 	// Print header unless --no-headers is set
-	if !noHeaders {
-		header := fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
-			nameWidth, "NAME",
-			cpuWidth, "CPU(cores)",
+	if !opts.NoHeaders {
+		header := fmt.Sprintf("%-*s  ", nameWidth, "NAME")
+		if opts.Wide {
+			header += fmt.Sprintf("%-*s  ", statusWidth, "STATUS")
+		}
+		cpuUsage, memoryUsage := usageHeaders(opts.Usage)
+		header += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
+			cpuWidth, cpuUsage,
 			percentWidth, "CPU%",
 			cpuWidth, "CPU REQUEST",
 			cpuWidth, "CPU LIMIT",
-			memWidth, "MEMORY(bytes)",
+			memWidth, memoryUsage,
 			percentWidth, "MEMORY%",
 			memWidth, "MEMORY REQUEST",
 			memWidth, "MEMORY LIMIT",
 		)
+		if opts.Wide {
+			header += fmt.Sprintf("  %-*s  %-*s  %-*s  %-*s",
+				commitmentWidth, "CPU REQ%",
+				commitmentWidth, "CPU LIM%",
+				commitmentWidth, "MEM REQ%",
+				commitmentWidth, "MEM LIM%",
+			)
+		}
 		fmt.Println(header)
 	}
 
 	// Print rows
 	for _, d := range data {
-		row := fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
-			nameWidth, d.Name,
-			cpuWidth, d.CPUUsage,
-			percentWidth, d.CPUPercent,
-			cpuWidth, d.CPURequest,
-			cpuWidth, d.CPULimit,
-			memWidth, d.MemoryUsage,
-			percentWidth, d.MemoryPercent,
-			memWidth, d.MemoryRequest,
-			memWidth, d.MemoryLimit,
+		columns := d.columns()
+		cpuPercent, memoryPercent := d.percentages()
+		row := fmt.Sprintf("%-*s  ", nameWidth, d.Name)
+		if opts.Wide {
+			status := d.Status
+			if status == "" {
+				status = unknownValue
+			}
+			row += fmt.Sprintf("%-*s  ", statusWidth, status)
+		}
+		row += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
+			cpuWidth, columns.CPUUsage,
+			percentWidth, cpuPercent,
+			cpuWidth, columns.CPURequest,
+			cpuWidth, columns.CPULimit,
+			memWidth, columns.MemoryUsage,
+			percentWidth, memoryPercent,
+			memWidth, columns.MemoryRequest,
+			memWidth, columns.MemoryLimit,
 		)
+		if opts.Wide {
+			cpuRequestPercent, cpuLimitPercent, memoryRequestPercent, memoryLimitPercent := d.commitments()
+			row += fmt.Sprintf("  %-*s  %-*s  %-*s  %-*s",
+				commitmentWidth, cpuRequestPercent,
+				commitmentWidth, cpuLimitPercent,
+				commitmentWidth, memoryRequestPercent,
+				commitmentWidth, memoryLimitPercent,
+			)
+		}
 		fmt.Println(row)
 	}
 }
 
-const unknownValue = "<unknown>"
-
-// parseCPUValueForNode parses CPU string to float64 for node calculations (handles "m" suffix)
-func parseCPUValueForNode(cpuStr string) float64 {
-	if cpuStr == "" || cpuStr == "-" || cpuStr == unknownValue {
-		return 0
-	}
-	// Remove "m" suffix and convert to float
-	cpuStr = strings.TrimSuffix(cpuStr, "m")
-	value, _ := strconv.ParseFloat(cpuStr, 64)
-	return value
-}
-
-// parseMemoryValueForNode parses memory string to bytes for node calculations
-func parseMemoryValueForNode(memStr string) int64 {
-	if memStr == "" || memStr == "-" || memStr == unknownValue {
-		return 0
-	}
-	// Simple parsing - convert common units to bytes
-	if strings.HasSuffix(memStr, "Gi") {
-		var value float64
-		_, _ = fmt.Sscanf(memStr, "%fGi", &value)
-		return int64(value * 1024 * 1024 * 1024)
-	}
-	if strings.HasSuffix(memStr, "Mi") {
-		var value float64
-		_, _ = fmt.Sscanf(memStr, "%fMi", &value)
-		return int64(value * 1024 * 1024)
-	}
-	if strings.HasSuffix(memStr, "Ki") {
-		var value float64
-		_, _ = fmt.Sscanf(memStr, "%fKi", &value)
-		return int64(value * 1024)
-	}
-	return 0
-}
-
-// sortNodeData sorts the combined node data based on the sortBy field
+// sortNodeData sorts the combined node data based on the sortBy field.
+// Ties and unknown values keep the default name order.
 func sortNodeData(data []CombinedNodeData, sortBy string) {
-	switch sortBy {
-	case "cpu":
-		sort.Slice(data, func(i, j int) bool {
-			return parseCPUValueForNode(data[i].CPUUsage) > parseCPUValueForNode(data[j].CPUUsage)
-		})
-	case "memory":
-		sort.Slice(data, func(i, j int) bool {
-			return parseMemoryValueForNode(data[i].MemoryUsage) > parseMemoryValueForNode(data[j].MemoryUsage)
-		})
-	default:
-		// Default: sort by name
-		sort.Slice(data, func(i, j int) bool {
-			return data[i].Name < data[j].Name
-		})
+	sort.Slice(data, func(i, j int) bool {
+		return data[i].Name < data[j].Name
+	})
+
+	field, descending := parseSortBy(sortBy)
+	if field == "name" {
+		if descending {
+			sort.SliceStable(data, func(i, j int) bool { return data[i].Name > data[j].Name })
+		}
+		return
+	}
+	if value, ok := nodeSortValues[field]; ok {
+		sortByValue(data, value, descending)
 	}
 }
 
 // NewNodeCommand creates a new node command
 func NewNodeCommand() *cobra.Command {
-	var labelSelector string
-	var showCapacity bool
-	var sortBy string
-	var noHeaders bool
+	factory := newClientFactory()
+	var opts NodeOptions
+	var sourceOpts metricsSourceOptions
+	var window time.Duration
+	var usageStat string
+	var fromFile, at string
 	var useProtocolBuffers bool
 
 	cmd := &cobra.Command{
@@ -318,83 +357,112 @@ Examples:
   kubectl rltop node NODE_NAME
   
   # Show metrics for nodes defined by label
-  kubectl rltop node -l node-role.kubernetes.io/worker`,
+  kubectl rltop node -l node-role.kubernetes.io/worker
+
+  # Show the pods scheduled on a given node, with their usage, requests and limits
+  kubectl rltop node NODE_NAME --pods
+
+  # Show node status and requests/limits as a percentage of allocatable
+  kubectl rltop node -o wide
+
+  # Print metrics as YAML for use in scripts
+  kubectl rltop node -o yaml
+
+  # Show the peak usage over the last week, read from Prometheus
+  kubectl rltop node --metrics-source=prometheus --prometheus-url=http://localhost:9090 --window=168h --stat=max
+
+  # Show the nodes as they were at noon, from a recording made with 'rltop record'
+  kubectl rltop node --from-file incident.jsonl --at=2024-05-01T12:00:00Z
+
+  # Refresh the table every 15 seconds until interrupted
+  kubectl rltop node --watch`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(opts.Output, nodeOutputFormats); err != nil {
+				return err
+			}
+			replayAt, err := validateFromFile(cmd, fromFile, at, "selector", "include-terminated", "pods", "watch",
+				"window", "stat", "metrics-source", "prometheus-url")
+			if err != nil {
+				return err
+			}
+			if err := sourceOpts.validate(); err != nil {
+				return err
+			}
+			usage, err := parseUsageWindow(window, usageStat, cmd.Flags().Changed("stat"), sourceOpts)
+			if err != nil {
+				return err
+			}
+			opts.Usage = usage
+			if err := validateWatchInterval(opts.Watch, opts.Interval); err != nil {
+				return err
+			}
+			if err := validateSortBy(opts.SortBy, nodeSortFields); err != nil {
+				return err
+			}
+
 			// Extract node names from args
-			var nodeNames []string
 			if len(args) > 0 {
-				nodeNames = args
+				opts.NodeNames = args
+			}
+			if err := validateNodePods(opts.Pods, opts.NodeNames, opts.LabelSelector); err != nil {
+				return err
 			}
 
 			// Note: --use-protocol-buffers is not yet implemented but we accept the flag for compatibility
 			_ = useProtocolBuffers
 
-			// Use RESTClientGetter pattern - same as kubectl plugins use
-			loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-			configOverrides := &clientcmd.ConfigOverrides{}
-
-			clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-				loadingRules,
-				configOverrides,
-			)
-
-			// Get REST config
-			config, err := clientConfig.ClientConfig()
-			if err != nil {
-				errMsg := err.Error()
-				if strings.Contains(errMsg, "exec plugin") && strings.Contains(errMsg, "apiVersion") {
-					return fmt.Errorf("failed to load kubeconfig: %w. "+
-						"Your kubeconfig uses an exec plugin with an outdated API version. "+
-						"To fix this, update your kubeconfig by running: "+
-						"kubectl config view --raw > ~/.kube/config.new && "+
-						"mv ~/.kube/config.new ~/.kube/config. "+
-						"Or regenerate your kubeconfig using your cloud provider's CLI tool", err)
-				}
-				return fmt.Errorf("failed to load kubeconfig: %w", err)
-			}
-
-			// Create clients
-			clientset, err := kubernetes.NewForConfig(config)
-			if err != nil {
-				errMsg := err.Error()
-				if strings.Contains(errMsg, "exec plugin") && strings.Contains(errMsg, "apiVersion") {
-					return fmt.Errorf("failed to create kubernetes client: %w. "+
-						"Your kubeconfig uses an exec plugin with an outdated API version (v1alpha1). "+
-						"This version of kubectl-rltop requires exec plugins to use v1beta1 or v1. "+
-						"To fix this, update your kubeconfig: "+
-						"1. Run: kubectl config view --raw > ~/.kube/config.new "+
-						"2. Check the file and update any exec plugin apiVersion from v1alpha1 to v1beta1 "+
-						"3. Replace: mv ~/.kube/config.new ~/.kube/config. "+
-						"Or regenerate your kubeconfig using your cloud provider's CLI tool", err)
+			// A recording is replayed without the cluster
+			if fromFile != "" {
+				snapshot, err := readSnapshotFile(fromFile, replayAt)
+				if err != nil {
+					return err
 				}
-				return fmt.Errorf("failed to create kubernetes client: %w", err)
+				return RunNodeSnapshot(snapshot, opts)
 			}
 
-			metricsClient, err := metricsclientset.NewForConfig(config)
+			clientset, metricsClient, err := factory.Clients()
 			if err != nil {
-				return fmt.Errorf("failed to create metrics client: %w", err)
+				return err
 			}
+			source := sourceOpts.newSource(clientset, metricsClient, opts.Usage)
 
 			ctx := cmd.Context()
 			if ctx == nil {
 				ctx = context.Background()
 			}
 
-			return RunNode(ctx, clientset, metricsClient, labelSelector, nodeNames, showCapacity, sortBy, noHeaders)
+			return RunNode(ctx, clientset, source, opts)
 		},
 	}
 
 	// Add all flags matching kubectl top node
-	cmd.Flags().StringVarP(&labelSelector, "selector", "l", "",
+	cmd.Flags().StringVarP(&opts.LabelSelector, "selector", "l", "",
 		"Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
-	cmd.Flags().BoolVar(&showCapacity, "show-capacity", false,
+	cmd.Flags().BoolVar(&opts.ShowCapacity, "show-capacity", false,
 		"Print node resources based on Capacity instead of Allocatable(default) of the nodes.")
-	cmd.Flags().StringVar(&sortBy, "sort-by", "",
-		"If non-empty, sort nodes list using specified field. The field can be either 'cpu' or 'memory'.")
-	cmd.Flags().BoolVar(&noHeaders, "no-headers", false,
+	cmd.Flags().BoolVar(&opts.IncludeTerminated, "include-terminated", false,
+		"If present, count the requests and limits of terminated (Succeeded or Failed) pods in the node totals.")
+	cmd.Flags().BoolVar(&opts.Pods, "pods", false,
+		"If present, list the pods scheduled on each node with their usage, requests and limits. "+
+			"Requires a node NAME or a label selector. --sort-by also sorts the pods.")
+	cmd.Flags().StringVar(&opts.SortBy, "sort-by", "",
+		"If non-empty, sort nodes list using specified field. One of: "+strings.Join(nodeSortFields, ", ")+". "+
+			"'cpu-util' and 'memory-util' compare usage with allocatable, 'cpu-headroom' and 'memory-headroom' "+
+			"are the allocatable minus usage. Append ':asc' or ':desc' to choose the direction "+
+			"(numeric fields default to descending, names to ascending).")
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
 		"If present, print output without headers.")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "",
+		"Output format. One of: (json, yaml, wide). Defaults to a human-readable table. "+
+			"'wide' adds a STATUS column (Ready, NotReady, SchedulingDisabled) and CPU REQ%, CPU LIM%, "+
+			"MEM REQ% and MEM LIM% columns comparing requests and limits with allocatable (or capacity).")
+	addWatchFlags(cmd, &opts.Watch, &opts.Interval)
 	cmd.Flags().BoolVar(&useProtocolBuffers, "use-protocol-buffers", true,
 		"Enables using protocol-buffers to access Metrics API.")
+	addMetricsSourceFlags(cmd, &sourceOpts)
+	addUsageWindowFlags(cmd, &window, &usageStat)
+	addFromFileFlags(cmd, &fromFile, &at)
+	factory.AddFlags(cmd.Flags())
 
 	return cmd
 }
@@ -24,9 +24,9 @@ func TestCombineNodeMetricsAndResources(t *testing.T) {
 			name: "combine metrics and resources",
 			metrics: []pkg.NodeMetrics{
 				{
-					Name:   "node1",
-					CPU:    "100m",
-					Memory: "128Mi",
+					Name:        "node1",
+					CPUMilli:    100,
+					MemoryBytes: 128 * 1024 * 1024,
 				},
 			},
 			resources: map[string]*pkg.NodeAggregatedResources{
@@ -56,9 +56,9 @@ func TestCombineNodeMetricsAndResources(t *testing.T) {
 			name: "node without resources",
 			metrics: []pkg.NodeMetrics{
 				{
-					Name:   "node1",
-					CPU:    "100m",
-					Memory: "128Mi",
+					Name:        "node1",
+					CPUMilli:    100,
+					MemoryBytes: 128 * 1024 * 1024,
 				},
 			},
 			resources: map[string]*pkg.NodeAggregatedResources{},
@@ -80,9 +80,9 @@ func TestCombineNodeMetricsAndResources(t *testing.T) {
 			name: "node without node info",
 			metrics: []pkg.NodeMetrics{
 				{
-					Name:   "node1",
-					CPU:    "100m",
-					Memory: "128Mi",
+					Name:        "node1",
+					CPUMilli:    100,
+					MemoryBytes: 128 * 1024 * 1024,
 				},
 			},
 			resources: map[string]*pkg.NodeAggregatedResources{
@@ -108,11 +108,12 @@ func TestCombineNodeMetricsAndResources(t *testing.T) {
 				if result[0].Name == "" {
 					t.Errorf("combineNodeMetricsAndResources() node name is empty")
 				}
-				if result[0].CPUUsage == "" {
-					t.Errorf("combineNodeMetricsAndResources() CPU usage is empty")
+				if result[0].CPUUsageMilli != 100 {
+					t.Errorf("combineNodeMetricsAndResources() CPU usage = %d, want 100", result[0].CPUUsageMilli)
 				}
-				if result[0].MemoryUsage == "" {
-					t.Errorf("combineNodeMetricsAndResources() memory usage is empty")
+				if result[0].MemoryUsageBytes != 128*1024*1024 {
+					t.Errorf("combineNodeMetricsAndResources() memory usage = %d, want %d",
+						result[0].MemoryUsageBytes, 128*1024*1024)
 				}
 			}
 		})
@@ -121,9 +122,21 @@ func TestCombineNodeMetricsAndResources(t *testing.T) {
 
 func TestSortNodeData(t *testing.T) {
 	data := []CombinedNodeData{
-		{Name: "node3", CPUUsage: "300m", MemoryUsage: "3Gi"},
-		{Name: "node1", CPUUsage: "100m", MemoryUsage: "1Gi"},
-		{Name: "node2", CPUUsage: "200m", MemoryUsage: "2Gi"},
+		{
+			Name: "node3", HasMetrics: true,
+			CPUUsageMilli: 300, MemoryUsageBytes: 3 << 30, CPURequestMilli: 100,
+			CPUAllocatableMilli: 4000, MemoryAllocatableBytes: 4 << 30,
+		},
+		{
+			Name: "node1", HasMetrics: true,
+			CPUUsageMilli: 100, MemoryUsageBytes: 1 << 30, CPURequestMilli: 300,
+			CPUAllocatableMilli: 1000, MemoryAllocatableBytes: 4 << 30,
+		},
+		{
+			Name: "node2", HasMetrics: true,
+			CPUUsageMilli: 200, MemoryUsageBytes: 2 << 30, CPURequestMilli: 200,
+			CPUAllocatableMilli: 8000, MemoryAllocatableBytes: 4 << 30,
+		},
 	}
 
 	tests := []struct {
@@ -141,6 +154,31 @@ func TestSortNodeData(t *testing.T) {
 			sortBy:   "memory",
 			expected: "node3",
 		},
+		{
+			name:     "sort by CPU ascending",
+			sortBy:   "cpu:asc",
+			expected: "node1",
+		},
+		{
+			name:     "sort by CPU request descending",
+			sortBy:   "cpu-request",
+			expected: "node1",
+		},
+		{
+			name:     "sort by CPU utilization descending",
+			sortBy:   "cpu-util",
+			expected: "node1",
+		},
+		{
+			name:     "sort by CPU headroom descending",
+			sortBy:   "cpu-headroom",
+			expected: "node2",
+		},
+		{
+			name:     "sort by name descending",
+			sortBy:   "name:desc",
+			expected: "node3",
+		},
 		{
 			name:     "sort by name (default)",
 			sortBy:   "",
@@ -174,15 +212,16 @@ func TestSortNodeData(t *testing.T) {
 func TestPrintNodeTable(t *testing.T) {
 	data := []CombinedNodeData{
 		{
-			Name:          "node1",
-			CPUUsage:      "100m",
-			CPUPercent:    "2%",
-			CPURequest:    "200m",
-			CPULimit:      "400m",
-			MemoryUsage:   "128Mi",
-			MemoryPercent: "1%",
-			MemoryRequest: "256Mi",
-			MemoryLimit:   "512Mi",
+			Name:                   "node1",
+			HasMetrics:             true,
+			CPUUsageMilli:          100,
+			CPURequestMilli:        200,
+			CPULimitMilli:          400,
+			CPUAllocatableMilli:    4000,
+			MemoryUsageBytes:       128 * 1024 * 1024,
+			MemoryRequestBytes:     256 * 1024 * 1024,
+			MemoryLimitBytes:       512 * 1024 * 1024,
+			MemoryAllocatableBytes: 8 * 1024 * 1024 * 1024,
 		},
 	}
 
@@ -195,7 +234,9 @@ func TestPrintNodeTable(t *testing.T) {
 			name:      "with headers",
 			noHeaders: false,
 			checkFunc: func(output string) bool {
-				return strings.Contains(output, "NAME") && strings.Contains(output, "CPU(cores)")
+				return strings.Contains(output, "NAME") && strings.Contains(output, "CPU(cores)") &&
+					strings.Contains(output, "100m") && strings.Contains(output, "2%") &&
+					strings.Contains(output, "128.00Mi") && strings.Contains(output, "256.00Mi")
 			},
 		},
 		{
@@ -214,7 +255,7 @@ func TestPrintNodeTable(t *testing.T) {
 			r, w, _ := os.Pipe()
 			os.Stdout = w
 
-			printNodeTable(data, tt.noHeaders)
+			printNodeTable(data, nodeTableOptions{NoHeaders: tt.noHeaders})
 
 			_ = w.Close()
 			os.Stdout = oldStdout
@@ -232,3 +273,89 @@ func TestPrintNodeTable(t *testing.T) {
 
 // Note: TestRunNode is skipped here as it requires complex mocking of metricsclientset.Interface
 // and CheckMetricsAPIAvailable. It will be tested in integration tests instead.
+
+func TestCombineNodeMetricsAndResourcesWithoutMetrics(t *testing.T) {
+	metrics := []pkg.NodeMetrics{
+		{Name: "node1", CPUMilli: 500, MemoryBytes: 1 << 30},
+	}
+	resources := map[string]*pkg.NodeAggregatedResources{
+		"node2": {NodeName: "node2", CPURequest: resource.MustParse("750m")},
+	}
+	nodes := map[string]*corev1.Node{
+		"node1": {
+			ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		"node2": {
+			ObjectMeta: metav1.ObjectMeta{Name: "node2"},
+			Spec:       corev1.NodeSpec{Unschedulable: true},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}},
+			},
+		},
+	}
+
+	result := combineNodeMetricsAndResources(metrics, resources, nodes, false)
+	sortNodeData(result, "")
+	if len(result) != 2 {
+		t.Fatalf("combineNodeMetricsAndResources() returned %d nodes, want 2", len(result))
+	}
+
+	node2 := result[1]
+	if node2.Name != "node2" || node2.HasMetrics || node2.CPURequestMilli != 750 {
+		t.Errorf("combineNodeMetricsAndResources() node2 = %+v, want no metrics and 750m requested", node2)
+	}
+	if node2.Status != "NotReady,SchedulingDisabled" {
+		t.Errorf("combineNodeMetricsAndResources() node2 status = %q", node2.Status)
+	}
+
+	output := captureStdout(t, func() {
+		printNodeTable(result, nodeTableOptions{Wide: true})
+	})
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "STATUS") {
+		t.Fatalf("printNodeTable() wide output = %s", output)
+	}
+	fields := strings.Fields(lines[2])
+	want := []string{"node2", "NotReady,SchedulingDisabled", unknownValue, unknownValue, "750m"}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("printNodeTable() node2 row = %v, want prefix %v", fields, want)
+			break
+		}
+	}
+}
+
+func TestPrintNodeTableCommitments(t *testing.T) {
+	data := []CombinedNodeData{
+		{
+			Name: "node1", Status: "Ready", HasMetrics: true,
+			CPUUsageMilli: 1000, CPURequestMilli: 3000, CPULimitMilli: 6000, CPUAllocatableMilli: 4000,
+			MemoryUsageBytes: 2 << 30, MemoryRequestBytes: 4 << 30, MemoryAllocatableBytes: 8 << 30,
+		},
+	}
+
+	output := captureStdout(t, func() {
+		printNodeTable(data, nodeTableOptions{Wide: true})
+	})
+
+	for _, col := range []string{"CPU REQ%", "CPU LIM%", "MEM REQ%", "MEM LIM%"} {
+		if !strings.Contains(output, col) {
+			t.Errorf("printNodeTable() wide output missing column %s. Output: %s", col, output)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	// Limits above 100% of allocatable show the node is overcommitted
+	want := []string{"75%", "150%", "50%", "0%"}
+	got := fields[len(fields)-4:]
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("printNodeTable() wide percentages = %v, want %v", got, want)
+			break
+		}
+	}
+}
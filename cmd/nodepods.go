@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NodePodsData holds a node together with the pods scheduled on it, for 'rltop node NAME --pods'
+type NodePodsData struct {
+	Node CombinedNodeData
+	Pods []CombinedPodData
+}
+
+// validateNodePods returns an error if --pods is set without a node name or label selector,
+// which would drill down into every pod of the cluster
+func validateNodePods(pods bool, nodeNames []string, labelSelector string) error {
+	if pods && len(nodeNames) == 0 && labelSelector == "" {
+		return errors.New("--pods requires a node NAME or a label selector (-l)")
+	}
+	return nil
+}
+
+// fetchNodePodsData fetches the nodes, then the pods scheduled on each of them.
+// Pods are listed per node with a server-side spec.nodeName field selector; pod metrics are
+// fetched once and matched to the listed pods.
+func fetchNodePodsData(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	opts NodeOptions,
+) ([]NodePodsData, error) {
+	nodes, err := fetchNodeData(ctx, clientset, source, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	metrics, err := source.PodMetrics(ctx, "", "", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]NodePodsData, 0, len(nodes))
+	for _, node := range nodes {
+		resources, err := pkg.GetPodResources(ctx, clientset,
+			"", "", pkg.NodePodsSelector(node.Name, opts.IncludeTerminated), nil)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, NodePodsData{
+			Node: node,
+			Pods: combineNodePods(node.Name, metrics, resources, opts.IncludeTerminated, opts.SortBy),
+		})
+	}
+	return data, nil
+}
+
+// combineNodePods combines the pods scheduled on a node with their metrics and sorts them.
+// Metrics of pods that are not on the node are dropped, and pods are checked against the node
+// and, unless includeTerminated, their phase here too, so the result doesn't depend on the
+// server honoring the field selector.
+func combineNodePods(
+	nodeName string,
+	metrics []pkg.PodMetrics,
+	resources []pkg.PodResources,
+	includeTerminated bool,
+	sortBy string,
+) []CombinedPodData {
+	onNode := make([]pkg.PodResources, 0, len(resources))
+	keys := make(map[string]bool, len(resources))
+	for _, r := range resources {
+		if r.NodeName == nodeName && (includeTerminated || !pkg.IsTerminalPhase(r.Phase)) {
+			onNode = append(onNode, r)
+			keys[r.Namespace+"/"+r.Name] = true
+		}
+	}
+
+	nodeMetrics := make([]pkg.PodMetrics, 0, len(onNode))
+	for _, m := range metrics {
+		if keys[m.Namespace+"/"+m.Name] {
+			nodeMetrics = append(nodeMetrics, m)
+		}
+	}
+
+	pods := combineMetricsAndResources(nodeMetrics, onNode)
+	sortCombinedData(pods, sortBy)
+	return pods
+}
+
+// printNodePodsData prints every node followed by the pods scheduled on it
+func printNodePodsData(data []NodePodsData, opts NodeOptions) error {
+	if isStructuredOutput(opts.Output) {
+		list := newNodePodsList(data)
+		list.Aggregation = newUsageAggregation(opts.Usage)
+		return printStructured(list, opts.Output)
+	}
+
+	if len(data) == 0 {
+		fmt.Fprintf(os.Stderr, "No nodes found\n")
+		return nil
+	}
+
+	for i, d := range data {
+		if i > 0 {
+			fmt.Println()
+		}
+		printNodeTable([]CombinedNodeData{d.Node}, nodeTableOptions{
+			NoHeaders: opts.NoHeaders,
+			Wide:      opts.Output == outputWide,
+			Usage:     opts.Usage,
+		})
+		fmt.Println()
+		if len(d.Pods) == 0 {
+			fmt.Fprintf(os.Stderr, "No pods found on node %s\n", d.Node.Name)
+			continue
+		}
+		printTable(d.Pods, podTableOptions{
+			NoHeaders:     opts.NoHeaders,
+			ShowNamespace: true,
+			Wide:          opts.Output == outputWide,
+			Usage:         opts.Usage,
+		})
+	}
+	return nil
+}
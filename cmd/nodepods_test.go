@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestValidateNodePods(t *testing.T) {
+	if err := validateNodePods(false, nil, ""); err != nil {
+		t.Errorf("validateNodePods() without --pods error = %v", err)
+	}
+	if err := validateNodePods(true, []string{"node1"}, ""); err != nil {
+		t.Errorf("validateNodePods() with a node name error = %v", err)
+	}
+	if err := validateNodePods(true, nil, "pool=a"); err != nil {
+		t.Errorf("validateNodePods() with a selector error = %v", err)
+	}
+	if err := validateNodePods(true, nil, ""); err == nil {
+		t.Errorf("validateNodePods() without node name or selector expected error")
+	}
+}
+
+func TestCombineNodePods(t *testing.T) {
+	metrics := []pkg.PodMetrics{
+		{Name: "small", Namespace: "default", CPUMilli: 10},
+		{Name: "big", Namespace: "kube-system", CPUMilli: 500},
+		{Name: "elsewhere", Namespace: "default", CPUMilli: 900},
+	}
+	resources := []pkg.PodResources{
+		{Name: "small", Namespace: "default", NodeName: "node1", CPURequestMilli: 100},
+		{Name: "big", Namespace: "kube-system", NodeName: "node1"},
+		{Name: "pending", Namespace: "default", NodeName: "node1", CPURequestMilli: 200},
+		// Returned by a server ignoring the field selector
+		{Name: "elsewhere", Namespace: "default", NodeName: "node2"},
+		{Name: "done", Namespace: "default", NodeName: "node1", Phase: corev1.PodSucceeded, CPURequestMilli: 300},
+	}
+
+	pods := combineNodePods("node1", metrics, resources, false, "cpu")
+
+	want := []string{"kube-system/big", "default/small", "default/pending"}
+	if len(pods) != len(want) {
+		t.Fatalf("combineNodePods() returned %d pods, want %d: %+v", len(pods), len(want), pods)
+	}
+	for i, w := range want {
+		if got := pods[i].Namespace + "/" + pods[i].Name; got != w {
+			t.Errorf("combineNodePods()[%d] = %s, want %s", i, got, w)
+		}
+	}
+	if pods[2].HasMetrics {
+		t.Errorf("combineNodePods() pod without metrics should have HasMetrics = false")
+	}
+
+	if pods := combineNodePods("node1", metrics, resources, true, "cpu"); len(pods) != 4 {
+		t.Errorf("combineNodePods() with terminated pods returned %d pods, want 4: %+v", len(pods), pods)
+	}
+}
+
+func TestNewNodePodsList(t *testing.T) {
+	data := []NodePodsData{
+		{
+			Node: CombinedNodeData{Name: "node1", HasMetrics: true, CPUUsageMilli: 500, CPUAllocatableMilli: 1000},
+			Pods: []CombinedPodData{{Namespace: "default", Name: "pod1", HasMetrics: true, CPUUsageMilli: 100}},
+		},
+	}
+
+	out, err := json.Marshal(newNodePodsList(data))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var decoded struct {
+		Kind  string `json:"kind"`
+		Items []struct {
+			Name string `json:"name"`
+			CPU  struct {
+				Usage struct {
+					Millicores int64 `json:"millicores"`
+				} `json:"usage"`
+			} `json:"cpu"`
+			Pods []struct {
+				Name string `json:"name"`
+			} `json:"pods"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded.Kind != "NodePodsList" || len(decoded.Items) != 1 {
+		t.Fatalf("newNodePodsList() = %s", out)
+	}
+	item := decoded.Items[0]
+	if item.Name != "node1" || item.CPU.Usage.Millicores != 500 {
+		t.Errorf("newNodePodsList() node fields not inlined: %s", out)
+	}
+	if len(item.Pods) != 1 || item.Pods[0].Name != "pod1" {
+		t.Errorf("newNodePodsList() pods = %+v", item.Pods)
+	}
+}
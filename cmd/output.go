@@ -0,0 +1,779 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+	"sigs.k8s.io/yaml"
+)
+
+// outputAPIVersion is the version of the list objects printed by -o json and -o yaml.
+// Bump it whenever a field is renamed or removed so scripts can detect the change.
+const outputAPIVersion = "rltop.veditoid.io/v1alpha1"
+
+// Supported values for the --output flag
+const (
+	outputJSON = "json"
+	outputYAML = "yaml"
+	outputWide = "wide"
+)
+
+// Output formats accepted by each command; the empty default is always accepted
+var (
+	podOutputFormats       = []string{outputJSON, outputYAML, outputWide}
+	nodeOutputFormats      = []string{outputJSON, outputYAML, outputWide}
+	workloadOutputFormats  = []string{outputJSON, outputYAML, outputWide}
+	namespaceOutputFormats = []string{outputJSON, outputYAML}
+	quotaOutputFormats     = []string{outputJSON, outputYAML}
+	recommendOutputFormats = []string{outputJSON, outputYAML, outputPatch, outputKubectl}
+	diffOutputFormats      = []string{outputJSON, outputYAML}
+)
+
+// validateOutputFormat returns an error if the --output value is not one of the supported formats
+func validateOutputFormat(format string, supported []string) error {
+	if format == "" {
+		return nil
+	}
+	for _, f := range supported {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format %q, must be one of: %s", format, strings.Join(supported, ", "))
+}
+
+// isStructuredOutput reports whether the output format is a machine-readable format
+func isStructuredOutput(format string) bool {
+	return format == outputJSON || format == outputYAML
+}
+
+// CPUValue is a CPU quantity in millicores together with its formatted representation
+type CPUValue struct {
+	Millicores int64  `json:"millicores"`
+	Formatted  string `json:"formatted"`
+}
+
+// MemoryValue is a memory quantity in bytes together with its formatted representation
+type MemoryValue struct {
+	Bytes     int64  `json:"bytes"`
+	Formatted string `json:"formatted"`
+}
+
+// PercentValue is a percentage together with its formatted representation
+type PercentValue struct {
+	Percent   float64 `json:"percent"`
+	Formatted string  `json:"formatted"`
+}
+
+// PodCPU holds CPU usage, request and limit, and usage as a percentage of each.
+// Unknown or unset values are omitted.
+type PodCPU struct {
+	Usage              *CPUValue     `json:"usage,omitempty"`
+	Request            *CPUValue     `json:"request,omitempty"`
+	Limit              *CPUValue     `json:"limit,omitempty"`
+	RequestUtilization *PercentValue `json:"requestUtilization,omitempty"`
+	LimitUtilization   *PercentValue `json:"limitUtilization,omitempty"`
+}
+
+// PodMemory holds memory usage, request and limit, and usage as a percentage of each.
+// Unknown or unset values are omitted.
+type PodMemory struct {
+	Usage              *MemoryValue  `json:"usage,omitempty"`
+	Request            *MemoryValue  `json:"request,omitempty"`
+	Limit              *MemoryValue  `json:"limit,omitempty"`
+	RequestUtilization *PercentValue `json:"requestUtilization,omitempty"`
+	LimitUtilization   *PercentValue `json:"limitUtilization,omitempty"`
+}
+
+// PodUsage is a single pod (or container) entry of a PodUsageList
+type PodUsage struct {
+	Namespace          string    `json:"namespace"`
+	Name               string    `json:"name"`
+	Container          string    `json:"container,omitempty"`
+	Resize             string    `json:"resize,omitempty"`
+	LimitRangeDefaults []string  `json:"limitRangeDefaults,omitempty"`
+	CPU                PodCPU    `json:"cpu"`
+	Memory             PodMemory `json:"memory"`
+	VPA                *VPAUsage `json:"vpa,omitempty"`
+}
+
+// VPAUsage holds the recommendation of a VerticalPodAutoscaler for a container. Unset values are omitted.
+type VPAUsage struct {
+	CPU    VPACPU    `json:"cpu"`
+	Memory VPAMemory `json:"memory"`
+}
+
+// VPACPU holds the CPU target, lower bound and upper bound recommended by a VerticalPodAutoscaler
+type VPACPU struct {
+	Target     *CPUValue `json:"target,omitempty"`
+	LowerBound *CPUValue `json:"lowerBound,omitempty"`
+	UpperBound *CPUValue `json:"upperBound,omitempty"`
+}
+
+// VPAMemory holds the memory target, lower bound and upper bound recommended by a VerticalPodAutoscaler
+type VPAMemory struct {
+	Target     *MemoryValue `json:"target,omitempty"`
+	LowerBound *MemoryValue `json:"lowerBound,omitempty"`
+	UpperBound *MemoryValue `json:"upperBound,omitempty"`
+}
+
+// UsageAggregation describes usage aggregated over a window (--window, --stat) instead of the current usage
+type UsageAggregation struct {
+	Stat   string `json:"stat"`
+	Window string `json:"window"`
+}
+
+// PodUsageList is the versioned list object printed by 'rltop pod -o json|yaml'
+type PodUsageList struct {
+	APIVersion  string            `json:"apiVersion"`
+	Kind        string            `json:"kind"`
+	Aggregation *UsageAggregation `json:"aggregation,omitempty"`
+	Items       []PodUsage        `json:"items"`
+}
+
+// WorkloadCPU holds the CPU totals of a workload and the average usage per pod
+type WorkloadCPU struct {
+	PodCPU
+	Average *CPUValue `json:"average,omitempty"`
+}
+
+// WorkloadMemory holds the memory totals of a workload and the average usage per pod
+type WorkloadMemory struct {
+	PodMemory
+	Average *MemoryValue `json:"average,omitempty"`
+}
+
+// WorkloadUsage is a single workload entry of a WorkloadUsageList.
+// Usage, requests and limits are totals over the pods of the workload.
+type WorkloadUsage struct {
+	Namespace string         `json:"namespace"`
+	Kind      string         `json:"kind"`
+	Name      string         `json:"name"`
+	Pods      int            `json:"pods"`
+	CPU       WorkloadCPU    `json:"cpu"`
+	Memory    WorkloadMemory `json:"memory"`
+}
+
+// WorkloadUsageList is the versioned list object printed by 'rltop workload -o json|yaml'
+type WorkloadUsageList struct {
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	Items      []WorkloadUsage `json:"items"`
+}
+
+// CPUQuota holds the used and hard values of a CPU ResourceQuota
+type CPUQuota struct {
+	Used CPUValue `json:"used"`
+	Hard CPUValue `json:"hard"`
+}
+
+// MemoryQuota holds the used and hard values of a memory ResourceQuota
+type MemoryQuota struct {
+	Used MemoryValue `json:"used"`
+	Hard MemoryValue `json:"hard"`
+}
+
+// NamespaceQuotaUsage holds the compute ResourceQuota of a namespace; resources without a quota are omitted
+type NamespaceQuotaUsage struct {
+	CPURequest    *CPUQuota    `json:"cpuRequest,omitempty"`
+	CPULimit      *CPUQuota    `json:"cpuLimit,omitempty"`
+	MemoryRequest *MemoryQuota `json:"memoryRequest,omitempty"`
+	MemoryLimit   *MemoryQuota `json:"memoryLimit,omitempty"`
+}
+
+// NamespaceUsage is a single namespace entry of a NamespaceUsageList.
+// Usage, requests and limits are totals over the pods of the namespace; the request
+// utilization is the usage-to-request efficiency of the pods with metrics.
+type NamespaceUsage struct {
+	Name   string               `json:"name"`
+	Pods   int                  `json:"pods"`
+	CPU    PodCPU               `json:"cpu"`
+	Memory PodMemory            `json:"memory"`
+	Quota  *NamespaceQuotaUsage `json:"quota,omitempty"`
+}
+
+// NamespaceUsageList is the versioned list object printed by 'rltop namespace -o json|yaml'
+type NamespaceUsageList struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Items      []NamespaceUsage `json:"items"`
+}
+
+// QuotaValue is a quota amount, in millicores for CPU resources and bytes for memory resources,
+// together with its formatted representation
+type QuotaValue struct {
+	Value     int64  `json:"value"`
+	Formatted string `json:"formatted"`
+}
+
+// ResourceQuotaUsage is a single entry of a ResourceQuotaUsageList
+type ResourceQuotaUsage struct {
+	Namespace   string        `json:"namespace"`
+	Resource    string        `json:"resource"`
+	Used        QuotaValue    `json:"used"`
+	Hard        QuotaValue    `json:"hard"`
+	Left        QuotaValue    `json:"left"`
+	PercentLeft *PercentValue `json:"percentLeft,omitempty"`
+}
+
+// ResourceQuotaUsageList is the versioned list object printed by 'rltop quota -o json|yaml'
+type ResourceQuotaUsageList struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Items      []ResourceQuotaUsage `json:"items"`
+}
+
+// ContainerResourceValues holds the CPU and memory requests and limits of a container. Unset values are omitted.
+type ContainerResourceValues struct {
+	CPURequest    *CPUValue    `json:"cpuRequest,omitempty"`
+	CPULimit      *CPUValue    `json:"cpuLimit,omitempty"`
+	MemoryRequest *MemoryValue `json:"memoryRequest,omitempty"`
+	MemoryLimit   *MemoryValue `json:"memoryLimit,omitempty"`
+}
+
+// ContainerRecommendation is a single workload container entry of a RecommendationList
+type ContainerRecommendation struct {
+	Namespace string                  `json:"namespace"`
+	Kind      string                  `json:"kind"`
+	Workload  string                  `json:"workload"`
+	Container string                  `json:"container"`
+	Pods      int                     `json:"pods"`
+	Samples   int                     `json:"samples"`
+	Current   ContainerResourceValues `json:"current"`
+	Proposed  ContainerResourceValues `json:"proposed"`
+}
+
+// WorkloadSavings is the projected decrease of the requests of a workload, negative when requests go up
+type WorkloadSavings struct {
+	Namespace string      `json:"namespace"`
+	Kind      string      `json:"kind"`
+	Name      string      `json:"name"`
+	Pods      int         `json:"pods"`
+	CPU       CPUValue    `json:"cpu"`
+	Memory    MemoryValue `json:"memory"`
+}
+
+// RecommendationList is the versioned list object printed by 'rltop recommend -o json|yaml'
+type RecommendationList struct {
+	APIVersion string                    `json:"apiVersion"`
+	Kind       string                    `json:"kind"`
+	Items      []ContainerRecommendation `json:"items"`
+	Savings    []WorkloadSavings         `json:"savings"`
+}
+
+// CPUChange holds a CPU value before and after, the difference, and the difference as a percentage
+// of the value before. Before and After are omitted when unset or missing.
+type CPUChange struct {
+	Before  *CPUValue     `json:"before,omitempty"`
+	After   *CPUValue     `json:"after,omitempty"`
+	Delta   CPUValue      `json:"delta"`
+	Percent *PercentValue `json:"percent,omitempty"`
+}
+
+// MemoryChange holds a memory value before and after, the difference, and the difference as a percentage
+// of the value before. Before and After are omitted when unset or missing.
+type MemoryChange struct {
+	Before  *MemoryValue  `json:"before,omitempty"`
+	After   *MemoryValue  `json:"after,omitempty"`
+	Delta   MemoryValue   `json:"delta"`
+	Percent *PercentValue `json:"percent,omitempty"`
+}
+
+// DiffCPU holds the changes of CPU usage, request and limit. Unknown or unset changes are omitted.
+type DiffCPU struct {
+	Usage   *CPUChange `json:"usage,omitempty"`
+	Request *CPUChange `json:"request,omitempty"`
+	Limit   *CPUChange `json:"limit,omitempty"`
+}
+
+// DiffMemory holds the changes of memory usage, request and limit. Unknown or unset changes are omitted.
+type DiffMemory struct {
+	Usage   *MemoryChange `json:"usage,omitempty"`
+	Request *MemoryChange `json:"request,omitempty"`
+	Limit   *MemoryChange `json:"limit,omitempty"`
+}
+
+// DiffItem is a single pod, workload or node entry of a DiffList
+type DiffItem struct {
+	Namespace string     `json:"namespace,omitempty"`
+	Kind      string     `json:"kind,omitempty"`
+	Name      string     `json:"name"`
+	Change    string     `json:"change"`
+	CPU       DiffCPU    `json:"cpu"`
+	Memory    DiffMemory `json:"memory"`
+}
+
+// DiffList is the versioned list object printed by 'rltop diff -o json|yaml'
+type DiffList struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	By         string     `json:"by"`
+	Before     string     `json:"before"`
+	After      string     `json:"after"`
+	Items      []DiffItem `json:"items"`
+}
+
+// NodeCPU holds node CPU usage, aggregated requests and limits, and allocatable CPU.
+// The percentages compare usage, requests and limits with allocatable (or capacity).
+type NodeCPU struct {
+	Usage          *CPUValue     `json:"usage,omitempty"`
+	Percent        *PercentValue `json:"percent,omitempty"`
+	Request        *CPUValue     `json:"request,omitempty"`
+	RequestPercent *PercentValue `json:"requestPercent,omitempty"`
+	Limit          *CPUValue     `json:"limit,omitempty"`
+	LimitPercent   *PercentValue `json:"limitPercent,omitempty"`
+	Allocatable    *CPUValue     `json:"allocatable,omitempty"`
+}
+
+// NodeMemory holds node memory usage, aggregated requests and limits, and allocatable memory.
+// The percentages compare usage, requests and limits with allocatable (or capacity).
+type NodeMemory struct {
+	Usage          *MemoryValue  `json:"usage,omitempty"`
+	Percent        *PercentValue `json:"percent,omitempty"`
+	Request        *MemoryValue  `json:"request,omitempty"`
+	RequestPercent *PercentValue `json:"requestPercent,omitempty"`
+	Limit          *MemoryValue  `json:"limit,omitempty"`
+	LimitPercent   *PercentValue `json:"limitPercent,omitempty"`
+	Allocatable    *MemoryValue  `json:"allocatable,omitempty"`
+}
+
+// NodeUsage is a single node entry of a NodeUsageList
+type NodeUsage struct {
+	Name   string     `json:"name"`
+	Status string     `json:"status,omitempty"`
+	CPU    NodeCPU    `json:"cpu"`
+	Memory NodeMemory `json:"memory"`
+}
+
+// NodeUsageList is the versioned list object printed by 'rltop node -o json|yaml'
+type NodeUsageList struct {
+	APIVersion  string            `json:"apiVersion"`
+	Kind        string            `json:"kind"`
+	Aggregation *UsageAggregation `json:"aggregation,omitempty"`
+	Items       []NodeUsage       `json:"items"`
+}
+
+// NodePods is a single node entry of a NodePodsList, with the pods scheduled on the node
+type NodePods struct {
+	NodeUsage
+	Pods []PodUsage `json:"pods"`
+}
+
+// NodePodsList is the versioned list object printed by 'rltop node NAME --pods -o json|yaml'
+type NodePodsList struct {
+	APIVersion  string            `json:"apiVersion"`
+	Kind        string            `json:"kind"`
+	Aggregation *UsageAggregation `json:"aggregation,omitempty"`
+	Items       []NodePods        `json:"items"`
+}
+
+// newUsageAggregation describes the usage window, nil for the current usage
+func newUsageAggregation(usage pkg.UsageWindow) *UsageAggregation {
+	if usage.IsZero() {
+		return nil
+	}
+	return &UsageAggregation{Stat: string(usage.Stat), Window: usage.Window.String()}
+}
+
+// newPodUsageList builds the structured output object from the combined pod data
+func newPodUsageList(data []CombinedPodData) PodUsageList {
+	list := PodUsageList{
+		APIVersion: outputAPIVersion,
+		Kind:       "PodUsageList",
+		Items:      make([]PodUsage, 0, len(data)),
+	}
+
+	for _, d := range data {
+		columns := d.columns()
+		item := PodUsage{
+			Namespace:          d.Namespace,
+			Name:               d.Name,
+			Container:          d.Container,
+			Resize:             d.Resize,
+			LimitRangeDefaults: d.LimitRangeDefaults,
+			CPU: PodCPU{
+				Request: cpuValue(d.CPURequestMilli, columns.CPURequest),
+				Limit:   cpuValue(d.CPULimitMilli, columns.CPULimit),
+			},
+			Memory: PodMemory{
+				Request: memoryValue(d.MemoryRequestBytes, columns.MemoryRequest),
+				Limit:   memoryValue(d.MemoryLimitBytes, columns.MemoryLimit),
+			},
+		}
+		if d.HasMetrics {
+			cpuRequestPercent, cpuLimitPercent, memoryRequestPercent, memoryLimitPercent := d.utilization()
+			item.CPU.Usage = &CPUValue{Millicores: d.CPUUsageMilli, Formatted: columns.CPUUsage}
+			item.CPU.RequestUtilization = percentValue(d.CPUUsageMilli, d.CPURequestMilli, cpuRequestPercent)
+			item.CPU.LimitUtilization = percentValue(d.CPUUsageMilli, d.CPULimitMilli, cpuLimitPercent)
+			item.Memory.Usage = &MemoryValue{Bytes: d.MemoryUsageBytes, Formatted: columns.MemoryUsage}
+			item.Memory.RequestUtilization = percentValue(d.MemoryUsageBytes, d.MemoryRequestBytes, memoryRequestPercent)
+			item.Memory.LimitUtilization = percentValue(d.MemoryUsageBytes, d.MemoryLimitBytes, memoryLimitPercent)
+		}
+		if d.VPA != nil {
+			cpuTarget, cpuLower, cpuUpper, memoryTarget, memoryLower, memoryUpper := d.vpaColumns()
+			item.VPA = &VPAUsage{
+				CPU: VPACPU{
+					Target:     cpuValue(d.VPA.CPUTargetMilli, cpuTarget),
+					LowerBound: cpuValue(d.VPA.CPULowerBoundMilli, cpuLower),
+					UpperBound: cpuValue(d.VPA.CPUUpperBoundMilli, cpuUpper),
+				},
+				Memory: VPAMemory{
+					Target:     memoryValue(d.VPA.MemoryTargetBytes, memoryTarget),
+					LowerBound: memoryValue(d.VPA.MemoryLowerBoundBytes, memoryLower),
+					UpperBound: memoryValue(d.VPA.MemoryUpperBoundBytes, memoryUpper),
+				},
+			}
+		}
+		list.Items = append(list.Items, item)
+	}
+
+	return list
+}
+
+// newNodeUsageList builds the structured output object from the combined node data
+func newNodeUsageList(data []CombinedNodeData) NodeUsageList {
+	list := NodeUsageList{
+		APIVersion: outputAPIVersion,
+		Kind:       "NodeUsageList",
+		Items:      make([]NodeUsage, 0, len(data)),
+	}
+
+	for _, d := range data {
+		columns := d.columns()
+		cpuRequestPercent, cpuLimitPercent, memoryRequestPercent, memoryLimitPercent := d.commitments()
+		item := NodeUsage{
+			Name:   d.Name,
+			Status: d.Status,
+			CPU: NodeCPU{
+				Request:        cpuValue(d.CPURequestMilli, columns.CPURequest),
+				RequestPercent: percentValue(d.CPURequestMilli, d.CPUAllocatableMilli, cpuRequestPercent),
+				Limit:          cpuValue(d.CPULimitMilli, columns.CPULimit),
+				LimitPercent:   percentValue(d.CPULimitMilli, d.CPUAllocatableMilli, cpuLimitPercent),
+				Allocatable:    cpuValue(d.CPUAllocatableMilli, formatCPUMilli(d.CPUAllocatableMilli)),
+			},
+			Memory: NodeMemory{
+				Request:        memoryValue(d.MemoryRequestBytes, columns.MemoryRequest),
+				RequestPercent: percentValue(d.MemoryRequestBytes, d.MemoryAllocatableBytes, memoryRequestPercent),
+				Limit:          memoryValue(d.MemoryLimitBytes, columns.MemoryLimit),
+				LimitPercent:   percentValue(d.MemoryLimitBytes, d.MemoryAllocatableBytes, memoryLimitPercent),
+				Allocatable:    memoryValue(d.MemoryAllocatableBytes, formatMemoryBytes(d.MemoryAllocatableBytes)),
+			},
+		}
+		if d.HasMetrics {
+			cpuPercent, memoryPercent := d.percentages()
+			item.CPU.Usage = &CPUValue{Millicores: d.CPUUsageMilli, Formatted: columns.CPUUsage}
+			item.CPU.Percent = percentValue(d.CPUUsageMilli, d.CPUAllocatableMilli, cpuPercent)
+			item.Memory.Usage = &MemoryValue{Bytes: d.MemoryUsageBytes, Formatted: columns.MemoryUsage}
+			item.Memory.Percent = percentValue(d.MemoryUsageBytes, d.MemoryAllocatableBytes, memoryPercent)
+		}
+		list.Items = append(list.Items, item)
+	}
+
+	return list
+}
+
+// newNodePodsList builds the structured output object from the nodes and the pods scheduled on them
+func newNodePodsList(data []NodePodsData) NodePodsList {
+	list := NodePodsList{
+		APIVersion: outputAPIVersion,
+		Kind:       "NodePodsList",
+		Items:      make([]NodePods, 0, len(data)),
+	}
+
+	for _, d := range data {
+		list.Items = append(list.Items, NodePods{
+			NodeUsage: newNodeUsageList([]CombinedNodeData{d.Node}).Items[0],
+			Pods:      newPodUsageList(d.Pods).Items,
+		})
+	}
+
+	return list
+}
+
+// newGroupUsage returns the totals of a pod group, with usage compared against the requests and
+// limits of the pods with metrics
+func newGroupUsage(g podGroup) (PodCPU, PodMemory) {
+	totals := newPodUsageList([]CombinedPodData{g.CombinedPodData}).Items[0]
+	measured := newPodUsageList([]CombinedPodData{g.measured()}).Items[0]
+	totals.CPU.RequestUtilization = measured.CPU.RequestUtilization
+	totals.CPU.LimitUtilization = measured.CPU.LimitUtilization
+	totals.Memory.RequestUtilization = measured.Memory.RequestUtilization
+	totals.Memory.LimitUtilization = measured.Memory.LimitUtilization
+	return totals.CPU, totals.Memory
+}
+
+// newWorkloadUsageList builds the structured output object from the combined workload data
+func newWorkloadUsageList(data []CombinedWorkloadData) WorkloadUsageList {
+	list := WorkloadUsageList{
+		APIVersion: outputAPIVersion,
+		Kind:       "WorkloadUsageList",
+		Items:      make([]WorkloadUsage, 0, len(data)),
+	}
+
+	for _, d := range data {
+		cpu, memory := newGroupUsage(d.podGroup)
+		item := WorkloadUsage{
+			Namespace: d.Namespace,
+			Kind:      d.Kind,
+			Name:      d.Name,
+			Pods:      d.Pods,
+			CPU:       WorkloadCPU{PodCPU: cpu},
+			Memory:    WorkloadMemory{PodMemory: memory},
+		}
+		if cpuMilli, memoryBytes, ok := d.averages(); ok {
+			cpuAverage, memoryAverage := d.formattedAverages()
+			item.CPU.Average = &CPUValue{Millicores: cpuMilli, Formatted: cpuAverage}
+			item.Memory.Average = &MemoryValue{Bytes: memoryBytes, Formatted: memoryAverage}
+		}
+		list.Items = append(list.Items, item)
+	}
+
+	return list
+}
+
+// newNamespaceUsageList builds the structured output object from the combined namespace data
+func newNamespaceUsageList(data []CombinedNamespaceData) NamespaceUsageList {
+	list := NamespaceUsageList{
+		APIVersion: outputAPIVersion,
+		Kind:       "NamespaceUsageList",
+		Items:      make([]NamespaceUsage, 0, len(data)),
+	}
+
+	for _, d := range data {
+		cpu, memory := newGroupUsage(d.podGroup)
+		item := NamespaceUsage{
+			Name:   d.Name,
+			Pods:   d.Pods,
+			CPU:    cpu,
+			Memory: memory,
+		}
+		if d.Quota != nil {
+			item.Quota = &NamespaceQuotaUsage{
+				CPURequest:    cpuQuota(d.Quota.CPURequest),
+				CPULimit:      cpuQuota(d.Quota.CPULimit),
+				MemoryRequest: memoryQuota(d.Quota.MemoryRequest),
+				MemoryLimit:   memoryQuota(d.Quota.MemoryLimit),
+			}
+		}
+		list.Items = append(list.Items, item)
+	}
+
+	return list
+}
+
+// newResourceQuotaUsageList builds the structured output object from the combined quota data
+func newResourceQuotaUsageList(data []CombinedQuotaData) ResourceQuotaUsageList {
+	list := ResourceQuotaUsageList{
+		APIVersion: outputAPIVersion,
+		Kind:       "ResourceQuotaUsageList",
+		Items:      make([]ResourceQuotaUsage, 0, len(data)),
+	}
+
+	for _, d := range data {
+		used, hard, left, percentLeft := d.quotaColumns()
+		list.Items = append(list.Items, ResourceQuotaUsage{
+			Namespace:   d.Namespace,
+			Resource:    d.Resource,
+			Used:        QuotaValue{Value: d.Used, Formatted: used},
+			Hard:        QuotaValue{Value: d.Hard, Formatted: hard},
+			Left:        QuotaValue{Value: d.left(), Formatted: left},
+			PercentLeft: percentValue(d.left(), d.Hard, percentLeft),
+		})
+	}
+
+	return list
+}
+
+// newRecommendationList builds the structured output object from the recommendations
+func newRecommendationList(data []CombinedRecommendationData) RecommendationList {
+	list := RecommendationList{
+		APIVersion: outputAPIVersion,
+		Kind:       "RecommendationList",
+		Items:      make([]ContainerRecommendation, 0, len(data)),
+		Savings:    make([]WorkloadSavings, 0),
+	}
+
+	for _, d := range data {
+		list.Items = append(list.Items, ContainerRecommendation{
+			Namespace: d.Namespace,
+			Kind:      d.Kind,
+			Workload:  d.Workload,
+			Container: d.Container,
+			Pods:      d.Pods,
+			Samples:   d.Samples,
+			Current:   containerResourceValues(d.Current),
+			Proposed:  containerResourceValues(d.Proposed),
+		})
+	}
+
+	for _, s := range sumSavings(data) {
+		list.Savings = append(list.Savings, WorkloadSavings{
+			Namespace: s.Namespace,
+			Kind:      s.Kind,
+			Name:      s.Name,
+			Pods:      s.Pods,
+			CPU:       CPUValue{Millicores: s.CPUMilli, Formatted: formatCPUSavings(s.CPUMilli)},
+			Memory:    MemoryValue{Bytes: s.MemoryBytes, Formatted: formatMemorySavings(s.MemoryBytes)},
+		})
+	}
+
+	return list
+}
+
+// newDiffList builds the structured output object from the diff rows and the times of both snapshots
+func newDiffList(data []CombinedDiffData, by string, before, after time.Time) DiffList {
+	list := DiffList{
+		APIVersion: outputAPIVersion,
+		Kind:       "DiffList",
+		By:         by,
+		Before:     before.Format(time.RFC3339),
+		After:      after.Format(time.RFC3339),
+		Items:      make([]DiffItem, 0, len(data)),
+	}
+
+	for _, d := range data {
+		list.Items = append(list.Items, DiffItem{
+			Namespace: d.Namespace,
+			Kind:      d.Kind,
+			Name:      d.Name,
+			Change:    d.Change,
+			CPU: DiffCPU{
+				Usage:   cpuChange(d, "cpu"),
+				Request: cpuChange(d, "cpu-request"),
+				Limit:   cpuChange(d, "cpu-limit"),
+			},
+			Memory: DiffMemory{
+				Usage:   memoryChange(d, "memory"),
+				Request: memoryChange(d, "memory-request"),
+				Limit:   memoryChange(d, "memory-limit"),
+			},
+		})
+	}
+
+	return list
+}
+
+// cpuChange returns the change of a CPU field of a diff row, nil when unknown or unset on both sides
+func cpuChange(d CombinedDiffData, field string) *CPUChange {
+	f := diffFields[field]
+	delta, ok := diffDelta(d, f.value, f.usage)
+	before, after := f.value(d.Before), f.value(d.After)
+	if !ok || (!f.usage && before == 0 && after == 0) {
+		return nil
+	}
+	change := &CPUChange{
+		Before: cpuValue(before, pkg.FormatCPU(before)),
+		After:  cpuValue(after, pkg.FormatCPU(after)),
+		Delta:  CPUValue{Millicores: delta, Formatted: formatDelta(delta, pkg.FormatCPU)},
+	}
+	if percent, ok := diffPercent(d, f.value, f.usage); ok {
+		change.Percent = &PercentValue{Percent: percent, Formatted: fmt.Sprintf("%+.0f%%", percent)}
+	}
+	return change
+}
+
+// memoryChange returns the change of a memory field of a diff row, nil when unknown or unset on both sides
+func memoryChange(d CombinedDiffData, field string) *MemoryChange {
+	f := diffFields[field]
+	delta, ok := diffDelta(d, f.value, f.usage)
+	before, after := f.value(d.Before), f.value(d.After)
+	if !ok || (!f.usage && before == 0 && after == 0) {
+		return nil
+	}
+	change := &MemoryChange{
+		Before: memoryValue(before, pkg.FormatMemory(before)),
+		After:  memoryValue(after, pkg.FormatMemory(after)),
+		Delta:  MemoryValue{Bytes: delta, Formatted: formatDelta(delta, pkg.FormatMemory)},
+	}
+	if percent, ok := diffPercent(d, f.value, f.usage); ok {
+		change.Percent = &PercentValue{Percent: percent, Formatted: fmt.Sprintf("%+.0f%%", percent)}
+	}
+	return change
+}
+
+// containerResourceValues formats requests and limits, memory in the unit of the request
+func containerResourceValues(v resourceValues) ContainerResourceValues {
+	unit := pkg.MemoryUnit(v.MemoryRequestBytes)
+	return ContainerResourceValues{
+		CPURequest:    cpuValue(v.CPURequestMilli, formatCPUMilli(v.CPURequestMilli)),
+		CPULimit:      cpuValue(v.CPULimitMilli, formatCPUMilli(v.CPULimitMilli)),
+		MemoryRequest: memoryValue(v.MemoryRequestBytes, formatMemoryBytesIn(v.MemoryRequestBytes, unit)),
+		MemoryLimit:   memoryValue(v.MemoryLimitBytes, formatMemoryBytesIn(v.MemoryLimitBytes, unit)),
+	}
+}
+
+// cpuQuota returns nil for CPU resources without a quota
+func cpuQuota(q pkg.QuotaUsage) *CPUQuota {
+	if !q.Limited {
+		return nil
+	}
+	return &CPUQuota{
+		Used: CPUValue{Millicores: q.Used, Formatted: formatCPUMilli(q.Used)},
+		Hard: CPUValue{Millicores: q.Hard, Formatted: formatCPUMilli(q.Hard)},
+	}
+}
+
+// memoryQuota returns nil for memory resources without a quota; both values use the unit of the hard value
+func memoryQuota(q pkg.QuotaUsage) *MemoryQuota {
+	if !q.Limited {
+		return nil
+	}
+	unit := pkg.MemoryUnit(q.Hard)
+	return &MemoryQuota{
+		Used: MemoryValue{Bytes: q.Used, Formatted: formatMemoryBytesIn(q.Used, unit)},
+		Hard: MemoryValue{Bytes: q.Hard, Formatted: formatMemoryBytesIn(q.Hard, unit)},
+	}
+}
+
+// cpuValue returns nil for unset (zero) CPU quantities
+func cpuValue(millicores int64, formatted string) *CPUValue {
+	if millicores == 0 {
+		return nil
+	}
+	return &CPUValue{Millicores: millicores, Formatted: formatted}
+}
+
+// memoryValue returns nil for unset (zero) memory quantities
+func memoryValue(bytes int64, formatted string) *MemoryValue {
+	if bytes == 0 {
+		return nil
+	}
+	return &MemoryValue{Bytes: bytes, Formatted: formatted}
+}
+
+// percentValue returns nil when the total is unknown or unset
+func percentValue(used, total int64, formatted string) *PercentValue {
+	percent, ok := pkg.UtilizationPercent(used, total)
+	if !ok {
+		return nil
+	}
+	return &PercentValue{Percent: percent, Formatted: formatted}
+}
+
+// printStructured writes obj to stdout as JSON or YAML
+func printStructured(obj interface{}, format string) error {
+	var out []byte
+	var err error
+
+	switch format {
+	case outputJSON:
+		out, err = json.MarshalIndent(obj, "", "    ")
+		if err == nil {
+			out = append(out, '\n')
+		}
+	case outputYAML:
+		out, err = yaml.Marshal(obj)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	_, err = os.Stdout.Write(out)
+	return err
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, format := range []string{"", "json", "yaml", "wide"} {
+		if err := validateOutputFormat(format, podOutputFormats); err != nil {
+			t.Errorf("validateOutputFormat(%q) error = %v", format, err)
+		}
+	}
+	if err := validateOutputFormat("xml", podOutputFormats); err == nil {
+		t.Errorf("validateOutputFormat(%q) expected error", "xml")
+	}
+	if err := validateOutputFormat("wide", nodeOutputFormats); err != nil {
+		t.Errorf("validateOutputFormat(%q) for nodes error = %v", "wide", err)
+	}
+}
+
+func TestNewPodUsageList(t *testing.T) {
+	data := []CombinedPodData{
+		{
+			Name: "pod1", HasMetrics: true, CPUUsageMilli: 100, CPURequestMilli: 200,
+			MemoryUsageBytes: 128 << 20, MemoryRequestBytes: 256 << 20,
+		},
+		{Name: "pod2"},
+	}
+
+	list := newPodUsageList(data)
+	if list.APIVersion != outputAPIVersion || list.Kind != "PodUsageList" {
+		t.Errorf("newPodUsageList() apiVersion/kind = %s/%s", list.APIVersion, list.Kind)
+	}
+	if len(list.Items) != 2 {
+		t.Fatalf("newPodUsageList() returned %d items, want 2", len(list.Items))
+	}
+
+	pod1 := list.Items[0]
+	if pod1.CPU.Usage == nil || pod1.CPU.Usage.Millicores != 100 || pod1.CPU.Usage.Formatted != "100m" {
+		t.Errorf("newPodUsageList() pod1 CPU usage = %+v", pod1.CPU.Usage)
+	}
+	if pod1.CPU.Request == nil || pod1.CPU.Request.Millicores != 200 {
+		t.Errorf("newPodUsageList() pod1 CPU request = %+v", pod1.CPU.Request)
+	}
+	if pod1.CPU.Limit != nil || pod1.Memory.Limit != nil {
+		t.Errorf("newPodUsageList() pod1 unset limits should be omitted")
+	}
+	if pod1.Memory.Request == nil || pod1.Memory.Request.Bytes != 256<<20 || pod1.Memory.Request.Formatted != "256.00Mi" {
+		t.Errorf("newPodUsageList() pod1 memory request = %+v", pod1.Memory.Request)
+	}
+	if u := pod1.CPU.RequestUtilization; u == nil || u.Percent != 50 || u.Formatted != "50%" {
+		t.Errorf("newPodUsageList() pod1 CPU request utilization = %+v", u)
+	}
+	if pod1.CPU.LimitUtilization != nil {
+		t.Errorf("newPodUsageList() pod1 CPU limit utilization should be omitted without a limit")
+	}
+
+	pod2 := list.Items[1]
+	if pod2.CPU.Usage != nil || pod2.Memory.Usage != nil {
+		t.Errorf("newPodUsageList() pod2 without metrics should omit usage")
+	}
+
+	// Unknown values must not show up in the encoded output
+	out, err := json.Marshal(pod2)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want := `{"namespace":"","name":"pod2","cpu":{},"memory":{}}`; string(out) != want {
+		t.Errorf("json.Marshal(pod2) = %s, want %s", out, want)
+	}
+}
+
+func TestNewNodeUsageList(t *testing.T) {
+	data := []CombinedNodeData{
+		{
+			Name: "node1", HasMetrics: true, CPUUsageMilli: 1000, CPUAllocatableMilli: 4000, CPULimitMilli: 6000,
+			MemoryUsageBytes: 2 << 30, MemoryAllocatableBytes: 8 << 30,
+		},
+	}
+
+	list := newNodeUsageList(data)
+	if list.Kind != "NodeUsageList" || len(list.Items) != 1 {
+		t.Fatalf("newNodeUsageList() kind = %s, items = %d", list.Kind, len(list.Items))
+	}
+
+	node := list.Items[0]
+	if node.CPU.Usage == nil || node.CPU.Usage.Formatted != "1" {
+		t.Errorf("newNodeUsageList() CPU usage = %+v", node.CPU.Usage)
+	}
+	if node.CPU.Percent == nil || node.CPU.Percent.Percent != 25 || node.CPU.Percent.Formatted != "25%" {
+		t.Errorf("newNodeUsageList() CPU percent = %+v", node.CPU.Percent)
+	}
+	if node.CPU.Allocatable == nil || node.CPU.Allocatable.Millicores != 4000 {
+		t.Errorf("newNodeUsageList() CPU allocatable = %+v", node.CPU.Allocatable)
+	}
+	if node.Memory.Percent == nil || node.Memory.Percent.Percent != 25 {
+		t.Errorf("newNodeUsageList() memory percent = %+v", node.Memory.Percent)
+	}
+	if p := node.CPU.LimitPercent; p == nil || p.Percent != 150 || p.Formatted != "150%" {
+		t.Errorf("newNodeUsageList() CPU limit percent = %+v", p)
+	}
+	if p := node.CPU.RequestPercent; p == nil || p.Percent != 0 {
+		t.Errorf("newNodeUsageList() CPU request percent = %+v, want 0%%", p)
+	}
+}
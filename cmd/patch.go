@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats of the recommend command printing the proposals as changes to apply
+const (
+	outputPatch   = "patch"
+	outputKubectl = "kubectl"
+)
+
+// workloadAPIVersions is the API version of every workload kind the proposals can be applied to.
+// Bare pods are left out: their resources can only change through an in-place resize. So are standalone
+// Jobs, whose pod template is immutable; CronJobs apply the change to the Jobs they create next.
+var workloadAPIVersions = map[string]string{
+	"Deployment":  "apps/v1",
+	"StatefulSet": "apps/v1",
+	"DaemonSet":   "apps/v1",
+	"ReplicaSet":  "apps/v1",
+	"CronJob":     "batch/v1",
+}
+
+// workloadPatch is a strategic merge patch of the container resources in the pod template of a workload.
+// It carries apiVersion, kind and metadata so it works both with 'kubectl patch' and as a kustomize patch.
+type workloadPatch struct {
+	APIVersion string                 `json:"apiVersion"`
+	Kind       string                 `json:"kind"`
+	Metadata   patchMetadata          `json:"metadata"`
+	Spec       map[string]interface{} `json:"spec"`
+}
+
+// patchMetadata identifies the patched workload
+type patchMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// containerPatch sets the resources of a container, matched by name by the strategic merge
+type containerPatch struct {
+	Name      string                      `json:"name"`
+	Resources corev1.ResourceRequirements `json:"resources"`
+}
+
+// groupRecommendations splits recommendations sorted by workload into the containers of each workload
+func groupRecommendations(data []CombinedRecommendationData) [][]CombinedRecommendationData {
+	var groups [][]CombinedRecommendationData
+	for i, d := range data {
+		if i == 0 || d.Namespace != data[i-1].Namespace || d.Kind != data[i-1].Kind ||
+			d.Workload != data[i-1].Workload {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], d)
+	}
+	return groups
+}
+
+// requirements returns the set values as container resource requirements
+func (v resourceValues) requirements() corev1.ResourceRequirements {
+	requirements := corev1.ResourceRequirements{}
+	set := func(list *corev1.ResourceList, name corev1.ResourceName, q *resource.Quantity) {
+		if q.IsZero() {
+			return
+		}
+		if *list == nil {
+			*list = corev1.ResourceList{}
+		}
+		(*list)[name] = *q
+	}
+
+	set(&requirements.Requests, corev1.ResourceCPU, resource.NewMilliQuantity(v.CPURequestMilli, resource.DecimalSI))
+	set(&requirements.Requests, corev1.ResourceMemory, resource.NewQuantity(v.MemoryRequestBytes, resource.BinarySI))
+	set(&requirements.Limits, corev1.ResourceCPU, resource.NewMilliQuantity(v.CPULimitMilli, resource.DecimalSI))
+	set(&requirements.Limits, corev1.ResourceMemory, resource.NewQuantity(v.MemoryLimitBytes, resource.BinarySI))
+	return requirements
+}
+
+// newWorkloadPatch returns the patch setting the proposed resources of the containers of a workload
+func newWorkloadPatch(apiVersion string, containers []CombinedRecommendationData) workloadPatch {
+	var appContainers, initContainers []containerPatch
+	for _, d := range containers {
+		p := containerPatch{Name: d.Container, Resources: d.Proposed.requirements()}
+		if d.Sidecar {
+			initContainers = append(initContainers, p)
+		} else {
+			appContainers = append(appContainers, p)
+		}
+	}
+
+	podSpec := make(map[string]interface{})
+	if len(appContainers) > 0 {
+		podSpec["containers"] = appContainers
+	}
+	if len(initContainers) > 0 {
+		podSpec["initContainers"] = initContainers
+	}
+
+	// CronJobs hold the pod template in their job template
+	spec := map[string]interface{}{"template": map[string]interface{}{"spec": podSpec}}
+	first := containers[0]
+	if first.Kind == "CronJob" {
+		spec = map[string]interface{}{"jobTemplate": map[string]interface{}{"spec": spec}}
+	}
+
+	return workloadPatch{
+		APIVersion: apiVersion,
+		Kind:       first.Kind,
+		Metadata:   patchMetadata{Name: first.Workload, Namespace: first.Namespace},
+		Spec:       spec,
+	}
+}
+
+// patchableWorkloads returns the containers of every workload the proposals can be applied to.
+// Other workloads are reported on stderr.
+func patchableWorkloads(data []CombinedRecommendationData) [][]CombinedRecommendationData {
+	var patchable [][]CombinedRecommendationData
+	for _, containers := range groupRecommendations(data) {
+		first := containers[0]
+		if _, ok := workloadAPIVersions[first.Kind]; !ok {
+			fmt.Fprintf(os.Stderr, "Skipping %s %s/%s: only the pod templates of workloads can be changed\n",
+				first.Kind, first.Namespace, first.Workload)
+			continue
+		}
+		patchable = append(patchable, containers)
+	}
+	return patchable
+}
+
+// savingsComment describes a workload and its projected savings as a comment line
+func savingsComment(containers []CombinedRecommendationData) string {
+	s := sumSavings(containers)[0]
+	return fmt.Sprintf("# %s %s/%s: projected savings %s CPU, %s memory",
+		s.Kind, s.Namespace, s.Name, formatCPUSavings(s.CPUMilli), formatMemorySavings(s.MemoryBytes))
+}
+
+// printPatches prints a strategic merge patch per workload as a stream of YAML documents
+func printPatches(data []CombinedRecommendationData) error {
+	for i, containers := range patchableWorkloads(data) {
+		out, err := yaml.Marshal(newWorkloadPatch(workloadAPIVersions[containers[0].Kind], containers))
+		if err != nil {
+			return fmt.Errorf("failed to encode patch: %w", err)
+		}
+		if i > 0 {
+			fmt.Println("---")
+		}
+		fmt.Println(savingsComment(containers))
+		fmt.Print(string(out))
+	}
+	return nil
+}
+
+// printSetResources prints a 'kubectl set resources' command per workload container
+func printSetResources(data []CombinedRecommendationData) error {
+	for i, containers := range patchableWorkloads(data) {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(savingsComment(containers))
+		for _, d := range containers {
+			command := fmt.Sprintf("kubectl set resources %s/%s --namespace=%s --containers=%s",
+				strings.ToLower(d.Kind), d.Workload, d.Namespace, d.Container)
+			requirements := d.Proposed.requirements()
+			if len(requirements.Requests) > 0 {
+				command += " --requests=" + formatResourceList(requirements.Requests)
+			}
+			if len(requirements.Limits) > 0 {
+				command += " --limits=" + formatResourceList(requirements.Limits)
+			}
+			fmt.Println(command)
+		}
+	}
+	return nil
+}
+
+// formatResourceList formats CPU and memory as "cpu=200m,memory=256Mi", the syntax of 'kubectl set resources'
+func formatResourceList(list corev1.ResourceList) string {
+	var values []string
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if q, ok := list[name]; ok {
+			values = append(values, string(name)+"="+q.String())
+		}
+	}
+	return strings.Join(values, ",")
+}
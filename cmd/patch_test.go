@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// testPatchRecommendations returns the recommendations of a deployment with a sidecar, a cronjob, a job and a bare pod
+func testPatchRecommendations() []CombinedRecommendationData {
+	return []CombinedRecommendationData{
+		{
+			Namespace: "batch", Kind: "CronJob", Workload: "report", Container: "main", Pods: 1,
+			Proposed: resourceValues{CPURequestMilli: 1000, MemoryRequestBytes: 1 << 30},
+		},
+		{
+			Namespace: "batch", Kind: "Job", Workload: "migrate", Container: "main", Pods: 1,
+			Proposed: resourceValues{CPURequestMilli: 500},
+		},
+		{
+			Namespace: "default", Kind: "Deployment", Workload: "web", Container: "app", Pods: 2,
+			Proposed: resourceValues{CPURequestMilli: 200, CPULimitMilli: 300, MemoryRequestBytes: 256 << 20,
+				MemoryLimitBytes: 320 << 20},
+			totalCPURequestMilli: 1000,
+		},
+		{
+			Namespace: "default", Kind: "Deployment", Workload: "web", Container: "mesh", Sidecar: true, Pods: 2,
+			Proposed: resourceValues{CPURequestMilli: 50},
+		},
+		{
+			Namespace: "default", Kind: "Pod", Workload: "debug", Container: "shell", Pods: 1,
+			Proposed: resourceValues{CPURequestMilli: 10},
+		},
+	}
+}
+
+func TestPrintPatches(t *testing.T) {
+	output := captureStdout(t, func() {
+		if err := printPatches(testPatchRecommendations()); err != nil {
+			t.Errorf("printPatches() error = %v", err)
+		}
+	})
+
+	documents := strings.Split(output, "---\n")
+	if len(documents) != 2 {
+		t.Fatalf("printPatches() printed %d documents, want 2 (jobs and bare pods are skipped). Output: %s",
+			len(documents), output)
+	}
+
+	cronJob := documents[0]
+	for _, want := range []string{"# CronJob batch/report", "apiVersion: batch/v1", "kind: CronJob",
+		"jobTemplate:", "name: main", "cpu: \"1\"", "memory: 1Gi"} {
+		if !strings.Contains(cronJob, want) {
+			t.Errorf("printPatches() CronJob patch missing %q. Output: %s", want, cronJob)
+		}
+	}
+
+	deployment := documents[1]
+	for _, want := range []string{"# Deployment default/web: projected savings 500m CPU", "apiVersion: apps/v1",
+		"namespace: default", "template:", "name: app", "cpu: 200m", "memory: 256Mi", "memory: 320Mi",
+		"initContainers:", "name: mesh"} {
+		if !strings.Contains(deployment, want) {
+			t.Errorf("printPatches() Deployment patch missing %q. Output: %s", want, deployment)
+		}
+	}
+	if strings.Contains(deployment, "jobTemplate") {
+		t.Errorf("printPatches() Deployment patch should use spec.template. Output: %s", deployment)
+	}
+}
+
+func TestPrintSetResources(t *testing.T) {
+	output := captureStdout(t, func() {
+		if err := printSetResources(testPatchRecommendations()); err != nil {
+			t.Errorf("printSetResources() error = %v", err)
+		}
+	})
+
+	want := "kubectl set resources deployment/web --namespace=default --containers=app " +
+		"--requests=cpu=200m,memory=256Mi --limits=cpu=300m,memory=320Mi"
+	if !strings.Contains(output, want) {
+		t.Errorf("printSetResources() output missing %q. Output: %s", want, output)
+	}
+	if !strings.Contains(output, "--containers=mesh --requests=cpu=50m\n") {
+		t.Errorf("printSetResources() should leave out unset values. Output: %s", output)
+	}
+	if strings.Contains(output, "debug") || strings.Contains(output, "migrate") {
+		t.Errorf("printSetResources() should skip jobs and bare pods. Output: %s", output)
+	}
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/veditoid/kubectl-rltop/pkg"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CombinedQuotaData represents the used and hard values of one compute resource of a namespace quota.
+// CPU is in millicores and memory in bytes.
+type CombinedQuotaData struct {
+	Namespace string
+	Resource  string // requests.cpu, limits.cpu, requests.memory or limits.memory
+	Used      int64
+	Hard      int64
+}
+
+// QuotaOptions holds the options of the quota command
+type QuotaOptions struct {
+	Namespace string // Empty means all namespaces
+	SortBy    string
+	NoHeaders bool
+	Output    string
+	Watch     bool
+	Interval  time.Duration
+}
+
+// RunQuota executes the quota command. Quotas come from the API server, so the Metrics API is not needed.
+func RunQuota(ctx context.Context, clientset kubernetes.Interface, opts QuotaOptions) error {
+	refresh := func(ctx context.Context) error {
+		quotas, err := pkg.GetNamespaceQuotas(ctx, clientset, opts.Namespace)
+		if err != nil {
+			return err
+		}
+		combined := combineQuotas(quotas)
+		sortQuotaData(combined, opts.SortBy)
+		return printQuotaData(combined, opts)
+	}
+
+	if opts.Watch {
+		return runWatch(ctx, opts.Interval, opts.Output, refresh)
+	}
+	return refresh(ctx)
+}
+
+// printQuotaData prints the combined quota data in the requested output format
+func printQuotaData(combined []CombinedQuotaData, opts QuotaOptions) error {
+	if isStructuredOutput(opts.Output) {
+		return printStructured(newResourceQuotaUsageList(combined), opts.Output)
+	}
+
+	if len(combined) == 0 {
+		fmt.Fprintf(os.Stderr, "No resource quotas found\n")
+		return nil
+	}
+
+	printQuotaTable(combined, quotaTableOptions{
+		NoHeaders:     opts.NoHeaders,
+		ShowNamespace: opts.Namespace == "",
+	})
+
+	return nil
+}
+
+// combineQuotas returns a row for every compute resource limited by a namespace quota
+func combineQuotas(quotas map[string]*pkg.NamespaceQuota) []CombinedQuotaData {
+	combined := make([]CombinedQuotaData, 0, len(quotas)*4)
+	for namespace, quota := range quotas {
+		for _, r := range []struct {
+			name  corev1.ResourceName
+			usage pkg.QuotaUsage
+		}{
+			{corev1.ResourceRequestsCPU, quota.CPURequest},
+			{corev1.ResourceLimitsCPU, quota.CPULimit},
+			{corev1.ResourceRequestsMemory, quota.MemoryRequest},
+			{corev1.ResourceLimitsMemory, quota.MemoryLimit},
+		} {
+			if r.usage.Limited {
+				combined = append(combined, CombinedQuotaData{
+					Namespace: namespace,
+					Resource:  string(r.name),
+					Used:      r.usage.Used,
+					Hard:      r.usage.Hard,
+				})
+			}
+		}
+	}
+	return combined
+}
+
+// isCPU reports whether the row is a CPU resource, in millicores
+func (d CombinedQuotaData) isCPU() bool {
+	return strings.HasSuffix(d.Resource, ".cpu")
+}
+
+// left returns the part of the quota that is not used yet, negative if the quota was lowered below usage
+func (d CombinedQuotaData) left() int64 {
+	return d.Hard - d.Used
+}
+
+// quotaColumns formats the used, hard and left values and the percentage left.
+// Memory values use the unit of the hard value.
+func (d CombinedQuotaData) quotaColumns() (used, hard, left, percentLeft string) {
+	format := formatCPUMilli
+	if !d.isCPU() {
+		unit := pkg.MemoryUnit(d.Hard)
+		format = func(bytes int64) string { return formatMemoryBytesIn(bytes, unit) }
+	}
+	return format(d.Used), format(d.Hard), format(d.left()), pkg.FormatUtilization(d.left(), d.Hard)
+}
+
+// quotaTableOptions controls which columns printQuotaTable prints
+type quotaTableOptions struct {
+	NoHeaders     bool
+	ShowNamespace bool // Add a leading NAMESPACE column
+}
+
+// printQuotaTable prints the combined quota data in a formatted table
+func printQuotaTable(data []CombinedQuotaData, opts quotaTableOptions) {
+	// Calculate column widths
+	namespaceWidth := 20
+	resourceWidth := 15
+	valueWidth := 15
+
+	for _, d := range data {
+		if len(d.Namespace) > namespaceWidth {
+			namespaceWidth = len(d.Namespace)
+		}
+	}
+
+	// Print header unless --no-headers is set
+	if !opts.NoHeaders {
+		var header string
+		if opts.ShowNamespace {
+			header = fmt.Sprintf("%-*s  ", namespaceWidth, "NAMESPACE")
+		}
+		header += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %s",
+			resourceWidth, "RESOURCE",
+			valueWidth, "USED",
+			valueWidth, "HARD",
+			valueWidth, "LEFT",
+			"LEFT%",
+		)
+		fmt.Println(header)
+	}
+
+	// Print rows
+	for _, d := range data {
+		used, hard, left, percentLeft := d.quotaColumns()
+		var row string
+		if opts.ShowNamespace {
+			row = fmt.Sprintf("%-*s  ", namespaceWidth, d.Namespace)
+		}
+		row += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %s",
+			resourceWidth, d.Resource,
+			valueWidth, used,
+			valueWidth, hard,
+			valueWidth, left,
+			percentLeft,
+		)
+		fmt.Println(row)
+	}
+}
+
+// resourceOrder is the order of the rows of a namespace
+var resourceOrder = map[string]int{
+	string(corev1.ResourceRequestsCPU):    0,
+	string(corev1.ResourceLimitsCPU):      1,
+	string(corev1.ResourceRequestsMemory): 2,
+	string(corev1.ResourceLimitsMemory):   3,
+}
+
+// sortQuotaData sorts the combined quota data based on the sortBy field.
+// Ties keep the default namespace/resource order.
+func sortQuotaData(data []CombinedQuotaData, sortBy string) {
+	sort.Slice(data, func(i, j int) bool {
+		if data[i].Namespace != data[j].Namespace {
+			return data[i].Namespace < data[j].Namespace
+		}
+		return resourceOrder[data[i].Resource] < resourceOrder[data[j].Resource]
+	})
+
+	field, descending := parseSortBy(sortBy)
+	if field == "namespace" {
+		if descending {
+			sort.SliceStable(data, func(i, j int) bool { return data[i].Namespace > data[j].Namespace })
+		}
+		return
+	}
+	if value, ok := quotaSortValues[field]; ok {
+		sortByValue(data, value, descending)
+	}
+}
+
+// NewQuotaCommand creates a new quota command
+func NewQuotaCommand() *cobra.Command {
+	factory := newClientFactory()
+	var opts QuotaOptions
+	var allNamespaces bool
+
+	cmd := &cobra.Command{
+		Use:     "quota",
+		Aliases: []string{"quotas", "resourcequota"},
+		Short:   "Display the CPU and memory ResourceQuota used, hard and left per namespace",
+		Long: `Display the CPU and memory ResourceQuota used, hard and left per namespace.
+One row is printed for every requests.cpu, limits.cpu, requests.memory and limits.memory
+quota (cpu and memory count as requests). When several quotas limit the same resource, the
+one with the smallest hard value is shown. Scoped quotas are skipped.
+
+A rollout fails when the new pods do not fit in what is left of the quota.
+
+Examples:
+  # Show the quota of the current namespace
+  kubectl rltop quota
+
+  # Show the quotas closest to being exhausted across all namespaces
+  kubectl rltop quota -A --sort-by=left:asc`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(opts.Output, quotaOutputFormats); err != nil {
+				return err
+			}
+			if err := validateWatchInterval(opts.Watch, opts.Interval); err != nil {
+				return err
+			}
+			if err := validateSortBy(opts.SortBy, quotaSortFields); err != nil {
+				return err
+			}
+
+			// Get namespace from context if not specified
+			if !allNamespaces && opts.Namespace == "" {
+				opts.Namespace = factory.Namespace()
+			}
+
+			// Handle -A/--all-namespaces flag (must be after namespace detection)
+			if allNamespaces {
+				opts.Namespace = ""
+			}
+
+			clientset, _, err := factory.Clients()
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			return RunQuota(ctx, clientset, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace to query (default: namespace from current context, or 'default')")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false,
+		"If present, list the requested object(s) across all namespaces. "+
+			"Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().StringVar(&opts.SortBy, "sort-by", "",
+		"If non-empty, sort quotas list using specified field. One of: "+strings.Join(quotaSortFields, ", ")+". "+
+			"'left' is the percentage of the quota left, 'used' the percentage used. "+
+			"Append ':asc' or ':desc' to choose the direction (numeric fields default to descending, names to ascending).")
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"If present, print output without headers.")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "",
+		"Output format. One of: (json, yaml).")
+	addWatchFlags(cmd, &opts.Watch, &opts.Interval)
+	factory.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+)
+
+func TestCombineQuotas(t *testing.T) {
+	quotas := map[string]*pkg.NamespaceQuota{
+		"team-a": {
+			CPURequest:  pkg.QuotaUsage{Limited: true, Used: 1500, Hard: 2000},
+			MemoryLimit: pkg.QuotaUsage{Limited: true, Used: 1 << 30, Hard: 4 << 30},
+		},
+		"team-b": {
+			CPURequest: pkg.QuotaUsage{Limited: true, Used: 500, Hard: 4000},
+			CPULimit:   pkg.QuotaUsage{Limited: true, Used: 2500, Hard: 2000},
+		},
+	}
+
+	data := combineQuotas(quotas)
+	sortQuotaData(data, "")
+
+	want := []string{"team-a/requests.cpu", "team-a/limits.memory", "team-b/requests.cpu", "team-b/limits.cpu"}
+	if len(data) != len(want) {
+		t.Fatalf("combineQuotas() returned %d rows, want %d: %+v", len(data), len(want), data)
+	}
+	for i, w := range want {
+		if got := data[i].Namespace + "/" + data[i].Resource; got != w {
+			t.Errorf("combineQuotas()[%d] = %s, want %s", i, got, w)
+		}
+	}
+
+	sortQuotaData(data, "left:asc")
+	if got := data[0].Namespace + "/" + data[0].Resource; got != "team-b/limits.cpu" {
+		t.Errorf("sortQuotaData(left:asc) first row = %s, want the overcommitted team-b/limits.cpu", got)
+	}
+}
+
+func TestQuotaColumns(t *testing.T) {
+	tests := []struct {
+		name                          string
+		data                          CombinedQuotaData
+		used, hard, left, percentLeft string
+	}{
+		{
+			name:        "cpu",
+			data:        CombinedQuotaData{Resource: "requests.cpu", Used: 1500, Hard: 2000},
+			used:        "1500m",
+			hard:        "2000m",
+			left:        "500m",
+			percentLeft: "25%",
+		},
+		{
+			name:        "memory in the unit of the hard value",
+			data:        CombinedQuotaData{Resource: "limits.memory", Used: 1 << 30, Hard: 4 << 30},
+			used:        "1.00Gi",
+			hard:        "4.00Gi",
+			left:        "3.00Gi",
+			percentLeft: "75%",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used, hard, left, percentLeft := tt.data.quotaColumns()
+			if used != tt.used || hard != tt.hard || left != tt.left || percentLeft != tt.percentLeft {
+				t.Errorf("quotaColumns() = %s, %s, %s, %s, want %s, %s, %s, %s",
+					used, hard, left, percentLeft, tt.used, tt.hard, tt.left, tt.percentLeft)
+			}
+		})
+	}
+}
@@ -0,0 +1,734 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/veditoid/kubectl-rltop/pkg"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Defaults of the recommend command
+const (
+	defaultRecommendWindow = 5 * time.Minute
+	defaultCPURound        = "10m"
+	defaultMemoryRound     = "16Mi"
+)
+
+// resourceValues holds CPU requests and limits in millicores and memory requests and limits in bytes.
+// Zero means unset.
+type resourceValues struct {
+	CPURequestMilli    int64
+	CPULimitMilli      int64
+	MemoryRequestBytes int64
+	MemoryLimitBytes   int64
+}
+
+// CombinedRecommendationData represents the current and proposed resources of a container of a workload.
+// Current values are the largest over the pods of the workload.
+type CombinedRecommendationData struct {
+	Namespace string
+	Kind      string
+	Workload  string
+	Container string
+	Sidecar   bool // Native sidecar, declared in the init containers of the pod template
+	Pods      int  // Number of pods running the container at the last poll
+	Samples   int  // Number of usage samples the proposal is based on, over all pods
+	Current   resourceValues
+	Proposed  resourceValues
+
+	// Requests summed over the pods, to project the savings (pods can differ during a rollout)
+	totalCPURequestMilli    int64
+	totalMemoryRequestBytes int64
+}
+
+// workloadSavings is the projected change of the requests of a workload, positive when requests go down
+type workloadSavings struct {
+	Namespace   string
+	Kind        string
+	Name        string
+	Pods        int
+	CPUMilli    int64
+	MemoryBytes int64
+}
+
+// RecommendOptions holds the options of the recommend command
+type RecommendOptions struct {
+	Namespace      string // Empty means all namespaces
+	LabelSelector  string
+	WorkloadNames  []string // NAME or KIND/NAME
+	Window         time.Duration
+	SampleInterval time.Duration
+	CPU            pkg.RecommendationPolicy // Round in millicores
+	Memory         pkg.RecommendationPolicy // Round in bytes
+	NoHeaders      bool
+	Output         string
+}
+
+// containerKey identifies a container of a pod
+type containerKey struct {
+	Namespace string
+	Pod       string
+	Container string
+}
+
+// containerSamples holds the usage samples of a container (or of a container of all pods of a workload)
+type containerSamples struct {
+	CPUMilli    []int64
+	MemoryBytes []int64
+}
+
+// usageSamples collects container usage and pod resources over repeated polls
+type usageSamples struct {
+	containers map[containerKey]*containerSamples
+	pods       map[string]pkg.PodResources // Latest resources of every pod seen, keyed by "namespace/name"
+	running    map[string]bool             // Pods reporting usage at the last poll
+}
+
+// newUsageSamples returns an empty usageSamples
+func newUsageSamples() *usageSamples {
+	return &usageSamples{
+		containers: make(map[containerKey]*containerSamples),
+		pods:       make(map[string]pkg.PodResources),
+		running:    make(map[string]bool),
+	}
+}
+
+// add records the result of one poll
+func (s *usageSamples) add(metrics []pkg.PodMetrics, resources []pkg.PodResources) {
+	for _, r := range resources {
+		s.pods[r.Namespace+"/"+r.Name] = r
+	}
+
+	s.running = make(map[string]bool, len(metrics))
+	for _, m := range metrics {
+		s.running[m.Namespace+"/"+m.Name] = true
+		for _, c := range m.Containers {
+			key := containerKey{Namespace: m.Namespace, Pod: m.Name, Container: c.Name}
+			samples, ok := s.containers[key]
+			if !ok {
+				samples = &containerSamples{}
+				s.containers[key] = samples
+			}
+			samples.CPUMilli = append(samples.CPUMilli, c.CPUMilli)
+			samples.MemoryBytes = append(samples.MemoryBytes, c.MemoryBytes)
+		}
+	}
+}
+
+// resources returns the latest resources of every pod seen, including pods that are gone since
+func (s *usageSamples) resources() []pkg.PodResources {
+	resources := make([]pkg.PodResources, 0, len(s.pods))
+	for _, r := range s.pods {
+		resources = append(resources, r)
+	}
+	return resources
+}
+
+// RunRecommend executes the recommend command
+func RunRecommend(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	opts RecommendOptions,
+) error {
+	if err := source.Check(ctx); err != nil {
+		return err
+	}
+
+	combined, err := fetchRecommendationData(ctx, clientset, source, opts)
+	if err != nil {
+		return err
+	}
+	return printRecommendationData(combined, opts)
+}
+
+// fetchRecommendationData samples pod usage over the window, resolves the workload of every pod seen
+// and returns the proposed resources per workload container, filtered and sorted
+func fetchRecommendationData(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	opts RecommendOptions,
+) ([]CombinedRecommendationData, error) {
+	samples := newUsageSamples()
+	poll := func(ctx context.Context) error {
+		metrics, resources, err := fetchPodMetricsAndResources(ctx, clientset, source,
+			opts.Namespace, opts.LabelSelector, "", nil)
+		if err != nil {
+			return err
+		}
+		samples.add(metrics, resources)
+		return nil
+	}
+
+	if polls := samplePolls(opts.Window, opts.SampleInterval); polls > 1 {
+		fmt.Fprintf(os.Stderr, "Sampling usage every %s for %s (%d polls)...\n", opts.SampleInterval, opts.Window, polls)
+	}
+	if err := sampleUsage(ctx, opts.Window, opts.SampleInterval, poll); err != nil {
+		return nil, err
+	}
+	// Sampling may have been interrupted: the proposals are still made from the samples taken
+	if ctx.Err() != nil {
+		ctx = context.WithoutCancel(ctx)
+	}
+
+	workloads, err := pkg.ResolveWorkloads(ctx, clientset, opts.Namespace, samples.resources())
+	if err != nil {
+		return nil, err
+	}
+
+	combined := combineRecommendations(samples, workloads, opts.CPU, opts.Memory)
+	combined = filterRecommendations(combined, opts.WorkloadNames)
+
+	// Sort by namespace, kind, workload, then container
+	sortRecommendationData(combined)
+
+	return combined, nil
+}
+
+// samplePolls returns the number of polls in a window: one now, then one every interval
+func samplePolls(window, interval time.Duration) int {
+	return int(window/interval) + 1
+}
+
+// sampleUsage calls poll immediately and then every interval until the window has elapsed.
+// Only the first poll must succeed; later errors are reported on stderr and that sample is skipped.
+// When ctx is cancelled after a successful poll, sampling stops and the samples taken so far are kept.
+func sampleUsage(ctx context.Context, window, interval time.Duration, poll func(context.Context) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	sampled := 0
+	polls := samplePolls(window, interval)
+	for i := 0; i < polls; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return interruptedSampling(ctx, sampled, time.Since(start), window)
+			case <-ticker.C:
+			}
+		}
+
+		if err := poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return interruptedSampling(ctx, sampled, time.Since(start), window)
+			}
+			if i == 0 {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+		sampled++
+	}
+	return nil
+}
+
+// interruptedSampling reports on stderr how much of the window was sampled before ctx was cancelled.
+// It fails only when no poll succeeded.
+func interruptedSampling(ctx context.Context, sampled int, elapsed, window time.Duration) error {
+	if sampled == 0 {
+		return fmt.Errorf("sampling interrupted: %w", ctx.Err())
+	}
+	fmt.Fprintf(os.Stderr, "Sampling interrupted after %s of the %s window (%d samples), proposing from them\n",
+		elapsed.Round(time.Second), window, sampled)
+	return nil
+}
+
+// combineRecommendations pools the samples of every container of a workload across its pods and proposes
+// requests and limits from them. Only pods reporting usage at the last poll count as running the workload;
+// samples of pods replaced during the window are kept.
+func combineRecommendations(
+	samples *usageSamples,
+	workloads map[string]pkg.Workload,
+	cpu, memory pkg.RecommendationPolicy,
+) []CombinedRecommendationData {
+	workloadOf := func(namespace, pod string) pkg.Workload {
+		if workload, ok := workloads[namespace+"/"+pod]; ok {
+			return workload
+		}
+		return pkg.Workload{Kind: pkg.KindPod, Name: pod}
+	}
+
+	type groupKey struct {
+		Namespace string
+		Workload  pkg.Workload
+		Container string
+	}
+	pooled := make(map[groupKey]*containerSamples)
+	for key, s := range samples.containers {
+		group := groupKey{Namespace: key.Namespace, Workload: workloadOf(key.Namespace, key.Pod), Container: key.Container}
+		p, ok := pooled[group]
+		if !ok {
+			p = &containerSamples{}
+			pooled[group] = p
+		}
+		p.CPUMilli = append(p.CPUMilli, s.CPUMilli...)
+		p.MemoryBytes = append(p.MemoryBytes, s.MemoryBytes...)
+	}
+
+	combined := make([]CombinedRecommendationData, 0)
+	index := make(map[groupKey]int)
+	for podKey := range samples.running {
+		r, ok := samples.pods[podKey]
+		if !ok {
+			continue
+		}
+		workload := workloadOf(r.Namespace, r.Name)
+		for _, c := range r.Containers {
+			group := groupKey{Namespace: r.Namespace, Workload: workload, Container: c.Name}
+			s, ok := pooled[group]
+			if !ok {
+				// No usage samples to base a proposal on
+				continue
+			}
+
+			i, ok := index[group]
+			if !ok {
+				i = len(combined)
+				index[group] = i
+				d := CombinedRecommendationData{
+					Namespace: r.Namespace,
+					Kind:      workload.Kind,
+					Workload:  workload.Name,
+					Container: c.Name,
+					Sidecar:   c.Sidecar,
+					Samples:   len(s.CPUMilli),
+				}
+				d.Proposed.CPURequestMilli, d.Proposed.CPULimitMilli, _ = cpu.Recommend(s.CPUMilli)
+				d.Proposed.MemoryRequestBytes, d.Proposed.MemoryLimitBytes, _ = memory.Recommend(s.MemoryBytes)
+				combined = append(combined, d)
+			}
+
+			d := &combined[i]
+			d.Pods++
+			d.Current.CPURequestMilli = max(d.Current.CPURequestMilli, c.CPURequestMilli)
+			d.Current.CPULimitMilli = max(d.Current.CPULimitMilli, c.CPULimitMilli)
+			d.Current.MemoryRequestBytes = max(d.Current.MemoryRequestBytes, c.MemoryRequestBytes)
+			d.Current.MemoryLimitBytes = max(d.Current.MemoryLimitBytes, c.MemoryLimitBytes)
+			d.totalCPURequestMilli += c.CPURequestMilli
+			d.totalMemoryRequestBytes += c.MemoryRequestBytes
+		}
+	}
+
+	return combined
+}
+
+// filterRecommendations keeps the containers of the workloads matching one of the names, see matchWorkload
+func filterRecommendations(data []CombinedRecommendationData, names []string) []CombinedRecommendationData {
+	if len(names) == 0 {
+		return data
+	}
+
+	filtered := make([]CombinedRecommendationData, 0, len(data))
+	for _, d := range data {
+		if matchWorkload(d.Kind, d.Workload, names) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// savings returns the projected decrease of the requests of the container over all its pods
+func (d CombinedRecommendationData) savings() (cpuMilli, memoryBytes int64) {
+	pods := int64(d.Pods)
+	return d.totalCPURequestMilli - pods*d.Proposed.CPURequestMilli,
+		d.totalMemoryRequestBytes - pods*d.Proposed.MemoryRequestBytes
+}
+
+// sumSavings adds up the savings of the containers of every workload, in the order of data
+func sumSavings(data []CombinedRecommendationData) []workloadSavings {
+	savings := make([]workloadSavings, 0)
+	index := make(map[string]int)
+
+	for _, d := range data {
+		key := d.Namespace + "/" + d.Kind + "/" + d.Workload
+		i, ok := index[key]
+		if !ok {
+			i = len(savings)
+			index[key] = i
+			savings = append(savings, workloadSavings{Namespace: d.Namespace, Kind: d.Kind, Name: d.Workload})
+		}
+
+		cpuMilli, memoryBytes := d.savings()
+		s := &savings[i]
+		s.Pods = max(s.Pods, d.Pods)
+		s.CPUMilli += cpuMilli
+		s.MemoryBytes += memoryBytes
+	}
+
+	return savings
+}
+
+// recommendationColumns formats the current and proposed values as "current -> proposed".
+// Memory values use the unit of the proposed request.
+func (d CombinedRecommendationData) recommendationColumns() (cpuRequest, cpuLimit, memoryRequest, memoryLimit string) {
+	unit := pkg.MemoryUnit(d.Proposed.MemoryRequestBytes)
+	formatMemory := func(bytes int64) string { return formatMemoryBytesIn(bytes, unit) }
+	change := func(current, proposed string) string { return current + " -> " + proposed }
+
+	return change(formatCPUMilli(d.Current.CPURequestMilli), formatCPUMilli(d.Proposed.CPURequestMilli)),
+		change(formatCPUMilli(d.Current.CPULimitMilli), formatCPUMilli(d.Proposed.CPULimitMilli)),
+		change(formatMemory(d.Current.MemoryRequestBytes), formatMemory(d.Proposed.MemoryRequestBytes)),
+		change(formatMemory(d.Current.MemoryLimitBytes), formatMemory(d.Proposed.MemoryLimitBytes))
+}
+
+// formatCPUSavings formats a CPU change in millicores, negative when requests go up
+func formatCPUSavings(millicores int64) string {
+	if millicores < 0 {
+		return "-" + pkg.FormatCPU(-millicores)
+	}
+	return pkg.FormatCPU(millicores)
+}
+
+// formatMemorySavings formats a memory change in bytes, negative when requests go up
+func formatMemorySavings(bytes int64) string {
+	if bytes < 0 {
+		return "-" + pkg.FormatMemory(-bytes)
+	}
+	return pkg.FormatMemory(bytes)
+}
+
+// printRecommendationData prints the recommendations in the requested output format
+func printRecommendationData(combined []CombinedRecommendationData, opts RecommendOptions) error {
+	if isStructuredOutput(opts.Output) {
+		return printStructured(newRecommendationList(combined), opts.Output)
+	}
+
+	if len(combined) == 0 {
+		fmt.Fprintf(os.Stderr, "No containers with usage samples found\n")
+		return nil
+	}
+
+	switch opts.Output {
+	case outputPatch:
+		return printPatches(combined)
+	case outputKubectl:
+		return printSetResources(combined)
+	}
+
+	tableOpts := recommendationTableOptions{
+		NoHeaders:     opts.NoHeaders,
+		ShowNamespace: opts.Namespace == "",
+	}
+	printRecommendationTable(combined, tableOpts)
+	fmt.Println()
+	printSavingsTable(sumSavings(combined), tableOpts)
+
+	return nil
+}
+
+// recommendationTableOptions controls which columns printRecommendationTable and printSavingsTable print
+type recommendationTableOptions struct {
+	NoHeaders     bool
+	ShowNamespace bool // Add a leading NAMESPACE column
+}
+
+// printRecommendationTable prints the current and proposed resources of every workload container
+func printRecommendationTable(data []CombinedRecommendationData, opts recommendationTableOptions) {
+	// Calculate column widths
+	namespaceWidth := 20
+	kindWidth := 11
+	nameWidth := 30
+	containerWidth := 20
+	podsWidth := 4
+	samplesWidth := 7
+	cpuWidth := 16
+	memWidth := 24
+
+	for _, d := range data {
+		if len(d.Namespace) > namespaceWidth {
+			namespaceWidth = len(d.Namespace)
+		}
+		if len(d.Kind) > kindWidth {
+			kindWidth = len(d.Kind)
+		}
+		if len(d.Workload) > nameWidth {
+			nameWidth = len(d.Workload)
+		}
+		if len(d.Container) > containerWidth {
+			containerWidth = len(d.Container)
+		}
+	}
+
+	// Print header unless --no-headers is set
+	if !opts.NoHeaders {
+		var header string
+		if opts.ShowNamespace {
+			header = fmt.Sprintf("%-*s  ", namespaceWidth, "NAMESPACE")
+		}
+		header += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %s",
+			kindWidth, "KIND",
+			nameWidth, "NAME",
+			containerWidth, "CONTAINER",
+			podsWidth, "PODS",
+			samplesWidth, "SAMPLES",
+			cpuWidth, "CPU REQUEST",
+			cpuWidth, "CPU LIMIT",
+			memWidth, "MEMORY REQUEST",
+			"MEMORY LIMIT",
+		)
+		fmt.Println(header)
+	}
+
+	// Print rows
+	for _, d := range data {
+		cpuRequest, cpuLimit, memoryRequest, memoryLimit := d.recommendationColumns()
+		var row string
+		if opts.ShowNamespace {
+			row = fmt.Sprintf("%-*s  ", namespaceWidth, d.Namespace)
+		}
+		row += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %s",
+			kindWidth, d.Kind,
+			nameWidth, d.Workload,
+			containerWidth, d.Container,
+			podsWidth, strconv.Itoa(d.Pods),
+			samplesWidth, strconv.Itoa(d.Samples),
+			cpuWidth, cpuRequest,
+			cpuWidth, cpuLimit,
+			memWidth, memoryRequest,
+			memoryLimit,
+		)
+		fmt.Println(row)
+	}
+}
+
+// printSavingsTable prints the projected request savings of every workload
+func printSavingsTable(data []workloadSavings, opts recommendationTableOptions) {
+	// Calculate column widths
+	namespaceWidth := 20
+	kindWidth := 11
+	nameWidth := 30
+	podsWidth := 4
+	cpuWidth := 12
+
+	for _, d := range data {
+		if len(d.Namespace) > namespaceWidth {
+			namespaceWidth = len(d.Namespace)
+		}
+		if len(d.Kind) > kindWidth {
+			kindWidth = len(d.Kind)
+		}
+		if len(d.Name) > nameWidth {
+			nameWidth = len(d.Name)
+		}
+	}
+
+	// Print header unless --no-headers is set
+	if !opts.NoHeaders {
+		var header string
+		if opts.ShowNamespace {
+			header = fmt.Sprintf("%-*s  ", namespaceWidth, "NAMESPACE")
+		}
+		header += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %s",
+			kindWidth, "KIND",
+			nameWidth, "NAME",
+			podsWidth, "PODS",
+			cpuWidth, "CPU SAVINGS",
+			"MEMORY SAVINGS",
+		)
+		fmt.Println(header)
+	}
+
+	// Print rows
+	for _, d := range data {
+		var row string
+		if opts.ShowNamespace {
+			row = fmt.Sprintf("%-*s  ", namespaceWidth, d.Namespace)
+		}
+		row += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %s",
+			kindWidth, d.Kind,
+			nameWidth, d.Name,
+			podsWidth, strconv.Itoa(d.Pods),
+			cpuWidth, formatCPUSavings(d.CPUMilli),
+			formatMemorySavings(d.MemoryBytes),
+		)
+		fmt.Println(row)
+	}
+}
+
+// sortRecommendationData sorts the recommendations by namespace, kind, workload, then container
+func sortRecommendationData(data []CombinedRecommendationData) {
+	sort.Slice(data, func(i, j int) bool {
+		if data[i].Namespace != data[j].Namespace {
+			return data[i].Namespace < data[j].Namespace
+		}
+		if data[i].Kind != data[j].Kind {
+			return data[i].Kind < data[j].Kind
+		}
+		if data[i].Workload != data[j].Workload {
+			return data[i].Workload < data[j].Workload
+		}
+		return data[i].Container < data[j].Container
+	})
+}
+
+// validateRecommendationPolicy returns an error if a percentile is outside (0, 100] or the headroom is negative
+func validateRecommendationPolicy(policy pkg.RecommendationPolicy) error {
+	for _, p := range []struct {
+		flag  string
+		value float64
+	}{
+		{"--percentile", policy.RequestPercentile},
+		{"--limit-percentile", policy.LimitPercentile},
+	} {
+		if p.value <= 0 || p.value > 100 {
+			return fmt.Errorf("invalid %s %v, must be greater than 0 and at most 100", p.flag, p.value)
+		}
+	}
+	if policy.Headroom < 0 {
+		return fmt.Errorf("invalid --headroom %v, must not be negative", policy.Headroom)
+	}
+	return nil
+}
+
+// parseRound parses a --cpu-round or --memory-round quantity, in millicores for CPU and bytes for memory
+func parseRound(flag, value string, isCPU bool) (int64, error) {
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", flag, value, err)
+	}
+	round := q.Value()
+	if isCPU {
+		round = q.MilliValue()
+	}
+	if round <= 0 {
+		return 0, fmt.Errorf("invalid %s %q, must be greater than zero", flag, value)
+	}
+	return round, nil
+}
+
+// NewRecommendCommand creates a new recommend command
+func NewRecommendCommand() *cobra.Command {
+	factory := newClientFactory()
+	var opts RecommendOptions
+	var sourceOpts metricsSourceOptions
+	var allNamespaces bool
+	var policy pkg.RecommendationPolicy
+	var cpuRound, memoryRound string
+
+	cmd := &cobra.Command{
+		Use:     "recommend [NAME | KIND/NAME | -l label]",
+		Aliases: []string{"recommendations", "rec"},
+		Short:   "Propose CPU and memory requests and limits from sampled usage",
+		Long: `Propose CPU and memory requests and limits from sampled usage.
+Usage is polled from the Metrics API every --sample-interval for --window. The samples of
+a container are pooled over all the pods of its workload (Deployment, StatefulSet, ...):
+the proposed request covers the --percentile of the samples and the proposed limit the
+--limit-percentile, both with --headroom percent added and rounded up.
+
+The current and proposed values are printed per container, followed by the projected
+savings per workload: the current requests of its pods minus the proposed ones (negative
+when requests go up). metrics-server refreshes usage about every 15s, so a longer window
+gives better proposals than faster polling. Interrupting the sampling with Ctrl-C proposes
+from the samples taken so far.
+
+Examples:
+  # Sample the workloads of the current namespace for 5 minutes
+  kubectl rltop recommend
+
+  # Sample a deployment for an hour and size requests at p95 with 20% headroom
+  kubectl rltop recommend deployment/NAME --window=1h --percentile=95 --headroom=20
+
+  # Print the proposals as JSON
+  kubectl rltop recommend -A -o json
+
+  # Apply the proposals to a deployment
+  kubectl rltop recommend deployment/NAME -o patch > patch.yaml
+  kubectl patch deployment NAME --patch-file=patch.yaml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(opts.Output, recommendOutputFormats); err != nil {
+				return err
+			}
+			if err := sourceOpts.validate(); err != nil {
+				return err
+			}
+			if opts.Window < 0 {
+				return fmt.Errorf("invalid --window %s, must not be negative", opts.Window)
+			}
+			if opts.SampleInterval <= 0 {
+				return fmt.Errorf("invalid --sample-interval %s, must be greater than zero", opts.SampleInterval)
+			}
+			if err := validateRecommendationPolicy(policy); err != nil {
+				return err
+			}
+
+			var err error
+			opts.CPU, opts.Memory = policy, policy
+			if opts.CPU.Round, err = parseRound("--cpu-round", cpuRound, true); err != nil {
+				return err
+			}
+			if opts.Memory.Round, err = parseRound("--memory-round", memoryRound, false); err != nil {
+				return err
+			}
+
+			// Extract workload names from args
+			if len(args) > 0 {
+				opts.WorkloadNames = args
+			}
+
+			// Get namespace from context if not specified
+			if !allNamespaces && opts.Namespace == "" {
+				opts.Namespace = factory.Namespace()
+			}
+
+			// Handle -A/--all-namespaces flag (must be after namespace detection)
+			if allNamespaces {
+				opts.Namespace = ""
+			}
+
+			clientset, metricsClient, err := factory.Clients()
+			if err != nil {
+				return err
+			}
+			source := sourceOpts.newSource(clientset, metricsClient, pkg.UsageWindow{})
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			return RunRecommend(ctx, clientset, source, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace to query (default: namespace from current context, or 'default')")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false,
+		"If present, list the requested object(s) across all namespaces. "+
+			"Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().StringVarP(&opts.LabelSelector, "selector", "l", "",
+		"Selector (label query) on the pods to sample, supports '=', '==', and '!='.(e.g. -l key1=value1)")
+	cmd.Flags().DurationVar(&opts.Window, "window", defaultRecommendWindow,
+		"How long to sample usage for (e.g. 30m, 2h). 0 takes a single sample.")
+	cmd.Flags().DurationVar(&opts.SampleInterval, "sample-interval", defaultWatchInterval,
+		"Time to wait between two usage samples (e.g. 15s, 1m).")
+	cmd.Flags().Float64Var(&policy.RequestPercentile, "percentile", 90,
+		"Percentile of the usage samples the proposed requests cover.")
+	cmd.Flags().Float64Var(&policy.LimitPercentile, "limit-percentile", 100,
+		"Percentile of the usage samples the proposed limits cover (100 is the peak).")
+	cmd.Flags().Float64Var(&policy.Headroom, "headroom", 15,
+		"Percentage added on top of the sampled usage for requests and limits.")
+	cmd.Flags().StringVar(&cpuRound, "cpu-round", defaultCPURound,
+		"Round proposed CPU values up to a multiple of this quantity, which is also the minimum.")
+	cmd.Flags().StringVar(&memoryRound, "memory-round", defaultMemoryRound,
+		"Round proposed memory values up to a multiple of this quantity, which is also the minimum.")
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"If present, print output without headers.")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "",
+		"Output format. One of: (json, yaml, patch, kubectl). "+
+			"'patch' prints a strategic merge patch of the pod template of each workload, "+
+			"'kubectl' the equivalent 'kubectl set resources' commands.")
+	addMetricsSourceFlags(cmd, &sourceOpts)
+	factory.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+)
+
+// testRecommendPolicy proposes the peak usage as both request and limit, rounded to 10
+var testRecommendPolicy = pkg.RecommendationPolicy{RequestPercentile: 100, LimitPercentile: 100, Round: 10}
+
+// testContainerMetrics returns the metrics of a single-container pod
+func testContainerMetrics(namespace, name string, cpuMilli, memoryBytes int64) pkg.PodMetrics {
+	return pkg.PodMetrics{
+		Namespace:  namespace,
+		Name:       name,
+		Containers: []pkg.ContainerMetrics{{Name: "app", CPUMilli: cpuMilli, MemoryBytes: memoryBytes}},
+	}
+}
+
+// testContainerResources returns the resources of a single-container pod
+func testContainerResources(namespace, name string, cpuRequestMilli, memoryRequestBytes int64) pkg.PodResources {
+	return pkg.PodResources{
+		Namespace: namespace,
+		Name:      name,
+		Containers: []pkg.ContainerResources{
+			{Name: "app", CPURequestMilli: cpuRequestMilli, MemoryRequestBytes: memoryRequestBytes},
+		},
+	}
+}
+
+func TestCombineRecommendations(t *testing.T) {
+	samples := newUsageSamples()
+	// web-old is replaced by web-2 during the window; its samples still count for the deployment
+	samples.add(
+		[]pkg.PodMetrics{
+			testContainerMetrics("default", "web-1", 100, 100<<20),
+			testContainerMetrics("default", "web-old", 300, 200<<20),
+		},
+		[]pkg.PodResources{
+			testContainerResources("default", "web-1", 500, 512<<20),
+			testContainerResources("default", "web-old", 500, 512<<20),
+		},
+	)
+	samples.add(
+		[]pkg.PodMetrics{
+			testContainerMetrics("default", "web-1", 120, 110<<20),
+			testContainerMetrics("default", "web-2", 150, 120<<20),
+			testContainerMetrics("default", "stray", 5, 10<<20),
+		},
+		[]pkg.PodResources{
+			testContainerResources("default", "web-1", 500, 512<<20),
+			testContainerResources("default", "web-2", 500, 512<<20),
+			testContainerResources("default", "stray", 0, 0),
+			testContainerResources("default", "pending", 100, 0),
+		},
+	)
+	workloads := map[string]pkg.Workload{
+		"default/web-1":   {Kind: "Deployment", Name: "web"},
+		"default/web-2":   {Kind: "Deployment", Name: "web"},
+		"default/web-old": {Kind: "Deployment", Name: "web"},
+	}
+
+	combined := combineRecommendations(samples, workloads, testRecommendPolicy, testRecommendPolicy)
+	sortRecommendationData(combined)
+
+	if len(combined) != 2 {
+		t.Fatalf("combineRecommendations() returned %d rows, want 2: %+v", len(combined), combined)
+	}
+
+	web := combined[0]
+	if web.Kind != "Deployment" || web.Workload != "web" || web.Pods != 2 || web.Samples != 4 {
+		t.Errorf("combineRecommendations() web = %+v", web)
+	}
+	if web.Current.CPURequestMilli != 500 || web.Proposed.CPURequestMilli != 300 || web.Proposed.CPULimitMilli != 300 {
+		t.Errorf("combineRecommendations() web CPU = %+v -> %+v, want the peak of all pods", web.Current, web.Proposed)
+	}
+	if cpu, memory := web.savings(); cpu != 400 || memory != 2*(512<<20)-2*(200<<20) {
+		t.Errorf("savings() = %d, %d", cpu, memory)
+	}
+
+	// A pod without requests gets requests, so its savings are negative
+	stray := combined[1]
+	if stray.Kind != pkg.KindPod || stray.Proposed.CPURequestMilli != 10 {
+		t.Errorf("combineRecommendations() pod without workload = %+v", stray)
+	}
+	if cpu, _ := stray.savings(); cpu != -10 {
+		t.Errorf("savings() of a pod without requests = %d, want -10", cpu)
+	}
+
+	if filtered := filterRecommendations(combined, []string{"deployment/web"}); len(filtered) != 1 {
+		t.Errorf("filterRecommendations() = %+v", filtered)
+	}
+}
+
+func TestSumSavings(t *testing.T) {
+	data := []CombinedRecommendationData{
+		{Namespace: "default", Kind: "Deployment", Workload: "web", Container: "app", Pods: 2,
+			Proposed: resourceValues{CPURequestMilli: 100}, totalCPURequestMilli: 1000},
+		{Namespace: "default", Kind: "Deployment", Workload: "web", Container: "proxy", Pods: 2,
+			Proposed: resourceValues{CPURequestMilli: 200}, totalCPURequestMilli: 200},
+	}
+
+	savings := sumSavings(data)
+	if len(savings) != 1 {
+		t.Fatalf("sumSavings() returned %d workloads, want 1", len(savings))
+	}
+	if s := savings[0]; s.Pods != 2 || s.CPUMilli != 600 {
+		t.Errorf("sumSavings() = %+v, want 2 pods and 600m", s)
+	}
+	if got := formatCPUSavings(-1500); got != "-1.50" {
+		t.Errorf("formatCPUSavings() = %s, want -1.50", got)
+	}
+}
+
+func TestSampleUsage(t *testing.T) {
+	var polls int
+	poll := func(context.Context) error {
+		polls++
+		return nil
+	}
+	if err := sampleUsage(context.Background(), 0, time.Second, poll); err != nil || polls != 1 {
+		t.Errorf("sampleUsage() with no window = %v after %d polls, want a single poll", err, polls)
+	}
+
+	polls = 0
+	if err := sampleUsage(context.Background(), 2*time.Millisecond, time.Millisecond, poll); err != nil || polls != 3 {
+		t.Errorf("sampleUsage() = %v after %d polls, want 3 polls", err, polls)
+	}
+
+	failing := func(context.Context) error { return errors.New("forbidden") }
+	if err := sampleUsage(context.Background(), 0, time.Second, failing); err == nil {
+		t.Error("sampleUsage() should fail when the first poll fails")
+	}
+
+	// Interrupted after two polls, the samples taken are kept
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	polls = 0
+	interrupting := func(context.Context) error {
+		polls++
+		if polls == 2 {
+			cancel()
+		}
+		return nil
+	}
+	if err := sampleUsage(ctx, time.Hour, time.Millisecond, interrupting); err != nil || polls != 2 {
+		t.Errorf("sampleUsage() interrupted = %v after %d polls, want no error after 2 polls", err, polls)
+	}
+	if err := sampleUsage(ctx, time.Hour, time.Millisecond, failing); err == nil {
+		t.Error("sampleUsage() should fail when interrupted before any poll succeeded")
+	}
+}
+
+func TestPrintRecommendationData(t *testing.T) {
+	data := []CombinedRecommendationData{
+		{
+			Namespace:               "default",
+			Kind:                    "Deployment",
+			Workload:                "web",
+			Container:               "app",
+			Pods:                    2,
+			Samples:                 40,
+			Current:                 resourceValues{CPURequestMilli: 500, MemoryRequestBytes: 512 << 20},
+			Proposed:                resourceValues{CPURequestMilli: 200, CPULimitMilli: 300, MemoryRequestBytes: 256 << 20},
+			totalCPURequestMilli:    1000,
+			totalMemoryRequestBytes: 1 << 30,
+		},
+	}
+
+	output := captureStdout(t, func() {
+		if err := printRecommendationData(data, RecommendOptions{Namespace: "default"}); err != nil {
+			t.Errorf("printRecommendationData() error = %v", err)
+		}
+	})
+
+	for _, want := range []string{"CONTAINER", "SAMPLES", "500m -> 200m", "- -> 300m", "512.00Mi -> 256.00Mi",
+		"CPU SAVINGS", "600m", "512.00Mi"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("printRecommendationData() output missing %q. Output: %s", want, output)
+		}
+	}
+}
+
+func TestNewRecommendationList(t *testing.T) {
+	data := []CombinedRecommendationData{
+		{
+			Namespace:            "default",
+			Kind:                 "Deployment",
+			Workload:             "web",
+			Container:            "app",
+			Pods:                 1,
+			Samples:              20,
+			Current:              resourceValues{CPURequestMilli: 100},
+			Proposed:             resourceValues{CPURequestMilli: 200, MemoryRequestBytes: 64 << 20},
+			totalCPURequestMilli: 100,
+		},
+	}
+
+	list := newRecommendationList(data)
+	if list.Kind != "RecommendationList" || len(list.Items) != 1 || len(list.Savings) != 1 {
+		t.Fatalf("newRecommendationList() = %+v", list)
+	}
+	item := list.Items[0]
+	if item.Current.MemoryRequest != nil || item.Proposed.MemoryRequest == nil ||
+		item.Proposed.CPURequest.Millicores != 200 {
+		t.Errorf("newRecommendationList() item = %+v", item)
+	}
+	if s := list.Savings[0]; s.CPU.Millicores != -100 || s.CPU.Formatted != "-100m" {
+		t.Errorf("newRecommendationList() savings = %+v, want -100m", s)
+	}
+}
+
+func TestParseRound(t *testing.T) {
+	if got, err := parseRound("--cpu-round", "10m", true); err != nil || got != 10 {
+		t.Errorf("parseRound(10m) = %d, %v, want 10", got, err)
+	}
+	if got, err := parseRound("--memory-round", "16Mi", false); err != nil || got != 16<<20 {
+		t.Errorf("parseRound(16Mi) = %d, %v, want %d", got, err, 16<<20)
+	}
+	if _, err := parseRound("--cpu-round", "0", true); err == nil {
+		t.Error("parseRound(0) should fail")
+	}
+	invalid := pkg.RecommendationPolicy{RequestPercentile: 101, LimitPercentile: 100}
+	if err := validateRecommendationPolicy(invalid); err == nil {
+		t.Error("validateRecommendationPolicy() should reject a percentile above 100")
+	}
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/veditoid/kubectl-rltop/pkg"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RecordOptions holds the options of the record command
+type RecordOptions struct {
+	Namespace     string // Empty means all namespaces
+	LabelSelector string
+	File          string // Recording to append snapshots to, "-" for stdout
+	Interval      time.Duration
+	Count         int  // Number of snapshots to record, 0 until interrupted
+	Nodes         bool // Record nodes too, which needs cluster-wide access
+}
+
+// RunRecord executes the record command: it appends a snapshot of the pods and nodes to the recording
+// every interval until opts.Count snapshots are recorded or ctx is cancelled. A failed poll is reported
+// on stderr and retried at the next interval, like in watch mode.
+func RunRecord(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	opts RecordOptions,
+) (err error) {
+	if err := source.Check(ctx); err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.File != "-" {
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open recording: %w", err)
+		}
+		defer func() {
+			if closeErr := file.Close(); err == nil && closeErr != nil {
+				err = fmt.Errorf("failed to close recording: %w", closeErr)
+			}
+		}()
+		out = file
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for recorded := 0; ; {
+		snapshot, err := captureSnapshot(ctx, clientset, source, opts)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		default:
+			if err := pkg.WriteSnapshot(out, snapshot); err != nil {
+				return err
+			}
+			recorded++
+			fmt.Fprintf(os.Stderr, "Recorded %d pods and %d nodes at %s\n",
+				len(snapshot.PodResources), len(snapshot.Nodes), snapshot.Timestamp.Format(time.RFC3339))
+			if opts.Count > 0 && recorded >= opts.Count {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// captureSnapshot fetches the pod data with the workload of every pod and, with opts.Nodes,
+// the node data the pod and node commands combine
+func captureSnapshot(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	opts RecordOptions,
+) (*pkg.Snapshot, error) {
+	snapshot := &pkg.Snapshot{
+		Timestamp:     time.Now().UTC(),
+		Namespace:     opts.Namespace,
+		LabelSelector: opts.LabelSelector,
+	}
+
+	var err error
+	snapshot.PodMetrics, snapshot.PodResources, err = fetchPodMetricsAndResources(ctx, clientset, source,
+		opts.Namespace, opts.LabelSelector, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Workloads, err = pkg.ResolveWorkloads(ctx, clientset, opts.Namespace, snapshot.PodResources); err != nil {
+		return nil, err
+	}
+	if !opts.Nodes {
+		return snapshot, nil
+	}
+
+	if snapshot.NodeMetrics, err = source.NodeMetrics(ctx, "", nil); err != nil {
+		return nil, err
+	}
+	resources, err := pkg.AggregatePodResourcesByNode(ctx, clientset, false)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := pkg.GetNodeResources(ctx, clientset, "", nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nodes are sorted by name so successive snapshots compare line by line
+	for _, r := range resources {
+		snapshot.NodeResources = append(snapshot.NodeResources, *r)
+	}
+	sort.Slice(snapshot.NodeResources, func(i, j int) bool {
+		return snapshot.NodeResources[i].NodeName < snapshot.NodeResources[j].NodeName
+	})
+	for _, node := range nodes {
+		snapshot.Nodes = append(snapshot.Nodes, pkg.SnapshotNode(node))
+	}
+	sort.Slice(snapshot.Nodes, func(i, j int) bool {
+		return snapshot.Nodes[i].Name < snapshot.Nodes[j].Name
+	})
+
+	return snapshot, nil
+}
+
+// validateRecordOptions returns an error if the recording or the number of snapshots is missing or invalid
+func validateRecordOptions(opts RecordOptions) error {
+	if opts.File == "" {
+		return errors.New("--file is required, use '-' to write to stdout")
+	}
+	if opts.Interval <= 0 {
+		return fmt.Errorf("invalid --interval %s, must be greater than zero", opts.Interval)
+	}
+	if opts.Count < 0 {
+		return fmt.Errorf("invalid --count %d, must not be negative", opts.Count)
+	}
+	return nil
+}
+
+// NewRecordCommand creates the record command
+func NewRecordCommand() *cobra.Command {
+	factory := newClientFactory()
+	var opts RecordOptions
+	var sourceOpts metricsSourceOptions
+	var allNamespaces bool
+
+	cmd := &cobra.Command{
+		Use:   "record -f FILE",
+		Short: "Record snapshots of pod and node usage, requests and limits to a file",
+		Long: `Record snapshots of pod and node usage, requests and limits to a file.
+Every --interval, a timestamped snapshot of what 'rltop pod' and 'rltop node' would show is appended to
+the file as a JSON line, until --count snapshots are recorded or the command is interrupted (Ctrl-C).
+
+Replay a snapshot with 'rltop pod --from-file FILE' or 'rltop node --from-file FILE'.
+
+Examples:
+  # Record every pod and node every 30 seconds until interrupted
+  kubectl rltop record -A -f incident.jsonl --interval=30s
+
+  # Record a single snapshot of a namespace, without nodes
+  kubectl rltop record -n production -f prod.jsonl --count=1 --nodes=false
+
+  # Replay the pods of the last snapshot, highest CPU first
+  kubectl rltop pod --from-file incident.jsonl --sort-by=cpu`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateRecordOptions(opts); err != nil {
+				return err
+			}
+			if err := sourceOpts.validate(); err != nil {
+				return err
+			}
+
+			// Get namespace from context if not specified
+			if !allNamespaces && opts.Namespace == "" {
+				opts.Namespace = factory.Namespace()
+			}
+
+			// Handle -A/--all-namespaces flag (must be after namespace detection)
+			if allNamespaces {
+				opts.Namespace = ""
+			}
+
+			clientset, metricsClient, err := factory.Clients()
+			if err != nil {
+				return err
+			}
+			source := sourceOpts.newSource(clientset, metricsClient, pkg.UsageWindow{})
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			return RunRecord(ctx, clientset, source, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.File, "file", "f", "",
+		"File to append the snapshots to, created if missing. '-' writes to stdout.")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", defaultWatchInterval,
+		"Time to wait between two snapshots (e.g. 15s, 1m).")
+	cmd.Flags().IntVar(&opts.Count, "count", 0,
+		"Number of snapshots to record. 0 records until interrupted.")
+	cmd.Flags().BoolVar(&opts.Nodes, "nodes", true,
+		"Record nodes and their request totals too. Disable without cluster-wide access to nodes and pods.")
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace to record (default: namespace from current context, or 'default')")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false,
+		"If present, record the pods of all namespaces. "+
+			"Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().StringVarP(&opts.LabelSelector, "selector", "l", "",
+		"Selector (label query) on the pods to record, supports '=', '==', and '!='.(e.g. -l key1=value1)")
+	addMetricsSourceFlags(cmd, &sourceOpts)
+	factory.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/veditoid/kubectl-rltop/pkg"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// staticSource is a metrics source serving fixed usage
+type staticSource struct {
+	pods  []pkg.PodMetrics
+	nodes []pkg.NodeMetrics
+}
+
+func (s staticSource) Check(context.Context) error { return nil }
+
+func (s staticSource) PodMetrics(context.Context, string, string, string, []string) ([]pkg.PodMetrics, error) {
+	return s.pods, nil
+}
+
+func (s staticSource) NodeMetrics(context.Context, string, []string) ([]pkg.NodeMetrics, error) {
+	return s.nodes, nil
+}
+
+func TestRunRecordAndReplay(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+				Containers: []corev1.Container{{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	)
+	source := staticSource{
+		pods:  []pkg.PodMetrics{testContainerMetrics("default", "web-1", 100, 64<<20)},
+		nodes: []pkg.NodeMetrics{{Name: "node-1", CPUMilli: 500, MemoryBytes: 1 << 30}},
+	}
+
+	path := filepath.Join(t.TempDir(), "recording.jsonl")
+	opts := RecordOptions{File: path, Interval: time.Millisecond, Count: 2, Nodes: true}
+	if err := RunRecord(context.Background(), clientset, source, opts); err != nil {
+		t.Fatalf("RunRecord() error = %v", err)
+	}
+	recording, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if lines := strings.Count(string(recording), "\n"); lines != 2 {
+		t.Fatalf("RunRecord() recorded %d snapshots, want 2", lines)
+	}
+
+	snapshot, err := readSnapshotFile(path, time.Time{})
+	if err != nil {
+		t.Fatalf("readSnapshotFile() error = %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := RunPodSnapshot(snapshot, PodOptions{}); err != nil {
+			t.Errorf("RunPodSnapshot() error = %v", err)
+		}
+	})
+	if !strings.Contains(out, "NAMESPACE") || !strings.Contains(out, "web-1") || !strings.Contains(out, "250m") {
+		t.Errorf("RunPodSnapshot() output = %q, want web-1 with its request across namespaces", out)
+	}
+
+	out = captureStdout(t, func() {
+		if err := RunNodeSnapshot(snapshot, NodeOptions{Output: outputWide}); err != nil {
+			t.Errorf("RunNodeSnapshot() error = %v", err)
+		}
+	})
+	if !strings.Contains(out, "node-1") || !strings.Contains(out, "Ready") || !strings.Contains(out, "500m") {
+		t.Errorf("RunNodeSnapshot() output = %q, want node-1 with its status and usage", out)
+	}
+
+	// Without --nodes, the node view has nothing to replay
+	withoutNodes := &pkg.Snapshot{Timestamp: snapshot.Timestamp, PodResources: snapshot.PodResources}
+	if err := RunNodeSnapshot(withoutNodes, NodeOptions{}); err == nil || !strings.Contains(err.Error(), "--nodes=false") {
+		t.Errorf("RunNodeSnapshot() without nodes error = %v", err)
+	}
+}
+
+func TestValidateRecordOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    RecordOptions
+		wantErr bool
+	}{
+		{name: "valid", opts: RecordOptions{File: "-", Interval: time.Second}},
+		{name: "missing file", opts: RecordOptions{Interval: time.Second}, wantErr: true},
+		{name: "zero interval", opts: RecordOptions{File: "-"}, wantErr: true},
+		{name: "negative count", opts: RecordOptions{File: "-", Interval: time.Second, Count: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateRecordOptions(tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("validateRecordOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateFromFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		fromFile string
+		at       string
+		wantAt   time.Time
+		wantErr  string
+	}{
+		{name: "live"},
+		{name: "replay", fromFile: "recording.jsonl"},
+		{name: "replay at", fromFile: "recording.jsonl", at: "2024-05-01T12:00:00Z",
+			wantAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{name: "at without replay", at: "2024-05-01T12:00:00Z", wantErr: "--at requires --from-file"},
+		{name: "invalid at", fromFile: "recording.jsonl", at: "noon", wantErr: "invalid --at"},
+		{name: "live flag", args: []string{"--watch"}, fromFile: "recording.jsonl",
+			wantErr: "--watch needs a live cluster"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().Bool("watch", false, "")
+			if err := cmd.Flags().Parse(tt.args); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			at, err := validateFromFile(cmd, tt.fromFile, tt.at, "watch")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("validateFromFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !at.Equal(tt.wantAt) {
+				t.Errorf("validateFromFile() = %v, %v, want %v", at, err, tt.wantAt)
+			}
+		})
+	}
+}
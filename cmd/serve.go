@@ -0,0 +1,393 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/veditoid/kubectl-rltop/pkg"
+	"k8s.io/client-go/kubernetes"
+)
+
+// defaultServeAddress is the address 'rltop serve' listens on by default
+const defaultServeAddress = ":9712"
+
+// serveShutdownTimeout is how long in-flight scrapes may take to finish once the server stops
+const serveShutdownTimeout = 5 * time.Second
+
+// ServeOptions holds the options of the serve command
+type ServeOptions struct {
+	Namespace     string // Empty means all namespaces
+	LabelSelector string
+	Address       string
+	Interval      time.Duration
+	Containers    bool // Expose per-container series too
+	Nodes         bool // Expose per-node series, which needs cluster-wide access
+}
+
+// gauge is a gauge exposed per pod, container or node. value returns false when the row has no
+// such value (no metrics, unset request or limit, ...), which leaves the series out.
+type gauge[T any] struct {
+	name  string
+	help  string
+	value func(T) (float64, bool)
+}
+
+// utilizationRatio returns used divided by total, if there is usage and a total to compare against
+func utilizationRatio(hasMetrics bool, used, total int64) (float64, bool) {
+	percent, ok := usagePercent(hasMetrics, used, total)
+	return percent / 100, ok
+}
+
+// podGauges are the gauges exposed per pod (rltop_pod_...) and per container (rltop_container_...)
+var podGauges = []gauge[CombinedPodData]{
+	{"cpu_usage_cores", "CPU usage in cores.", func(d CombinedPodData) (float64, bool) {
+		return float64(d.CPUUsageMilli) / 1000, d.HasMetrics
+	}},
+	{"cpu_request_cores", "CPU request in cores.", func(d CombinedPodData) (float64, bool) {
+		return float64(d.CPURequestMilli) / 1000, d.CPURequestMilli != 0
+	}},
+	{"cpu_limit_cores", "CPU limit in cores.", func(d CombinedPodData) (float64, bool) {
+		return float64(d.CPULimitMilli) / 1000, d.CPULimitMilli != 0
+	}},
+	{"memory_usage_bytes", "Memory usage (working set) in bytes.", func(d CombinedPodData) (float64, bool) {
+		return float64(d.MemoryUsageBytes), d.HasMetrics
+	}},
+	{"memory_request_bytes", "Memory request in bytes.", func(d CombinedPodData) (float64, bool) {
+		return float64(d.MemoryRequestBytes), d.MemoryRequestBytes != 0
+	}},
+	{"memory_limit_bytes", "Memory limit in bytes.", func(d CombinedPodData) (float64, bool) {
+		return float64(d.MemoryLimitBytes), d.MemoryLimitBytes != 0
+	}},
+	{"cpu_request_utilization_ratio", "CPU usage divided by the CPU request.", func(d CombinedPodData) (float64, bool) {
+		return utilizationRatio(d.HasMetrics, d.CPUUsageMilli, d.CPURequestMilli)
+	}},
+	{"cpu_limit_utilization_ratio", "CPU usage divided by the CPU limit.", func(d CombinedPodData) (float64, bool) {
+		return utilizationRatio(d.HasMetrics, d.CPUUsageMilli, d.CPULimitMilli)
+	}},
+	{"memory_request_utilization_ratio", "Memory usage divided by the memory request.",
+		func(d CombinedPodData) (float64, bool) {
+			return utilizationRatio(d.HasMetrics, d.MemoryUsageBytes, d.MemoryRequestBytes)
+		}},
+	{"memory_limit_utilization_ratio", "Memory usage divided by the memory limit.",
+		func(d CombinedPodData) (float64, bool) {
+			return utilizationRatio(d.HasMetrics, d.MemoryUsageBytes, d.MemoryLimitBytes)
+		}},
+}
+
+// nodeGauges are the gauges exposed per node (rltop_node_...). Requests and limits are the totals
+// of the pods on the node, terminated pods excluded.
+var nodeGauges = []gauge[CombinedNodeData]{
+	{"cpu_usage_cores", "CPU usage in cores.", func(d CombinedNodeData) (float64, bool) {
+		return float64(d.CPUUsageMilli) / 1000, d.HasMetrics
+	}},
+	{"cpu_request_cores", "Total CPU requests of the pods in cores.", func(d CombinedNodeData) (float64, bool) {
+		return float64(d.CPURequestMilli) / 1000, true
+	}},
+	{"cpu_limit_cores", "Total CPU limits of the pods in cores.", func(d CombinedNodeData) (float64, bool) {
+		return float64(d.CPULimitMilli) / 1000, true
+	}},
+	{"cpu_allocatable_cores", "Allocatable CPU in cores.", func(d CombinedNodeData) (float64, bool) {
+		return float64(d.CPUAllocatableMilli) / 1000, d.CPUAllocatableMilli != 0
+	}},
+	{"memory_usage_bytes", "Memory usage (working set) in bytes.", func(d CombinedNodeData) (float64, bool) {
+		return float64(d.MemoryUsageBytes), d.HasMetrics
+	}},
+	{"memory_request_bytes", "Total memory requests of the pods in bytes.", func(d CombinedNodeData) (float64, bool) {
+		return float64(d.MemoryRequestBytes), true
+	}},
+	{"memory_limit_bytes", "Total memory limits of the pods in bytes.", func(d CombinedNodeData) (float64, bool) {
+		return float64(d.MemoryLimitBytes), true
+	}},
+	{"memory_allocatable_bytes", "Allocatable memory in bytes.", func(d CombinedNodeData) (float64, bool) {
+		return float64(d.MemoryAllocatableBytes), d.MemoryAllocatableBytes != 0
+	}},
+	{"cpu_utilization_ratio", "CPU usage divided by the allocatable CPU.", func(d CombinedNodeData) (float64, bool) {
+		return utilizationRatio(d.HasMetrics, d.CPUUsageMilli, d.CPUAllocatableMilli)
+	}},
+	{"memory_utilization_ratio", "Memory usage divided by the allocatable memory.",
+		func(d CombinedNodeData) (float64, bool) {
+			return utilizationRatio(d.HasMetrics, d.MemoryUsageBytes, d.MemoryAllocatableBytes)
+		}},
+}
+
+// exporter refreshes the pod and node data every interval and serves the last data it fetched
+// on /metrics, so scrapes never wait on the API server
+type exporter struct {
+	clientset kubernetes.Interface
+	source    pkg.MetricsSource
+	opts      ServeOptions
+
+	mu          sync.Mutex
+	pods        []CombinedPodData
+	containers  []CombinedPodData
+	nodes       []CombinedNodeData
+	refreshes   int
+	errors      int
+	duration    time.Duration // Of the last refresh
+	lastSuccess time.Time     // Zero until a refresh succeeds
+}
+
+// newExporter creates an exporter; nothing is fetched until refresh is called
+func newExporter(clientset kubernetes.Interface, source pkg.MetricsSource, opts ServeOptions) *exporter {
+	return &exporter{clientset: clientset, source: source, opts: opts}
+}
+
+// refresh fetches the pod and node data. On error the data of the last successful refresh is kept.
+func (e *exporter) refresh(ctx context.Context) error {
+	start := time.Now()
+	pods, containers, nodes, err := e.fetch(ctx)
+	duration := time.Since(start)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.refreshes++
+	e.duration = duration
+	if err != nil {
+		e.errors++
+		return err
+	}
+	e.pods, e.containers, e.nodes = pods, containers, nodes
+	e.lastSuccess = time.Now()
+	return nil
+}
+
+// fetch fetches and combines the pods, their containers with opts.Containers, and the nodes with opts.Nodes
+func (e *exporter) fetch(ctx context.Context) ([]CombinedPodData, []CombinedPodData, []CombinedNodeData, error) {
+	metrics, resources, err := fetchPodMetricsAndResources(ctx, e.clientset, e.source,
+		e.opts.Namespace, e.opts.LabelSelector, "", nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pods := combineMetricsAndResources(metrics, resources)
+	sortByName(pods)
+
+	var containers []CombinedPodData
+	if e.opts.Containers {
+		containers = combineContainerMetricsAndResources(metrics, resources)
+		sortByName(containers)
+	}
+
+	var nodes []CombinedNodeData
+	if e.opts.Nodes {
+		if nodes, err = fetchNodeData(ctx, e.clientset, e.source, NodeOptions{}); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return pods, containers, nodes, nil
+}
+
+// ServeHTTP writes the metrics in OpenMetrics when the scrape accepts it, else in the Prometheus text format
+func (e *exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	exposition := &expositionWriter{openMetrics: acceptsOpenMetrics(r.Header.Get("Accept"))}
+	e.write(exposition)
+
+	contentType := contentTypeText
+	if exposition.openMetrics {
+		contentType = contentTypeOpenMetrics
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(exposition.finish())
+}
+
+// write writes the gauges of the last successful refresh and the refresh counters
+func (e *exporter) write(w *expositionWriter) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, g := range podGauges {
+		writeGauge(w, "rltop_pod_"+g.name, g, e.pods, func(d CombinedPodData) []metricLabel {
+			return []metricLabel{{"namespace", d.Namespace}, {"pod", d.Name}}
+		})
+	}
+	if e.opts.Containers {
+		for _, g := range podGauges {
+			writeGauge(w, "rltop_container_"+g.name, g, e.containers, func(d CombinedPodData) []metricLabel {
+				return []metricLabel{{"namespace", d.Namespace}, {"pod", d.Name}, {"container", d.Container}}
+			})
+		}
+	}
+	if e.opts.Nodes {
+		for _, g := range nodeGauges {
+			writeGauge(w, "rltop_node_"+g.name, g, e.nodes, func(d CombinedNodeData) []metricLabel {
+				return []metricLabel{{"node", d.Name}}
+			})
+		}
+	}
+
+	w.family("rltop_scrape_duration_seconds", metricGauge,
+		"Duration of the last refresh of the pod and node data from the cluster.")
+	w.sample("rltop_scrape_duration_seconds", e.duration.Seconds())
+	w.family("rltop_scrapes_total", metricCounter, "Number of refreshes of the pod and node data.")
+	w.sample("rltop_scrapes_total", float64(e.refreshes))
+	w.family("rltop_scrape_errors_total", metricCounter,
+		"Number of failed refreshes; the data of the last successful refresh is served meanwhile.")
+	w.sample("rltop_scrape_errors_total", float64(e.errors))
+	w.family("rltop_last_successful_scrape_timestamp_seconds", metricGauge,
+		"Unix time of the last successful refresh, 0 before the first one.")
+	var lastSuccess float64
+	if !e.lastSuccess.IsZero() {
+		lastSuccess = float64(e.lastSuccess.UnixNano()) / float64(time.Second)
+	}
+	w.sample("rltop_last_successful_scrape_timestamp_seconds", lastSuccess)
+}
+
+// writeGauge writes a gauge family with a sample per row that has a value
+func writeGauge[T any](w *expositionWriter, name string, g gauge[T], rows []T, labels func(T) []metricLabel) {
+	w.family(name, metricGauge, g.help)
+	for _, d := range rows {
+		if value, ok := g.value(d); ok {
+			w.sample(name, value, labels(d)...)
+		}
+	}
+}
+
+// RunServe executes the serve command: it serves the metrics on opts.Address and refreshes them every
+// interval until ctx is cancelled. A failed refresh is reported on stderr and retried at the next interval.
+func RunServe(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	opts ServeOptions,
+) error {
+	if err := source.Check(ctx); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", opts.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", opts.Address, err)
+	}
+
+	e := newExporter(clientset, source, opts)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	fmt.Fprintf(os.Stderr, "Serving metrics on http://%s/metrics every %s\n", listener.Addr(), opts.Interval)
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.refresh(ctx); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+			defer cancel()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				return fmt.Errorf("failed to stop the metrics server: %w", err)
+			}
+			return nil
+		case err := <-serveErr:
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return fmt.Errorf("metrics server failed: %w", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// validateServeOptions returns an error if the address is missing or the interval is not positive
+func validateServeOptions(opts ServeOptions) error {
+	if opts.Address == "" {
+		return errors.New("--address is required")
+	}
+	if opts.Interval <= 0 {
+		return fmt.Errorf("invalid --interval %s, must be greater than zero", opts.Interval)
+	}
+	return nil
+}
+
+// NewServeCommand creates the serve command
+func NewServeCommand() *cobra.Command {
+	factory := newClientFactory()
+	var opts ServeOptions
+	var sourceOpts metricsSourceOptions
+	var allNamespaces bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Expose pod and node usage, requests and limits as Prometheus metrics",
+		Long: `Expose pod and node usage, requests and limits as Prometheus metrics.
+Every --interval, the data 'rltop pod' and 'rltop node' show is fetched and served on /metrics
+in the Prometheus text format, or in OpenMetrics when the scraper asks for it, until interrupted (Ctrl-C).
+
+Gauges are exposed per pod (rltop_pod_...), per container with --containers (rltop_container_...)
+and per node (rltop_node_...): CPU in cores and memory in bytes for usage, requests and limits, and
+utilization ratios. Unset requests and limits and unknown usage are left out. rltop_scrape_duration_seconds,
+rltop_scrapes_total and rltop_scrape_errors_total describe the refreshes themselves.
+
+Examples:
+  # Serve the metrics of every pod and node on :9712
+  kubectl rltop serve -A
+
+  # Serve per-container metrics of a namespace every 30 seconds on port 8080
+  kubectl rltop serve -n production --containers --interval=30s --address=:8080`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateServeOptions(opts); err != nil {
+				return err
+			}
+			if err := sourceOpts.validate(); err != nil {
+				return err
+			}
+
+			// Get namespace from context if not specified
+			if !allNamespaces && opts.Namespace == "" {
+				opts.Namespace = factory.Namespace()
+			}
+
+			// Handle -A/--all-namespaces flag (must be after namespace detection)
+			if allNamespaces {
+				opts.Namespace = ""
+			}
+
+			clientset, metricsClient, err := factory.Clients()
+			if err != nil {
+				return err
+			}
+			source := sourceOpts.newSource(clientset, metricsClient, pkg.UsageWindow{})
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			return RunServe(ctx, clientset, source, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Address, "address", defaultServeAddress,
+		"Address to serve /metrics on, as host:port (e.g. :9712, 127.0.0.1:9712).")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", defaultWatchInterval,
+		"Time to wait between two refreshes of the metrics (e.g. 15s, 1m).")
+	cmd.Flags().BoolVar(&opts.Containers, "containers", false,
+		"If present, also expose the metrics of every container.")
+	cmd.Flags().BoolVar(&opts.Nodes, "nodes", true,
+		"Expose the metrics of the nodes too. Disable without cluster-wide access to nodes and pods.")
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace to expose (default: namespace from current context, or 'default')")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false,
+		"If present, expose the pods of all namespaces. "+
+			"Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().StringVarP(&opts.LabelSelector, "selector", "l", "",
+		"Selector (label query) on the pods to expose, supports '=', '==', and '!='.(e.g. -l key1=value1)")
+	addMetricsSourceFlags(cmd, &sourceOpts)
+	factory.AddFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// failingSource is a metrics source whose pod and node metrics cannot be fetched
+type failingSource struct{ staticSource }
+
+func (failingSource) PodMetrics(context.Context, string, string, string, []string) ([]pkg.PodMetrics, error) {
+	return nil, errors.New("metrics unavailable")
+}
+
+// scrape returns the content type and body of a /metrics scrape with the Accept header
+func scrape(t *testing.T, e *exporter, accept string) (string, string) {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, request)
+	return recorder.Header().Get("Content-Type"), recorder.Body.String()
+}
+
+func TestExporter(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+				Containers: []corev1.Container{{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("250m"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+			},
+		},
+	)
+	source := staticSource{
+		pods: []pkg.PodMetrics{{
+			Namespace:   "default",
+			Name:        "web-1",
+			CPUMilli:    125,
+			MemoryBytes: 64 << 20,
+			Containers:  []pkg.ContainerMetrics{{Name: "app", CPUMilli: 125, MemoryBytes: 64 << 20}},
+		}},
+		nodes: []pkg.NodeMetrics{{Name: "node-1", CPUMilli: 500, MemoryBytes: 1 << 30}},
+	}
+
+	e := newExporter(clientset, source, ServeOptions{Containers: true, Nodes: true})
+	if err := e.refresh(context.Background()); err != nil {
+		t.Fatalf("refresh() error = %v", err)
+	}
+
+	contentType, body := scrape(t, e, "")
+	if contentType != contentTypeText {
+		t.Errorf("Content-Type = %q, want the Prometheus text format", contentType)
+	}
+	for _, want := range []string{
+		"# TYPE rltop_pod_cpu_usage_cores gauge\n",
+		`rltop_pod_cpu_usage_cores{namespace="default",pod="web-1"} 0.125`,
+		`rltop_pod_cpu_request_cores{namespace="default",pod="web-1"} 0.25`,
+		`rltop_pod_memory_request_bytes{namespace="default",pod="web-1"} 1.34217728e+08`,
+		`rltop_pod_cpu_request_utilization_ratio{namespace="default",pod="web-1"} 0.5`,
+		`rltop_pod_memory_request_utilization_ratio{namespace="default",pod="web-1"} 0.5`,
+		`rltop_container_cpu_usage_cores{namespace="default",pod="web-1",container="app"} 0.125`,
+		`rltop_node_cpu_utilization_ratio{node="node-1"} 0.25`,
+		`rltop_node_cpu_request_cores{node="node-1"} 0.25`,
+		"# TYPE rltop_scrapes_total counter\nrltop_scrapes_total 1\n",
+		"rltop_scrape_errors_total 0\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("scrape is missing %q:\n%s", want, body)
+		}
+	}
+	// Unset limits have no series
+	if strings.Contains(body, "rltop_pod_cpu_limit_cores{") || strings.Contains(body, "# EOF") {
+		t.Errorf("scrape should leave unset limits out and have no EOF marker:\n%s", body)
+	}
+
+	contentType, body = scrape(t, e, "application/openmetrics-text;version=1.0.0,text/plain;q=0.5")
+	if contentType != contentTypeOpenMetrics || !strings.HasSuffix(body, "# EOF\n") {
+		t.Errorf("OpenMetrics scrape = %q, ends with %q", contentType, body[len(body)-min(len(body), 20):])
+	}
+	if !strings.Contains(body, "# TYPE rltop_scrapes counter\nrltop_scrapes_total 1\n") {
+		t.Errorf("OpenMetrics counters should be declared without _total:\n%s", body)
+	}
+}
+
+func TestExporterRefreshError(t *testing.T) {
+	e := newExporter(fake.NewSimpleClientset(), failingSource{}, ServeOptions{})
+	e.pods = []CombinedPodData{{Namespace: "default", Name: "web-1", CPURequestMilli: 100}}
+
+	if err := e.refresh(context.Background()); err == nil {
+		t.Fatal("refresh() should fail when the metrics cannot be fetched")
+	}
+	_, body := scrape(t, e, "")
+	if !strings.Contains(body, "rltop_scrape_errors_total 1\n") ||
+		!strings.Contains(body, "rltop_last_successful_scrape_timestamp_seconds 0\n") {
+		t.Errorf("scrape should count the failed refresh:\n%s", body)
+	}
+	if !strings.Contains(body, `rltop_pod_cpu_request_cores{namespace="default",pod="web-1"} 0.1`) {
+		t.Errorf("scrape should keep serving the last data after a failed refresh:\n%s", body)
+	}
+	if strings.Contains(body, "rltop_node_") || strings.Contains(body, "rltop_container_") {
+		t.Errorf("scrape should only have node and container series when enabled:\n%s", body)
+	}
+}
+
+func TestExpositionWriter(t *testing.T) {
+	w := &expositionWriter{}
+	w.family("test_info", metricGauge, "Help with a \\ backslash\nand a line feed.")
+	w.sample("test_info", 1, metricLabel{"value", "quote \" backslash \\ line\nfeed"})
+
+	want := "# HELP test_info Help with a \\\\ backslash\\nand a line feed.\n" +
+		"# TYPE test_info gauge\n" +
+		"test_info{value=\"quote \\\" backslash \\\\ line\\nfeed\"} 1\n"
+	if got := string(w.finish()); got != want {
+		t.Errorf("exposition = %q, want %q", got, want)
+	}
+}
+
+func TestValidateServeOptions(t *testing.T) {
+	if err := validateServeOptions(ServeOptions{Address: ":9712", Interval: time.Second}); err != nil {
+		t.Errorf("validateServeOptions() error = %v", err)
+	}
+	if err := validateServeOptions(ServeOptions{Interval: time.Second}); err == nil {
+		t.Error("validateServeOptions() without an address should fail")
+	}
+	if err := validateServeOptions(ServeOptions{Address: ":9712"}); err == nil {
+		t.Error("validateServeOptions() without an interval should fail")
+	}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/veditoid/kubectl-rltop/pkg"
+)
+
+// addFromFileFlags adds the --from-file and --at flags replaying a recorded snapshot
+func addFromFileFlags(cmd *cobra.Command, fromFile, at *string) {
+	cmd.Flags().StringVar(fromFile, "from-file", "",
+		"If non-empty, read a snapshot recorded with 'rltop record' from this file ('-' for stdin) "+
+			"instead of the cluster.")
+	cmd.Flags().StringVar(at, "at", "",
+		"With --from-file, replay the last snapshot taken at or before this RFC 3339 time "+
+			"(e.g. 2024-05-01T12:00:00Z) instead of the last one.")
+}
+
+// validateFromFile returns an error if --at is given without --from-file, or if one of the liveFlags,
+// which need a live cluster, is given with --from-file. It returns the parsed --at time, zero without it.
+func validateFromFile(cmd *cobra.Command, fromFile, at string, liveFlags ...string) (time.Time, error) {
+	if fromFile == "" {
+		if at != "" {
+			return time.Time{}, errors.New("--at requires --from-file")
+		}
+		return time.Time{}, nil
+	}
+
+	for _, name := range liveFlags {
+		if cmd.Flags().Changed(name) {
+			return time.Time{}, fmt.Errorf("--%s needs a live cluster and cannot be used with --from-file", name)
+		}
+	}
+	return parseSnapshotTime("--at", at)
+}
+
+// parseSnapshotTime parses the RFC 3339 time given to a flag selecting a snapshot, zero when empty
+func parseSnapshotTime(flag, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q, must be an RFC 3339 time such as 2024-05-01T12:00:00Z",
+			flag, value)
+	}
+	return t, nil
+}
+
+// readSnapshotFile reads the snapshot taken at or before at (the last one when zero) from a recording,
+// "-" reading it from stdin
+func readSnapshotFile(path string, at time.Time) (*pkg.Snapshot, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open recording: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	snapshot, err := pkg.ReadSnapshot(in, at)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+// RunPodSnapshot executes the pod command on a recorded snapshot instead of the cluster
+func RunPodSnapshot(snapshot *pkg.Snapshot, opts PodOptions) error {
+	metrics, resources := snapshot.Pods(opts.Namespace, opts.PodNames)
+	combined := combinePodData(metrics, resources, opts.Containers)
+	sortCombinedData(combined, opts.SortBy)
+	return printPodData(combined, opts)
+}
+
+// RunNodeSnapshot executes the node command on a recorded snapshot instead of the cluster
+func RunNodeSnapshot(snapshot *pkg.Snapshot, opts NodeOptions) error {
+	if err := checkSnapshotNodes(snapshot); err != nil {
+		return err
+	}
+
+	metrics, resources, nodes := snapshot.NodeData(opts.NodeNames)
+	combined := combineNodeMetricsAndResources(metrics, resources, nodes, opts.ShowCapacity)
+	sortNodeData(combined, opts.SortBy)
+	return printNodeData(combined, opts)
+}
+
+// checkSnapshotNodes returns an error if the snapshot was recorded without nodes
+func checkSnapshotNodes(snapshot *pkg.Snapshot) error {
+	if len(snapshot.Nodes) == 0 && len(snapshot.NodeMetrics) == 0 {
+		return fmt.Errorf("the snapshot of %s has no nodes, they were recorded with --nodes=false",
+			snapshot.Timestamp.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+)
+
+// Sort direction modifiers accepted after a --sort-by field, e.g. 'cpu-util:asc'
+const (
+	sortAscending  = "asc"
+	sortDescending = "desc"
+)
+
+// sortValue returns the numeric value an entry is sorted by, and false when the entry has no
+// such value (no metrics, no request to compare against, ...). Entries without a value always
+// sort last, whatever the direction.
+type sortValue[T any] func(T) (float64, bool)
+
+// podSortValues maps the numeric --sort-by fields of the pod command to their values
+var podSortValues = map[string]sortValue[CombinedPodData]{
+	"cpu": func(d CombinedPodData) (float64, bool) {
+		return float64(d.CPUUsageMilli), d.HasMetrics
+	},
+	"memory": func(d CombinedPodData) (float64, bool) {
+		return float64(d.MemoryUsageBytes), d.HasMetrics
+	},
+	"cpu-request": func(d CombinedPodData) (float64, bool) {
+		return float64(d.CPURequestMilli), d.CPURequestMilli != 0
+	},
+	"cpu-limit": func(d CombinedPodData) (float64, bool) {
+		return float64(d.CPULimitMilli), d.CPULimitMilli != 0
+	},
+	"memory-request": func(d CombinedPodData) (float64, bool) {
+		return float64(d.MemoryRequestBytes), d.MemoryRequestBytes != 0
+	},
+	"memory-limit": func(d CombinedPodData) (float64, bool) {
+		return float64(d.MemoryLimitBytes), d.MemoryLimitBytes != 0
+	},
+	"cpu-util": func(d CombinedPodData) (float64, bool) {
+		return usagePercent(d.HasMetrics, d.CPUUsageMilli, d.CPURequestMilli)
+	},
+	"memory-util": func(d CombinedPodData) (float64, bool) {
+		return usagePercent(d.HasMetrics, d.MemoryUsageBytes, d.MemoryRequestBytes)
+	},
+	"cpu-headroom": func(d CombinedPodData) (float64, bool) {
+		return float64(d.CPURequestMilli - d.CPUUsageMilli), d.HasMetrics && d.CPURequestMilli != 0
+	},
+	"memory-headroom": func(d CombinedPodData) (float64, bool) {
+		return float64(d.MemoryRequestBytes - d.MemoryUsageBytes), d.HasMetrics && d.MemoryRequestBytes != 0
+	},
+}
+
+// nodeSortValues maps the numeric --sort-by fields of the node command to their values.
+// Utilization and headroom are computed against allocatable (or capacity with --show-capacity).
+var nodeSortValues = map[string]sortValue[CombinedNodeData]{
+	"cpu": func(d CombinedNodeData) (float64, bool) {
+		return float64(d.CPUUsageMilli), d.HasMetrics
+	},
+	"memory": func(d CombinedNodeData) (float64, bool) {
+		return float64(d.MemoryUsageBytes), d.HasMetrics
+	},
+	"cpu-request": func(d CombinedNodeData) (float64, bool) {
+		return float64(d.CPURequestMilli), true
+	},
+	"cpu-limit": func(d CombinedNodeData) (float64, bool) {
+		return float64(d.CPULimitMilli), true
+	},
+	"memory-request": func(d CombinedNodeData) (float64, bool) {
+		return float64(d.MemoryRequestBytes), true
+	},
+	"memory-limit": func(d CombinedNodeData) (float64, bool) {
+		return float64(d.MemoryLimitBytes), true
+	},
+	"cpu-util": func(d CombinedNodeData) (float64, bool) {
+		return usagePercent(d.HasMetrics, d.CPUUsageMilli, d.CPUAllocatableMilli)
+	},
+	"memory-util": func(d CombinedNodeData) (float64, bool) {
+		return usagePercent(d.HasMetrics, d.MemoryUsageBytes, d.MemoryAllocatableBytes)
+	},
+	"cpu-headroom": func(d CombinedNodeData) (float64, bool) {
+		return float64(d.CPUAllocatableMilli - d.CPUUsageMilli), d.HasMetrics && d.CPUAllocatableMilli != 0
+	},
+	"memory-headroom": func(d CombinedNodeData) (float64, bool) {
+		return float64(d.MemoryAllocatableBytes - d.MemoryUsageBytes), d.HasMetrics && d.MemoryAllocatableBytes != 0
+	},
+}
+
+// workloadSortValues maps the numeric --sort-by fields of the workload command to their values
+var workloadSortValues = groupSortValues(func(d CombinedWorkloadData) podGroup { return d.podGroup })
+
+// namespaceSortValues maps the numeric --sort-by fields of the namespace command to their values
+var namespaceSortValues = groupSortValues(func(d CombinedNamespaceData) podGroup { return d.podGroup })
+
+// quotaSortValues maps the numeric --sort-by fields of the quota command to their values
+var quotaSortValues = map[string]sortValue[CombinedQuotaData]{
+	"left": func(d CombinedQuotaData) (float64, bool) {
+		return pkg.UtilizationPercent(d.left(), d.Hard)
+	},
+	"used": func(d CombinedQuotaData) (float64, bool) {
+		return pkg.UtilizationPercent(d.Used, d.Hard)
+	},
+}
+
+// Fields accepted by --sort-by, in the order they are listed in help and error messages
+var (
+	podSortFields = []string{
+		"cpu", "memory", "cpu-request", "cpu-limit", "memory-request", "memory-limit",
+		"cpu-util", "memory-util", "cpu-headroom", "memory-headroom", "namespace", "name",
+	}
+	workloadSortFields = []string{
+		"cpu", "memory", "cpu-request", "cpu-limit", "memory-request", "memory-limit",
+		"cpu-util", "memory-util", "cpu-headroom", "memory-headroom", "pods", "namespace", "name",
+	}
+	namespaceSortFields = []string{
+		"cpu", "memory", "cpu-request", "cpu-limit", "memory-request", "memory-limit",
+		"cpu-util", "memory-util", "cpu-headroom", "memory-headroom", "pods", "name",
+	}
+	quotaSortFields = []string{"left", "used", "namespace"}
+	nodeSortFields  = []string{
+		"cpu", "memory", "cpu-request", "cpu-limit", "memory-request", "memory-limit",
+		"cpu-util", "memory-util", "cpu-headroom", "memory-headroom", "name",
+	}
+	diffSortFields = []string{
+		"cpu", "memory", "cpu-request", "cpu-limit", "memory-request", "memory-limit", "change", "namespace", "name",
+	}
+)
+
+// parseSortBy splits a --sort-by value into its field and direction.
+// Numeric fields sort in descending order by default, names in ascending order.
+func parseSortBy(sortBy string) (field string, descending bool) {
+	field, direction, _ := strings.Cut(sortBy, ":")
+	switch direction {
+	case sortAscending:
+		return field, false
+	case sortDescending:
+		return field, true
+	default:
+		return field, field != "name" && field != "namespace"
+	}
+}
+
+// validateSortBy returns an error if the --sort-by value is not one of the supported fields,
+// optionally followed by ':asc' or ':desc'
+func validateSortBy(sortBy string, supported []string) error {
+	if sortBy == "" {
+		return nil
+	}
+	field, direction, hasDirection := strings.Cut(sortBy, ":")
+	if hasDirection && direction != sortAscending && direction != sortDescending {
+		return fmt.Errorf("invalid --sort-by direction %q, must be one of: %s, %s", direction, sortAscending, sortDescending)
+	}
+	for _, f := range supported {
+		if field == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported --sort-by field %q, must be one of: %s", field, strings.Join(supported, ", "))
+}
+
+// sortByValue stable-sorts data by value, keeping the existing order for ties.
+// Entries without a value are moved to the end in both directions.
+func sortByValue[T any](data []T, value sortValue[T], descending bool) {
+	sort.SliceStable(data, func(i, j int) bool {
+		vi, okI := value(data[i])
+		vj, okJ := value(data[j])
+		if !okI || !okJ {
+			return okI && !okJ
+		}
+		if descending {
+			return vi > vj
+		}
+		return vi < vj
+	})
+}
+
+// usagePercent returns usage as a percentage of total, if there is usage to compare
+func usagePercent(hasMetrics bool, used, total int64) (float64, bool) {
+	if !hasMetrics {
+		return 0, false
+	}
+	return pkg.UtilizationPercent(used, total)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestValidateSortBy(t *testing.T) {
+	tests := []struct {
+		sortBy  string
+		wantErr bool
+	}{
+		{"", false},
+		{"cpu", false},
+		{"cpu-util:asc", false},
+		{"memory-headroom:desc", false},
+		{"namespace", false},
+		{"cpu:up", true},
+		{"disk", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			err := validateSortBy(tt.sortBy, podSortFields)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSortBy(%q) error = %v, wantErr %v", tt.sortBy, err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := validateSortBy("namespace", nodeSortFields); err == nil {
+		t.Error("validateSortBy() accepted 'namespace' for nodes")
+	}
+}
+
+func TestSortCombinedData(t *testing.T) {
+	data := []CombinedPodData{
+		// Over-requested: uses 10% of its request
+		{Namespace: "b", Name: "idle", HasMetrics: true, CPUUsageMilli: 100, CPURequestMilli: 1000},
+		// Under-requested: uses twice its request, in whole cores
+		{Namespace: "a", Name: "busy", HasMetrics: true, CPUUsageMilli: 2000, CPURequestMilli: 1000},
+		// No request to compare against
+		{Namespace: "a", Name: "besteffort", HasMetrics: true, CPUUsageMilli: 50},
+		// No metrics yet
+		{Namespace: "c", Name: "pending", CPURequestMilli: 500},
+	}
+
+	tests := []struct {
+		sortBy string
+		want   []string
+	}{
+		{"", []string{"besteffort", "busy", "idle", "pending"}},
+		{"cpu", []string{"busy", "idle", "besteffort", "pending"}},
+		{"cpu:asc", []string{"besteffort", "idle", "busy", "pending"}},
+		{"cpu-request", []string{"busy", "idle", "pending", "besteffort"}},
+		{"cpu-util", []string{"busy", "idle", "besteffort", "pending"}},
+		{"cpu-util:asc", []string{"idle", "busy", "besteffort", "pending"}},
+		{"cpu-headroom", []string{"idle", "busy", "besteffort", "pending"}},
+		{"namespace:desc", []string{"pending", "idle", "besteffort", "busy"}},
+		{"name", []string{"besteffort", "busy", "idle", "pending"}},
+		{"name:desc", []string{"pending", "idle", "busy", "besteffort"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			sorted := make([]CombinedPodData, len(data))
+			copy(sorted, data)
+			sortCombinedData(sorted, tt.sortBy)
+
+			for i, want := range tt.want {
+				if sorted[i].Name != want {
+					t.Errorf("sortCombinedData(%q)[%d] = %s, want %s", tt.sortBy, i, sorted[i].Name, want)
+				}
+			}
+		})
+	}
+}
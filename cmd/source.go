@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/veditoid/kubectl-rltop/pkg"
+	"k8s.io/client-go/kubernetes"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// Metrics sources selectable with --metrics-source
+const (
+	metricsSourceAPI        = "metrics-api"
+	metricsSourcePrometheus = "prometheus"
+)
+
+// metricsSources are the supported values of --metrics-source
+var metricsSources = []string{metricsSourceAPI, metricsSourcePrometheus}
+
+// defaultUsageStat is the statistic usage is aggregated with over --window
+const defaultUsageStat = "p95"
+
+// metricsSourceOptions holds the flags choosing where usage is read from
+type metricsSourceOptions struct {
+	Source        string
+	PrometheusURL string
+}
+
+// addMetricsSourceFlags adds the --metrics-source and --prometheus-url flags to cmd
+func addMetricsSourceFlags(cmd *cobra.Command, opts *metricsSourceOptions) {
+	cmd.Flags().StringVar(&opts.Source, "metrics-source", metricsSourceAPI,
+		"Where to read usage from. One of: "+strings.Join(metricsSources, ", ")+". "+
+			"'prometheus' queries the cAdvisor metrics of a Prometheus server given with --prometheus-url.")
+	cmd.Flags().StringVar(&opts.PrometheusURL, "prometheus-url", "",
+		"Base URL of the Prometheus HTTP API used with --metrics-source=prometheus (e.g. http://localhost:9090).")
+}
+
+// validate returns an error if the source is unknown or Prometheus is chosen without a valid URL
+func (o metricsSourceOptions) validate() error {
+	switch o.Source {
+	case metricsSourceAPI:
+		return nil
+	case metricsSourcePrometheus:
+		if o.PrometheusURL == "" {
+			return errors.New("--metrics-source=prometheus requires --prometheus-url")
+		}
+		u, err := url.Parse(o.PrometheusURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid --prometheus-url %q, must be an http or https URL", o.PrometheusURL)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported metrics source %q, must be one of: %s",
+			o.Source, strings.Join(metricsSources, ", "))
+	}
+}
+
+// newSource creates the metrics source chosen by the flags, which must be valid.
+// A non-zero usage window is only supported by Prometheus, see parseUsageWindow.
+func (o metricsSourceOptions) newSource(
+	clientset kubernetes.Interface,
+	metricsClient metricsclientset.Interface,
+	usage pkg.UsageWindow,
+) pkg.MetricsSource {
+	if o.Source == metricsSourcePrometheus {
+		return pkg.NewPrometheusSource(clientset, o.PrometheusURL, nil).WithUsageWindow(usage)
+	}
+	return pkg.NewMetricsAPISource(clientset, metricsClient)
+}
+
+// addUsageWindowFlags adds the --window and --stat flags aggregating usage over a window
+func addUsageWindowFlags(cmd *cobra.Command, window *time.Duration, stat *string) {
+	cmd.Flags().DurationVar(window, "window", 0,
+		"If non-zero, show usage aggregated over this window (e.g. 1h, 24h, 168h) instead of the current usage. "+
+			"Requires --metrics-source=prometheus.")
+	cmd.Flags().StringVar(stat, "stat", defaultUsageStat,
+		"Statistic aggregating usage over --window. One of: avg, max, or a percentile such as p95.")
+}
+
+// parseUsageWindow returns the usage window of the --window and --stat flags, which needs a Prometheus source.
+// statSet reports whether --stat was given, which is an error without --window.
+func parseUsageWindow(
+	window time.Duration,
+	stat string,
+	statSet bool,
+	source metricsSourceOptions,
+) (pkg.UsageWindow, error) {
+	if window == 0 {
+		if statSet {
+			return pkg.UsageWindow{}, errors.New("--stat requires --window")
+		}
+		return pkg.UsageWindow{}, nil
+	}
+	if window < time.Minute {
+		return pkg.UsageWindow{}, fmt.Errorf("invalid --window %s, must be at least 1m", window)
+	}
+	if source.Source != metricsSourcePrometheus {
+		return pkg.UsageWindow{}, errors.New("--window requires --metrics-source=prometheus, " +
+			"the Metrics API only serves the current usage")
+	}
+
+	usageStat, err := pkg.ParseUsageStat(stat)
+	if err != nil {
+		return pkg.UsageWindow{}, fmt.Errorf("invalid --stat: %w", err)
+	}
+	return pkg.UsageWindow{Window: window, Stat: usageStat}, nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+)
+
+func TestMetricsSourceOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    metricsSourceOptions
+		wantErr bool
+	}{
+		{name: "metrics API", opts: metricsSourceOptions{Source: metricsSourceAPI}},
+		{name: "prometheus", opts: metricsSourceOptions{Source: metricsSourcePrometheus,
+			PrometheusURL: "http://prometheus.monitoring:9090"}},
+		{name: "prometheus without URL", opts: metricsSourceOptions{Source: metricsSourcePrometheus}, wantErr: true},
+		{name: "prometheus without scheme", opts: metricsSourceOptions{Source: metricsSourcePrometheus,
+			PrometheusURL: "localhost:9090"}, wantErr: true},
+		{name: "unknown source", opts: metricsSourceOptions{Source: "influxdb"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	prometheus := metricsSourceOptions{Source: metricsSourcePrometheus, PrometheusURL: "http://localhost:9090"}
+	if _, ok := prometheus.newSource(nil, nil, pkg.UsageWindow{}).(*pkg.PrometheusSource); !ok {
+		t.Error("newSource() should create a Prometheus source for --metrics-source=prometheus")
+	}
+	metricsAPI := metricsSourceOptions{Source: metricsSourceAPI}
+	if _, ok := metricsAPI.newSource(nil, nil, pkg.UsageWindow{}).(*pkg.MetricsAPISource); !ok {
+		t.Error("newSource() should create a Metrics API source by default")
+	}
+}
+
+func TestParseUsageWindow(t *testing.T) {
+	prometheus := metricsSourceOptions{Source: metricsSourcePrometheus, PrometheusURL: "http://localhost:9090"}
+	metricsAPI := metricsSourceOptions{Source: metricsSourceAPI}
+
+	usage, err := parseUsageWindow(24*time.Hour, "max", true, prometheus)
+	if err != nil || usage.Window != 24*time.Hour || usage.Stat != pkg.UsageStatMax {
+		t.Errorf("parseUsageWindow() = %+v, %v, want the max over 24h", usage, err)
+	}
+	if usage, err := parseUsageWindow(0, defaultUsageStat, false, metricsAPI); err != nil || !usage.IsZero() {
+		t.Errorf("parseUsageWindow() without --window = %+v, %v, want the current usage", usage, err)
+	}
+
+	for name, call := range map[string]func() error{
+		"window with the Metrics API": func() error {
+			_, err := parseUsageWindow(time.Hour, defaultUsageStat, false, metricsAPI)
+			return err
+		},
+		"stat without window": func() error {
+			_, err := parseUsageWindow(0, "avg", true, prometheus)
+			return err
+		},
+		"window below a minute": func() error {
+			_, err := parseUsageWindow(30*time.Second, defaultUsageStat, false, prometheus)
+			return err
+		},
+		"unknown stat": func() error {
+			_, err := parseUsageWindow(time.Hour, "median", true, prometheus)
+			return err
+		},
+	} {
+		if call() == nil {
+			t.Errorf("parseUsageWindow() with %s should fail", name)
+		}
+	}
+}
+
+func TestUsageHeaders(t *testing.T) {
+	usage := pkg.UsageWindow{Window: time.Hour, Stat: "p95"}
+	output := captureStdout(t, func() {
+		printTable([]CombinedPodData{{Namespace: "default", Name: "web-1"}}, podTableOptions{Usage: usage})
+	})
+	if !strings.Contains(output, "CPU(p95)") || !strings.Contains(output, "MEMORY(p95)") {
+		t.Errorf("printTable() over a window should name the statistic. Output: %s", output)
+	}
+
+	if a := newUsageAggregation(usage); a == nil || a.Stat != "p95" || a.Window != "1h0m0s" {
+		t.Errorf("newUsageAggregation() = %+v", a)
+	}
+	if a := newUsageAggregation(pkg.UsageWindow{}); a != nil {
+		t.Errorf("newUsageAggregation() of the current usage = %+v, want nil", a)
+	}
+}
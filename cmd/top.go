@@ -6,47 +6,130 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/veditoid/kubectl-rltop/pkg"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
-	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 // CombinedPodData represents combined metrics and resources for a pod
 type CombinedPodData struct {
-	Name          string
-	CPUUsage      string
-	CPURequest    string
-	CPULimit      string
-	MemoryUsage   string
-	MemoryRequest string
-	MemoryLimit   string
+	Namespace          string
+	Name               string
+	Container          string   // Set only when listing per-container usage
+	Resize             string   // In-place resize state, empty when the spec resources are in force
+	LimitRangeDefaults []string // Requests and limits a LimitRange defaulted at admission (requests.cpu, ...)
+	Terminated         bool     // The pod reached a terminal phase (Succeeded or Failed)
+
+	// Recommendation of the VerticalPodAutoscaler targeting the pod's workload, set per container with --vpa
+	VPA *pkg.VPARecommendation
+
+	// CPU in millicores and memory in bytes, formatted only when printed.
+	// Zero requests and limits mean unset; usage is only meaningful when HasMetrics is set.
+	HasMetrics         bool
+	CPUUsageMilli      int64
+	CPURequestMilli    int64
+	CPULimitMilli      int64
+	MemoryUsageBytes   int64
+	MemoryRequestBytes int64
+	MemoryLimitBytes   int64
 }
 
-// RunPod executes the pod command
+// PodOptions holds the options of the pod command
+type PodOptions struct {
+	Namespace     string // Empty means all namespaces
+	LabelSelector string
+	FieldSelector string
+	PodNames      []string
+	SortBy        string
+	NoHeaders     bool
+	Containers    bool
+	VPA           bool            // Join VerticalPodAutoscaler recommendations, implies Containers
+	Usage         pkg.UsageWindow // Aggregate usage over a window (--window, --stat); zero for the current usage
+	Output        string
+	Watch         bool
+	Interval      time.Duration
+}
+
+// RunPod executes the pod command. dynamicClient is only used with opts.VPA and may be nil otherwise.
 func RunPod(
 	ctx context.Context,
 	clientset kubernetes.Interface,
-	metricsClient metricsclientset.Interface,
-	namespace, labelSelector, fieldSelector string,
-	podNames []string,
-	sortBy string,
-	noHeaders bool,
+	source pkg.MetricsSource,
+	dynamicClient dynamic.Interface,
+	opts PodOptions,
 ) error {
-	// Check if Metrics API is available
-	if err := pkg.CheckMetricsAPIAvailable(ctx, clientset); err != nil {
-		return fmt.Errorf("metrics API not available: %w\nPlease ensure metrics-server is installed in your cluster", err)
+	// Check the metrics source is available (only once, also in watch mode)
+	if err := source.Check(ctx); err != nil {
+		return err
+	}
+
+	refresh := func(ctx context.Context) error {
+		combined, err := fetchPodData(ctx, clientset, source, dynamicClient, opts)
+		if err != nil {
+			return err
+		}
+		return printPodData(combined, opts)
+	}
+
+	if opts.Watch {
+		return runWatch(ctx, opts.Interval, opts.Output, refresh)
+	}
+	return refresh(ctx)
+}
+
+// fetchPodData fetches pod metrics and resources in parallel and returns them combined and sorted
+func fetchPodData(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	dynamicClient dynamic.Interface,
+	opts PodOptions,
+) ([]CombinedPodData, error) {
+	metrics, resources, err := fetchPodMetricsAndResources(ctx, clientset, source,
+		opts.Namespace, opts.LabelSelector, opts.FieldSelector, opts.PodNames)
+	if err != nil {
+		return nil, err
+	}
+
+	combined := combinePodData(metrics, resources, opts.Containers)
+
+	if opts.VPA {
+		if err := addVPARecommendations(ctx, clientset, dynamicClient, opts.Namespace, resources, combined); err != nil {
+			return nil, err
+		}
 	}
 
-	// Fetch metrics and resources in parallel
+	// Sort based on sortBy parameter (default: by namespace, then pod name)
+	sortCombinedData(combined, opts.SortBy)
+
+	return combined, nil
+}
+
+// combinePodData combines metrics and resources, either per pod or per container
+func combinePodData(metrics []pkg.PodMetrics, resources []pkg.PodResources, containers bool) []CombinedPodData {
+	if containers {
+		return combineContainerMetricsAndResources(metrics, resources)
+	}
+	return combineMetricsAndResources(metrics, resources)
+}
+
+// fetchPodMetricsAndResources fetches pod metrics and pod resources in parallel
+func fetchPodMetricsAndResources(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	namespace, labelSelector, fieldSelector string,
+	podNames []string,
+) ([]pkg.PodMetrics, []pkg.PodResources, error) {
 	metricsChan := make(chan []pkg.PodMetrics, 1)
 	resourcesChan := make(chan []pkg.PodResources, 1)
 	errChan := make(chan error, 2)
 
 	go func() {
-		metrics, err := pkg.GetPodMetrics(ctx, metricsClient, namespace, labelSelector, fieldSelector, podNames)
+		metrics, err := source.PodMetrics(ctx, namespace, labelSelector, fieldSelector, podNames)
 		if err != nil {
 			errChan <- err
 			return
@@ -70,32 +153,37 @@ func RunPod(
 	for i := 0; i < 2; i++ {
 		select {
 		case err := <-errChan:
-			return err
+			return nil, nil, err
 		case metrics = <-metricsChan:
 		case resources = <-resourcesChan:
 		}
 	}
 
-	// Combine metrics and resources
-	combined := combineMetricsAndResources(metrics, resources)
+	return metrics, resources, nil
+}
+
+// printPodData prints the combined pod data in the requested output format
+func printPodData(combined []CombinedPodData, opts PodOptions) error {
+	if isStructuredOutput(opts.Output) {
+		list := newPodUsageList(combined)
+		list.Aggregation = newUsageAggregation(opts.Usage)
+		return printStructured(list, opts.Output)
+	}
 
 	if len(combined) == 0 {
 		fmt.Fprintf(os.Stderr, "No pods found\n")
 		return nil
 	}
 
-	// Sort based on sortBy parameter
-	if sortBy != "" {
-		sortCombinedData(combined, sortBy)
-	} else {
-		// Default: sort by pod name
-		sort.Slice(combined, func(i, j int) bool {
-			return combined[i].Name < combined[j].Name
-		})
-	}
-
-	// Print table
-	printTable(combined, noHeaders)
+	// Print table, with a NAMESPACE column when listing across all namespaces
+	printTable(combined, podTableOptions{
+		NoHeaders:     opts.NoHeaders,
+		Containers:    opts.Containers,
+		ShowNamespace: opts.Namespace == "",
+		Wide:          opts.Output == outputWide,
+		VPA:           opts.VPA,
+		Usage:         opts.Usage,
+	})
 
 	return nil
 }
@@ -126,37 +214,21 @@ func combineMetricsAndResources(metrics []pkg.PodMetrics, resources []pkg.PodRes
 		}
 		seen[key] = true
 
-		r, hasResources := resourcesMap[key]
-		var cpuRequest, cpuLimit, memRequest, memLimit string
-		if hasResources {
-			cpuRequest = r.CPURequest
-			cpuLimit = r.CPULimit
-			// Normalize memory requests/limits to match the usage unit
-			memoryUnit := pkg.ExtractMemoryUnit(m.Memory)
-			if !r.MemoryRequest.IsZero() {
-				memRequest = pkg.FormatMemoryInUnit(r.MemoryRequest, memoryUnit)
-			} else {
-				memRequest = "-"
-			}
-			if !r.MemoryLimit.IsZero() {
-				memLimit = pkg.FormatMemoryInUnit(r.MemoryLimit, memoryUnit)
-			} else {
-				memLimit = "-"
-			}
-		} else {
-			cpuRequest = "-"
-			cpuLimit = "-"
-			memRequest = "-"
-			memLimit = "-"
-		}
+		// Pods without resources keep zero (unset) requests and limits
+		r := resourcesMap[key]
 		combined = append(combined, CombinedPodData{
-			Name:          m.Name,
-			CPUUsage:      m.CPU,
-			CPURequest:    cpuRequest,
-			CPULimit:      cpuLimit,
-			MemoryUsage:   m.Memory,
-			MemoryRequest: memRequest,
-			MemoryLimit:   memLimit,
+			Namespace:          m.Namespace,
+			Name:               m.Name,
+			Resize:             r.Resize,
+			LimitRangeDefaults: r.LimitRangeDefaults,
+			Terminated:         pkg.IsTerminalPhase(r.Phase),
+			HasMetrics:         true,
+			CPUUsageMilli:      m.CPUMilli,
+			CPURequestMilli:    r.CPURequestMilli,
+			CPULimitMilli:      r.CPULimitMilli,
+			MemoryUsageBytes:   m.MemoryBytes,
+			MemoryRequestBytes: r.MemoryRequestBytes,
+			MemoryLimitBytes:   r.MemoryLimitBytes,
 		})
 	}
 
@@ -167,141 +239,279 @@ func combineMetricsAndResources(metrics []pkg.PodMetrics, resources []pkg.PodRes
 		}
 		seen[key] = true
 
-		// For pods without metrics, use default unit (Mi) for memory
-		var memRequest, memLimit string
-		if !r.MemoryRequest.IsZero() {
-			memRequest = pkg.FormatMemoryInUnit(r.MemoryRequest, "Mi")
-		} else {
-			memRequest = "-"
+		combined = append(combined, CombinedPodData{
+			Namespace:          r.Namespace,
+			Name:               r.Name,
+			Resize:             r.Resize,
+			LimitRangeDefaults: r.LimitRangeDefaults,
+			Terminated:         pkg.IsTerminalPhase(r.Phase),
+			CPURequestMilli:    r.CPURequestMilli,
+			CPULimitMilli:      r.CPULimitMilli,
+			MemoryRequestBytes: r.MemoryRequestBytes,
+			MemoryLimitBytes:   r.MemoryLimitBytes,
+		})
+	}
+
+	return combined
+}
+
+// combineContainerMetricsAndResources merges metrics and resources data for every container of every pod
+func combineContainerMetricsAndResources(metrics []pkg.PodMetrics, resources []pkg.PodResources) []CombinedPodData {
+	resourcesMap := make(map[string]pkg.ContainerResources)
+	for _, r := range resources {
+		for _, c := range r.Containers {
+			key := fmt.Sprintf("%s/%s/%s", r.Namespace, r.Name, c.Name)
+			resourcesMap[key] = c
 		}
-		if !r.MemoryLimit.IsZero() {
-			memLimit = pkg.FormatMemoryInUnit(r.MemoryLimit, "Mi")
-		} else {
-			memLimit = "-"
+	}
+
+	combined := make([]CombinedPodData, 0)
+	seen := make(map[string]bool)
+
+	// Add entries from metrics (containers with metrics)
+	for _, m := range metrics {
+		for _, c := range m.Containers {
+			key := fmt.Sprintf("%s/%s/%s", m.Namespace, m.Name, c.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			r := resourcesMap[key]
+			combined = append(combined, CombinedPodData{
+				Namespace:          m.Namespace,
+				Name:               m.Name,
+				Container:          c.Name,
+				Resize:             r.Resize,
+				LimitRangeDefaults: r.LimitRangeDefaults,
+				HasMetrics:         true,
+				CPUUsageMilli:      c.CPUMilli,
+				CPURequestMilli:    r.CPURequestMilli,
+				CPULimitMilli:      r.CPULimitMilli,
+				MemoryUsageBytes:   c.MemoryBytes,
+				MemoryRequestBytes: r.MemoryRequestBytes,
+				MemoryLimitBytes:   r.MemoryLimitBytes,
+			})
 		}
+	}
 
-		combined = append(combined, CombinedPodData{
-			Name:          r.Name,
-			CPUUsage:      "<unknown>",
-			CPURequest:    r.CPURequest,
-			CPULimit:      r.CPULimit,
-			MemoryUsage:   "<unknown>",
-			MemoryRequest: memRequest,
-			MemoryLimit:   memLimit,
-		})
+	// Add entries from resources that don't have metrics (containers without metrics)
+	for _, r := range resources {
+		for _, c := range r.Containers {
+			key := fmt.Sprintf("%s/%s/%s", r.Namespace, r.Name, c.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			combined = append(combined, CombinedPodData{
+				Namespace:          r.Namespace,
+				Name:               r.Name,
+				Container:          c.Name,
+				Resize:             c.Resize,
+				LimitRangeDefaults: c.LimitRangeDefaults,
+				CPURequestMilli:    c.CPURequestMilli,
+				CPULimitMilli:      c.CPULimitMilli,
+				MemoryRequestBytes: c.MemoryRequestBytes,
+				MemoryLimitBytes:   c.MemoryLimitBytes,
+			})
+		}
 	}
 
 	return combined
 }
 
+// podTableOptions controls which columns printTable prints
+type podTableOptions struct {
+	NoHeaders     bool
+	Containers    bool            // Each row is a container; POD and CONTAINER columns replace NAME
+	ShowNamespace bool            // Add a leading NAMESPACE column
+	Wide          bool            // Add usage-vs-request and usage-vs-limit percentage columns, RESIZE and DEFAULTED
+	VPA           bool            // Add the VerticalPodAutoscaler target, lower bound and upper bound columns
+	Usage         pkg.UsageWindow // Labels the usage columns with the statistic when aggregated over a window
+}
+
+// utilization returns usage as a percentage of the CPU and memory requests and limits.
+// Cells are "-" when there is no usage or no request/limit to compare against.
+func (d CombinedPodData) utilization() (cpuRequest, cpuLimit, memoryRequest, memoryLimit string) {
+	if !d.HasMetrics {
+		return "-", "-", "-", "-"
+	}
+	return pkg.FormatUtilization(d.CPUUsageMilli, d.CPURequestMilli),
+		pkg.FormatUtilization(d.CPUUsageMilli, d.CPULimitMilli),
+		pkg.FormatUtilization(d.MemoryUsageBytes, d.MemoryRequestBytes),
+		pkg.FormatUtilization(d.MemoryUsageBytes, d.MemoryLimitBytes)
+}
+
 // printTable prints the combined pod data in a formatted table
-func printTable(data []CombinedPodData, noHeaders bool) {
+func printTable(data []CombinedPodData, opts podTableOptions) {
 	// Calculate column widths
+	namespaceWidth := 20
 	nameWidth := 40
+	containerWidth := 20
 	cpuWidth := 12
 	memWidth := 15
+	percentWidth := 7
+	resizeWidth := 10
+	vpaWidth := 14
 
 	for _, d := range data {
+		if len(d.Namespace) > namespaceWidth {
+			namespaceWidth = len(d.Namespace)
+		}
 		if len(d.Name) > nameWidth {
 			nameWidth = len(d.Name)
 		}
+		if len(d.Container) > containerWidth {
+			containerWidth = len(d.Container)
+		}
 	}
 
 	// Print header unless --no-headers is set
-	if !noHeaders {
-		header := fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
-			nameWidth, "NAME",
-			cpuWidth, "CPU(cores)",
+	if !opts.NoHeaders {
+		var header string
+		if opts.ShowNamespace {
+			header = fmt.Sprintf("%-*s  ", namespaceWidth, "NAMESPACE")
+		}
+		if opts.Containers {
+			header += fmt.Sprintf("%-*s  %-*s  ", nameWidth, "POD", containerWidth, "CONTAINER")
+		} else {
+			header += fmt.Sprintf("%-*s  ", nameWidth, "NAME")
+		}
+		cpuUsage, memoryUsage := usageHeaders(opts.Usage)
+		header += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
+			cpuWidth, cpuUsage,
 			cpuWidth, "CPU REQUEST",
 			cpuWidth, "CPU LIMIT",
-			memWidth, "MEMORY(bytes)",
+			memWidth, memoryUsage,
 			memWidth, "MEMORY REQUEST",
 			memWidth, "MEMORY LIMIT",
 		)
+		if opts.VPA {
+			header += fmt.Sprintf("  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
+				vpaWidth, "VPA CPU TARGET",
+				vpaWidth, "VPA CPU LOWER",
+				vpaWidth, "VPA CPU UPPER",
+				vpaWidth, "VPA MEM TARGET",
+				vpaWidth, "VPA MEM LOWER",
+				vpaWidth, "VPA MEM UPPER",
+			)
+		}
+		if opts.Wide {
+			header += fmt.Sprintf("  %-*s  %-*s  %-*s  %-*s  %-*s  %s",
+				percentWidth, "CPU%REQ",
+				percentWidth, "CPU%LIM",
+				percentWidth, "MEM%REQ",
+				percentWidth, "MEM%LIM",
+				resizeWidth, "RESIZE",
+				"DEFAULTED",
+			)
+		}
 		fmt.Println(header)
 	}
 
 	// Print rows
 	for _, d := range data {
-		row := fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
-			nameWidth, d.Name,
-			cpuWidth, d.CPUUsage,
-			cpuWidth, d.CPURequest,
-			cpuWidth, d.CPULimit,
-			memWidth, d.MemoryUsage,
-			memWidth, d.MemoryRequest,
-			memWidth, d.MemoryLimit,
+		columns := d.columns()
+		var row string
+		if opts.ShowNamespace {
+			row = fmt.Sprintf("%-*s  ", namespaceWidth, d.Namespace)
+		}
+		if opts.Containers {
+			row += fmt.Sprintf("%-*s  %-*s  ", nameWidth, d.Name, containerWidth, d.Container)
+		} else {
+			row += fmt.Sprintf("%-*s  ", nameWidth, d.Name)
+		}
+		row += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
+			cpuWidth, columns.CPUUsage,
+			cpuWidth, columns.CPURequest,
+			cpuWidth, columns.CPULimit,
+			memWidth, columns.MemoryUsage,
+			memWidth, columns.MemoryRequest,
+			memWidth, columns.MemoryLimit,
 		)
+		if opts.VPA {
+			cpuTarget, cpuLower, cpuUpper, memoryTarget, memoryLower, memoryUpper := d.vpaColumns()
+			row += fmt.Sprintf("  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
+				vpaWidth, cpuTarget,
+				vpaWidth, cpuLower,
+				vpaWidth, cpuUpper,
+				vpaWidth, memoryTarget,
+				vpaWidth, memoryLower,
+				vpaWidth, memoryUpper,
+			)
+		}
+		if opts.Wide {
+			cpuRequestPercent, cpuLimitPercent, memoryRequestPercent, memoryLimitPercent := d.utilization()
+			resize := d.Resize
+			if resize == "" {
+				resize = "-"
+			}
+			defaulted := strings.Join(d.LimitRangeDefaults, ",")
+			if defaulted == "" {
+				defaulted = "-"
+			}
+			row += fmt.Sprintf("  %-*s  %-*s  %-*s  %-*s  %-*s  %s",
+				percentWidth, cpuRequestPercent,
+				percentWidth, cpuLimitPercent,
+				percentWidth, memoryRequestPercent,
+				percentWidth, memoryLimitPercent,
+				resizeWidth, resize,
+				defaulted,
+			)
+		}
 		fmt.Println(row)
 	}
 }
 
-// sortCombinedData sorts the combined pod data based on the sortBy field
+// sortCombinedData sorts the combined pod data based on the sortBy field.
+// Ties and unknown values keep the default namespace/name order.
 func sortCombinedData(data []CombinedPodData, sortBy string) {
-	switch sortBy {
-	case "cpu":
-		sort.Slice(data, func(i, j int) bool {
-			// Parse CPU values for comparison (handle "m" suffix for millicores)
-			return parseCPUValue(data[i].CPUUsage) > parseCPUValue(data[j].CPUUsage)
-		})
-	case "memory":
-		sort.Slice(data, func(i, j int) bool {
-			// Parse memory values for comparison
-			return parseMemoryValue(data[i].MemoryUsage) > parseMemoryValue(data[j].MemoryUsage)
-		})
-	default:
-		// Default: sort by name
-		sort.Slice(data, func(i, j int) bool {
+	sortByName(data)
+
+	field, descending := parseSortBy(sortBy)
+	switch field {
+	case "namespace":
+		if descending {
+			sort.SliceStable(data, func(i, j int) bool { return data[i].Namespace > data[j].Namespace })
+		}
+	case "name":
+		sort.SliceStable(data, func(i, j int) bool {
+			if descending {
+				return data[i].Name > data[j].Name
+			}
 			return data[i].Name < data[j].Name
 		})
+	default:
+		if value, ok := podSortValues[field]; ok {
+			sortByValue(data, value, descending)
+		}
 	}
 }
 
-// parseCPUValue parses CPU string to float64 for sorting (handles "m" suffix)
-func parseCPUValue(cpuStr string) float64 {
-	if cpuStr == "" || cpuStr == "-" || cpuStr == "<unknown>" {
-		return 0
-	}
-	// Remove "m" suffix and convert to float
-	cpuStr = strings.TrimSuffix(cpuStr, "m")
-	var value float64
-	_, _ = fmt.Sscanf(cpuStr, "%f", &value)
-	return value
-}
-
-// parseMemoryValue parses memory string to bytes for sorting
-func parseMemoryValue(memStr string) int64 {
-	if memStr == "" || memStr == "-" || memStr == "<unknown>" {
-		return 0
-	}
-	// Simple parsing - convert common units to bytes
-	if strings.HasSuffix(memStr, "Gi") {
-		var value float64
-		_, _ = fmt.Sscanf(memStr, "%fGi", &value)
-		return int64(value * 1024 * 1024 * 1024)
-	}
-	if strings.HasSuffix(memStr, "Mi") {
-		var value float64
-		_, _ = fmt.Sscanf(memStr, "%fMi", &value)
-		return int64(value * 1024 * 1024)
-	}
-	if strings.HasSuffix(memStr, "Ki") {
-		var value float64
-		_, _ = fmt.Sscanf(memStr, "%fKi", &value)
-		return int64(value * 1024)
-	}
-	return 0
+// sortByName sorts the combined pod data by namespace, pod name, then container name,
+// the same order 'kubectl top pod -A' uses
+func sortByName(data []CombinedPodData) {
+	sort.Slice(data, func(i, j int) bool {
+		if data[i].Namespace != data[j].Namespace {
+			return data[i].Namespace < data[j].Namespace
+		}
+		if data[i].Name != data[j].Name {
+			return data[i].Name < data[j].Name
+		}
+		return data[i].Container < data[j].Container
+	})
 }
 
 // NewPodCommand creates a new pod command
 func NewPodCommand() *cobra.Command {
-	var namespace string
+	factory := newClientFactory()
+	var opts PodOptions
+	var sourceOpts metricsSourceOptions
+	var window time.Duration
+	var usageStat string
+	var fromFile, at string
 	var allNamespaces bool
-	var labelSelector string
-	var fieldSelector string
-	var sortBy string
-	var noHeaders bool
-	var containers bool
 	var useProtocolBuffers bool
 
 	cmd := &cobra.Command{
@@ -324,86 +534,98 @@ Examples:
   kubectl rltop pod POD_NAME
   
   # Show metrics for the pods defined by label name=myLabel
-  kubectl rltop pod -l name=myLabel`,
+  kubectl rltop pod -l name=myLabel
+
+  # Show metrics for every container of a given pod
+  kubectl rltop pod POD_NAME --containers
+
+  # Show usage as a percentage of requests and limits
+  kubectl rltop pod -o wide
+
+  # Compare requests with VerticalPodAutoscaler recommendations
+  kubectl rltop pod --vpa
+
+  # Print metrics as JSON for use in scripts
+  kubectl rltop pod -o json
+
+  # Show the 95th percentile of usage over the last day, read from Prometheus
+  kubectl rltop pod --metrics-source=prometheus --prometheus-url=http://localhost:9090 --window=24h --stat=p95
+
+  # Show the pods of the last snapshot recorded with 'rltop record'
+  kubectl rltop pod --from-file incident.jsonl
+
+  # Refresh the table every 5 seconds until interrupted
+  kubectl rltop pod --watch --interval=5s`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(opts.Output, podOutputFormats); err != nil {
+				return err
+			}
+			replayAt, err := validateFromFile(cmd, fromFile, at, "selector", "field-selector", "vpa", "watch",
+				"window", "stat", "metrics-source", "prometheus-url")
+			if err != nil {
+				return err
+			}
+			if err := sourceOpts.validate(); err != nil {
+				return err
+			}
+			usage, err := parseUsageWindow(window, usageStat, cmd.Flags().Changed("stat"), sourceOpts)
+			if err != nil {
+				return err
+			}
+			opts.Usage = usage
+			if err := validateWatchInterval(opts.Watch, opts.Interval); err != nil {
+				return err
+			}
+			if err := validateSortBy(opts.SortBy, podSortFields); err != nil {
+				return err
+			}
+
 			// Extract pod names from args
-			var podNames []string
 			if len(args) > 0 {
-				podNames = args
+				opts.PodNames = args
 			}
 
-			// Note: --containers and --use-protocol-buffers are not yet implemented
-			// but we accept the flags for compatibility
-			_ = containers
+			// VPA recommendations are per container
+			if opts.VPA {
+				opts.Containers = true
+			}
+
+			// Note: --use-protocol-buffers is not yet implemented but we accept the flag for compatibility
 			_ = useProtocolBuffers
-			// Use RESTClientGetter pattern - same as kubectl plugins use
-			// This properly handles kubeconfig loading with exec plugins
-			loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-			configOverrides := &clientcmd.ConfigOverrides{}
-
-			clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-				loadingRules,
-				configOverrides,
-			)
 
-			// Get namespace from context if not specified
-			if !allNamespaces && namespace == "" {
-				ns, _, err := clientConfig.Namespace()
-				if err == nil && ns != "" {
-					namespace = ns
-				} else {
-					// Default to "default" namespace if context doesn't have one
-					namespace = "default"
+			// A recording is replayed without the cluster, across every recorded namespace unless -n is given
+			if fromFile != "" {
+				if allNamespaces {
+					opts.Namespace = ""
+				}
+				snapshot, err := readSnapshotFile(fromFile, replayAt)
+				if err != nil {
+					return err
 				}
+				return RunPodSnapshot(snapshot, opts)
 			}
 
-			// Handle -A/--all-namespaces flag (must be after namespace detection)
-			if allNamespaces {
-				namespace = ""
+			// Get namespace from context if not specified
+			if !allNamespaces && opts.Namespace == "" {
+				opts.Namespace = factory.Namespace()
 			}
 
-			// Get REST config
-			config, err := clientConfig.ClientConfig()
-			if err != nil {
-				// Provide helpful error message for common exec plugin issues
-				errMsg := err.Error()
-				if strings.Contains(errMsg, "exec plugin") && strings.Contains(errMsg, "apiVersion") {
-					return fmt.Errorf("failed to load kubeconfig: %w. "+
-						"Your kubeconfig uses an exec plugin with an outdated API version. "+
-						"To fix this, update your kubeconfig by running: "+
-						"kubectl config view --raw > ~/.kube/config.new && "+
-						"mv ~/.kube/config.new ~/.kube/config. "+
-						"Or regenerate your kubeconfig using your cloud provider's CLI tool", err)
-				}
-				return fmt.Errorf("failed to load kubeconfig: %w", err)
+			// Handle -A/--all-namespaces flag (must be after namespace detection)
+			if allNamespaces {
+				opts.Namespace = ""
 			}
 
-			// Create clients
-			clientset, err := kubernetes.NewForConfig(config)
+			clientset, metricsClient, err := factory.Clients()
 			if err != nil {
-				errMsg := err.Error()
-				if strings.Contains(errMsg, "exec plugin") && strings.Contains(errMsg, "apiVersion") {
-					return fmt.Errorf("failed to create kubernetes client: %w. "+
-						"Your kubeconfig uses an exec plugin with an outdated API version (v1alpha1). "+
-						"This version of kubectl-rltop requires exec plugins to use v1beta1 or v1. "+
-						"To fix this, update your kubeconfig: "+
-						"1. Run: kubectl config view --raw > ~/.kube/config.new "+
-						"2. Check the file and update any exec plugin apiVersion from v1alpha1 to v1beta1 "+
-						"3. Replace: mv ~/.kube/config.new ~/.kube/config. "+
-						"Or regenerate your kubeconfig using your cloud provider's CLI tool", err)
-				}
-				return fmt.Errorf("failed to create kubernetes client: %w", err)
+				return err
 			}
+			source := sourceOpts.newSource(clientset, metricsClient, opts.Usage)
 
-			metricsClient, err := metricsclientset.NewForConfig(config)
-			if err != nil {
-				errMsg := err.Error()
-				if strings.Contains(errMsg, "exec plugin") && strings.Contains(errMsg, "apiVersion") {
-					return fmt.Errorf("failed to create metrics client: %w. "+
-						"Your kubeconfig uses an exec plugin with an outdated API version. "+
-						"See the error above for instructions on how to fix this", err)
+			var dynamicClient dynamic.Interface
+			if opts.VPA {
+				if dynamicClient, err = factory.DynamicClient(); err != nil {
+					return err
 				}
-				return fmt.Errorf("failed to create metrics client: %w", err)
 			}
 
 			ctx := cmd.Context()
@@ -411,34 +633,46 @@ Examples:
 				ctx = context.Background()
 			}
 
-			return RunPod(
-				ctx, clientset, metricsClient,
-				namespace, labelSelector, fieldSelector,
-				podNames, sortBy, noHeaders,
-			)
+			return RunPod(ctx, clientset, source, dynamicClient, opts)
 		},
 	}
 
 	// Add all flags matching kubectl top pods
-	cmd.Flags().StringVarP(&namespace, "namespace", "n", "",
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "",
 		"Namespace to query (default: namespace from current context, or 'default')")
 	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false,
 		"If present, list the requested object(s) across all namespaces. "+
 			"Namespace in current context is ignored even if specified with --namespace.")
-	cmd.Flags().StringVarP(&labelSelector, "selector", "l", "",
+	cmd.Flags().StringVarP(&opts.LabelSelector, "selector", "l", "",
 		"Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
-	cmd.Flags().StringVar(&fieldSelector, "field-selector", "",
+	cmd.Flags().StringVar(&opts.FieldSelector, "field-selector", "",
 		"Selector (field query) to filter on, supports '=', '==', and '!='. "+
 			"(e.g. --field-selector key1=value1,key2=value2). "+
 			"The server only supports a limited number of field queries per type.")
-	cmd.Flags().StringVar(&sortBy, "sort-by", "",
-		"If non-empty, sort pods list using specified field. The field can be either 'cpu' or 'memory'.")
-	cmd.Flags().BoolVar(&noHeaders, "no-headers", false,
+	cmd.Flags().StringVar(&opts.SortBy, "sort-by", "",
+		"If non-empty, sort pods list using specified field. One of: "+strings.Join(podSortFields, ", ")+". "+
+			"'cpu-util' and 'memory-util' compare usage with requests, 'cpu-headroom' and 'memory-headroom' "+
+			"are the request minus usage. Append ':asc' or ':desc' to choose the direction "+
+			"(numeric fields default to descending, names to ascending).")
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
 		"If present, print output without headers.")
-	cmd.Flags().BoolVar(&containers, "containers", false,
+	cmd.Flags().BoolVar(&opts.Containers, "containers", false,
 		"If present, print usage of containers within a pod.")
+	cmd.Flags().BoolVar(&opts.VPA, "vpa", false,
+		"If present, add the target, lower bound and upper bound of the VerticalPodAutoscaler (autoscaling.k8s.io/v1) "+
+			"targeting each pod's workload. Implies --containers.")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "",
+		"Output format. One of: (json, yaml, wide). "+
+			"'wide' adds CPU%REQ, CPU%LIM, MEM%REQ and MEM%LIM columns comparing usage with requests and limits, "+
+			"a RESIZE column showing in-place resizes (Pending, Infeasible, InProgress), "+
+			"and a DEFAULTED column listing the requests and limits set by a LimitRange default.")
+	addWatchFlags(cmd, &opts.Watch, &opts.Interval)
 	cmd.Flags().BoolVar(&useProtocolBuffers, "use-protocol-buffers", true,
 		"Enables using protocol-buffers to access Metrics API.")
+	addMetricsSourceFlags(cmd, &sourceOpts)
+	addUsageWindowFlags(cmd, &window, &usageStat)
+	addFromFileFlags(cmd, &fromFile, &at)
+	factory.AddFlags(cmd.Flags())
 
 	return cmd
 }
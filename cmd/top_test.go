@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCombineContainerMetricsAndResources(t *testing.T) {
+	metrics := []pkg.PodMetrics{
+		{
+			Name:        "pod1",
+			Namespace:   "default",
+			CPUMilli:    150,
+			MemoryBytes: 192 << 20,
+			Containers: []pkg.ContainerMetrics{
+				{Name: "app", CPUMilli: 100, MemoryBytes: 128 << 20},
+				{Name: "sidecar", CPUMilli: 50, MemoryBytes: 64 << 20},
+			},
+		},
+	}
+	resources := []pkg.PodResources{
+		{
+			Name:      "pod1",
+			Namespace: "default",
+			Containers: []pkg.ContainerResources{
+				{
+					Name:               "app",
+					CPURequestMilli:    200,
+					CPULimitMilli:      400,
+					MemoryRequestBytes: 256 << 20,
+					MemoryLimitBytes:   512 << 20,
+				},
+				{Name: "sidecar"},
+			},
+		},
+		{
+			Name:      "pod2",
+			Namespace: "default",
+			Containers: []pkg.ContainerResources{
+				{Name: "app", CPURequestMilli: 100},
+			},
+		},
+	}
+
+	result := combineContainerMetricsAndResources(metrics, resources)
+	if len(result) != 3 {
+		t.Fatalf("combineContainerMetricsAndResources() returned %d rows, want 3", len(result))
+	}
+
+	sortByName(result)
+
+	expected := []CombinedPodData{
+		{
+			Namespace: "default", Name: "pod1", Container: "app",
+			HasMetrics: true, CPUUsageMilli: 100, CPURequestMilli: 200, CPULimitMilli: 400,
+			MemoryUsageBytes: 128 << 20, MemoryRequestBytes: 256 << 20, MemoryLimitBytes: 512 << 20,
+		},
+		{
+			Namespace: "default", Name: "pod1", Container: "sidecar",
+			HasMetrics: true, CPUUsageMilli: 50, MemoryUsageBytes: 64 << 20,
+		},
+		{
+			Namespace: "default", Name: "pod2", Container: "app",
+			CPURequestMilli: 100,
+		},
+	}
+	for i, want := range expected {
+		if !reflect.DeepEqual(result[i], want) {
+			t.Errorf("combineContainerMetricsAndResources() row %d = %+v, want %+v", i, result[i], want)
+		}
+	}
+}
+
+func TestCombineMetricsAndResourcesAcrossNamespaces(t *testing.T) {
+	metrics := []pkg.PodMetrics{
+		{Name: "web", Namespace: "prod", CPUMilli: 100, MemoryBytes: 128 << 20},
+		{Name: "web", Namespace: "dev", CPUMilli: 10, MemoryBytes: 64 << 20},
+	}
+	resources := []pkg.PodResources{
+		{Name: "web", Namespace: "prod", CPURequestMilli: 200},
+		{Name: "web", Namespace: "dev", CPURequestMilli: 20, Phase: corev1.PodFailed},
+	}
+
+	result := combineMetricsAndResources(metrics, resources)
+	sortByName(result)
+
+	if len(result) != 2 {
+		t.Fatalf("combineMetricsAndResources() returned %d rows, want 2", len(result))
+	}
+	if result[0].Namespace != "dev" || result[0].CPURequestMilli != 20 {
+		t.Errorf("combineMetricsAndResources() row 0 = %s/%s request %dm, want dev/web request 20m",
+			result[0].Namespace, result[0].Name, result[0].CPURequestMilli)
+	}
+	if result[1].Namespace != "prod" || result[1].CPURequestMilli != 200 {
+		t.Errorf("combineMetricsAndResources() row 1 = %s/%s request %dm, want prod/web request 200m",
+			result[1].Namespace, result[1].Name, result[1].CPURequestMilli)
+	}
+	if !result[0].Terminated || result[1].Terminated {
+		t.Errorf("combineMetricsAndResources() terminated = %v, %v, want only the failed dev/web",
+			result[0].Terminated, result[1].Terminated)
+	}
+}
+
+func TestPrintTableNamespaceColumn(t *testing.T) {
+	data := []CombinedPodData{
+		{Namespace: "kube-system", Name: "coredns", HasMetrics: true, CPUUsageMilli: 3, CPURequestMilli: 100,
+			MemoryUsageBytes: 12 << 20, MemoryRequestBytes: 70 << 20, MemoryLimitBytes: 170 << 20},
+	}
+
+	tests := []struct {
+		name          string
+		showNamespace bool
+		want          bool
+	}{
+		{"all namespaces", true, true},
+		{"single namespace", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureStdout(t, func() {
+				printTable(data, podTableOptions{ShowNamespace: tt.showNamespace})
+			})
+			if got := strings.Contains(output, "NAMESPACE"); got != tt.want {
+				t.Errorf("printTable() NAMESPACE header present = %v, want %v. Output: %s", got, tt.want, output)
+			}
+			if got := strings.Contains(output, "kube-system"); got != tt.want {
+				t.Errorf("printTable() namespace value present = %v, want %v. Output: %s", got, tt.want, output)
+			}
+		})
+	}
+}
+
+// captureStdout returns everything written to stdout while f runs
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+	os.Stdout = w
+
+	f()
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+	return string(out)
+}
+
+func TestPrintTableWide(t *testing.T) {
+	data := []CombinedPodData{
+		{
+			Name: "pod1", HasMetrics: true, CPUUsageMilli: 150, CPURequestMilli: 100,
+			MemoryUsageBytes: 128 << 20, MemoryRequestBytes: 256 << 20, MemoryLimitBytes: 512 << 20,
+		},
+	}
+
+	output := captureStdout(t, func() {
+		printTable(data, podTableOptions{Wide: true})
+	})
+
+	for _, col := range []string{"CPU%REQ", "CPU%LIM", "MEM%REQ", "MEM%LIM", "RESIZE", "DEFAULTED"} {
+		if !strings.Contains(output, col) {
+			t.Errorf("printTable() wide output missing column %s. Output: %s", col, output)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	want := []string{"150%", "-", "50%", "25%", "-", "-"}
+	got := fields[len(fields)-6:]
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("printTable() wide percentages = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestPrintTableWideResizeAndDefaults(t *testing.T) {
+	data := []CombinedPodData{
+		{
+			Name: "pod1", Resize: pkg.ResizeInfeasible, LimitRangeDefaults: []string{"limits.cpu", "requests.cpu"},
+			HasMetrics: true, CPUUsageMilli: 150, CPURequestMilli: 100,
+		},
+	}
+
+	output := captureStdout(t, func() {
+		printTable(data, podTableOptions{Wide: true})
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if got := fields[len(fields)-2]; got != pkg.ResizeInfeasible {
+		t.Errorf("printTable() wide RESIZE = %q, want %q. Output: %s", got, pkg.ResizeInfeasible, output)
+	}
+	if got, want := fields[len(fields)-1], "limits.cpu,requests.cpu"; got != want {
+		t.Errorf("printTable() wide DEFAULTED = %q, want %q. Output: %s", got, want, output)
+	}
+}
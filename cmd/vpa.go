@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// addVPARecommendations sets the VPA recommendation of every container row whose pod's workload, or direct
+// controller, is the target of a VerticalPodAutoscaler. Without the VPA CRD a warning is printed on stderr
+// and the rows are left as they are.
+func addVPARecommendations(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	dynamicClient dynamic.Interface,
+	namespace string,
+	resources []pkg.PodResources,
+	combined []CombinedPodData,
+) error {
+	recommendations, err := pkg.GetVPARecommendations(ctx, dynamicClient, namespace)
+	if errors.Is(err, pkg.ErrVPANotInstalled) {
+		fmt.Fprintf(os.Stderr, "Warning: %v, VPA columns are empty\n", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(recommendations) == 0 {
+		return nil
+	}
+
+	workloads, err := pkg.ResolveWorkloads(ctx, clientset, namespace, resources)
+	if err != nil {
+		return err
+	}
+	owners := make(map[string]pkg.Workload, len(resources))
+	for _, r := range resources {
+		owners[r.Namespace+"/"+r.Name] = r.Owner
+	}
+
+	for i := range combined {
+		d := &combined[i]
+		key := d.Namespace + "/" + d.Name
+		for _, workload := range []pkg.Workload{workloads[key], owners[key]} {
+			byContainer, ok := recommendations[pkg.VPATarget{Namespace: d.Namespace, Workload: workload}]
+			if !ok {
+				continue
+			}
+			if r, ok := byContainer[d.Container]; ok {
+				d.VPA = &r
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+// vpaColumns formats the VPA target, lower bound and upper bound, "-" without a recommendation.
+// Memory uses the unit of the memory usage, like the requests and limits.
+func (d CombinedPodData) vpaColumns() (cpuTarget, cpuLower, cpuUpper, memoryTarget, memoryLower, memoryUpper string) {
+	if d.VPA == nil {
+		return "-", "-", "-", "-", "-", "-"
+	}
+
+	unit := "Mi"
+	if d.HasMetrics {
+		unit = pkg.MemoryUnit(d.MemoryUsageBytes)
+	}
+	return formatCPUMilli(d.VPA.CPUTargetMilli),
+		formatCPUMilli(d.VPA.CPULowerBoundMilli),
+		formatCPUMilli(d.VPA.CPUUpperBoundMilli),
+		formatMemoryBytesIn(d.VPA.MemoryTargetBytes, unit),
+		formatMemoryBytesIn(d.VPA.MemoryLowerBoundBytes, unit),
+		formatMemoryBytesIn(d.VPA.MemoryUpperBoundBytes, unit)
+}
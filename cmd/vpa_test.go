@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAddVPARecommendations(t *testing.T) {
+	vpa := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling.k8s.io/v1",
+		"kind":       "VerticalPodAutoscaler",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "web"},
+		},
+		"status": map[string]interface{}{
+			"recommendation": map[string]interface{}{
+				"containerRecommendations": []interface{}{
+					map[string]interface{}{"containerName": "app", "target": map[string]interface{}{"cpu": "250m"}},
+				},
+			},
+		},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{pkg.VPAResource: "VerticalPodAutoscalerList"}, vpa)
+
+	isController := true
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:      "web-abc",
+		Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Controller: &isController},
+		},
+	}}
+	clientset := fake.NewSimpleClientset(replicaSet)
+
+	resources := []pkg.PodResources{
+		{Namespace: "default", Name: "web-abc-1", Owner: pkg.Workload{Kind: pkg.KindReplicaSet, Name: "web-abc"}},
+		{Namespace: "default", Name: "stray", Owner: pkg.Workload{Kind: pkg.KindPod, Name: "stray"}},
+	}
+	combined := []CombinedPodData{
+		{Namespace: "default", Name: "web-abc-1", Container: "app"},
+		{Namespace: "default", Name: "web-abc-1", Container: "sidecar"},
+		{Namespace: "default", Name: "stray", Container: "app"},
+	}
+
+	err := addVPARecommendations(context.Background(), clientset, dynamicClient, "default", resources, combined)
+	if err != nil {
+		t.Fatalf("addVPARecommendations() error = %v", err)
+	}
+	if combined[0].VPA == nil || combined[0].VPA.CPUTargetMilli != 250 {
+		t.Errorf("addVPARecommendations() app of the deployment = %+v, want a 250m target", combined[0].VPA)
+	}
+	if combined[1].VPA != nil || combined[2].VPA != nil {
+		t.Errorf("addVPARecommendations() containers without a recommendation should be left unset")
+	}
+}
+
+func TestPrintTableVPA(t *testing.T) {
+	data := []CombinedPodData{
+		{
+			Namespace: "default", Name: "web-abc-1", Container: "app", HasMetrics: true, MemoryUsageBytes: 100 << 20,
+			VPA: &pkg.VPARecommendation{CPUTargetMilli: 250, CPULowerBoundMilli: 100, MemoryTargetBytes: 128 << 20},
+		},
+		{Namespace: "default", Name: "stray", Container: "app"},
+	}
+
+	output := captureStdout(t, func() {
+		printTable(data, podTableOptions{Containers: true, VPA: true})
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("printTable() printed %d lines, want 3. Output: %s", len(lines), output)
+	}
+	for _, col := range []string{"VPA CPU TARGET", "VPA CPU LOWER", "VPA CPU UPPER", "VPA MEM TARGET", "VPA MEM UPPER"} {
+		if !strings.Contains(lines[0], col) {
+			t.Errorf("printTable() header missing %s. Output: %s", col, output)
+		}
+	}
+
+	// Unset bounds and pods without a VPA show "-"
+	want := []string{"250m", "100m", "-", "128.00Mi", "-", "-"}
+	if fields := strings.Fields(lines[1]); strings.Join(fields[len(fields)-6:], " ") != strings.Join(want, " ") {
+		t.Errorf("printTable() VPA columns = %v, want %v", fields[len(fields)-6:], want)
+	}
+	if fields := strings.Fields(lines[2]); strings.Join(fields[len(fields)-6:], " ") != "- - - - - -" {
+		t.Errorf("printTable() VPA columns without a VPA = %v", fields[len(fields)-6:])
+	}
+
+	list := newPodUsageList(data)
+	if v := list.Items[0].VPA; v == nil || v.CPU.Target.Millicores != 250 || v.CPU.UpperBound != nil {
+		t.Errorf("newPodUsageList() VPA = %+v", v)
+	}
+	if list.Items[1].VPA != nil {
+		t.Errorf("newPodUsageList() VPA should be omitted without a recommendation")
+	}
+}
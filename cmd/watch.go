@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultWatchInterval matches the default metrics-server resolution; polling faster returns the same samples
+const defaultWatchInterval = 15 * time.Second
+
+// clearScreen moves the cursor to the top-left corner and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// addWatchFlags adds the --watch and --interval flags to a command
+func addWatchFlags(cmd *cobra.Command, watch *bool, interval *time.Duration) {
+	cmd.Flags().BoolVarP(watch, "watch", "w", false,
+		"If present, keep refreshing the output until interrupted (Ctrl-C).")
+	cmd.Flags().DurationVar(interval, "interval", defaultWatchInterval,
+		"Time to wait between refreshes in watch mode (e.g. 5s, 1m).")
+}
+
+// validateWatchInterval returns an error if watch mode is requested with a non-positive interval
+func validateWatchInterval(watch bool, interval time.Duration) error {
+	if watch && interval <= 0 {
+		return fmt.Errorf("invalid --interval %s, must be greater than zero", interval)
+	}
+	return nil
+}
+
+// runWatch calls refresh immediately and then every interval until ctx is cancelled.
+// On a terminal the screen is cleared before each refresh so the output is redrawn in place.
+// When output is piped, each table refresh is appended after a timestamp line and YAML
+// documents are separated with "---", so the stream stays parseable.
+// Errors from a single refresh are reported on stderr and do not stop watching.
+func runWatch(ctx context.Context, interval time.Duration, output string, refresh func(context.Context) error) error {
+	tty := isTerminal(os.Stdout)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for first := true; ; first = false {
+		now := time.Now().Format(time.RFC3339)
+		switch {
+		case tty:
+			fmt.Print(clearScreen)
+			if !isStructuredOutput(output) {
+				fmt.Printf("Every %s: %s\n\n", interval, now)
+			}
+		case output == outputYAML:
+			if !first {
+				fmt.Println("---")
+			}
+		case !isStructuredOutput(output):
+			if !first {
+				fmt.Println()
+			}
+			fmt.Println(now)
+		}
+
+		if err := refresh(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// isTerminal reports whether f is connected to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	output := captureStdout(t, func() {
+		err := runWatch(ctx, time.Millisecond, "", func(ctx context.Context) error {
+			calls++
+			if calls == 2 {
+				// A failed refresh must not stop watching
+				return errors.New("transient error")
+			}
+			if calls == 3 {
+				cancel()
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("runWatch() error = %v", err)
+		}
+	})
+
+	if calls != 3 {
+		t.Errorf("runWatch() refreshed %d times, want 3", calls)
+	}
+	// Piped table output gets one timestamp line per refresh
+	timestamps := 0
+	for _, line := range strings.Split(output, "\n") {
+		if _, err := time.Parse(time.RFC3339, line); err == nil {
+			timestamps++
+		}
+	}
+	if timestamps != 3 {
+		t.Errorf("runWatch() printed %d timestamps, want 3. Output: %s", timestamps, output)
+	}
+}
+
+func TestRunWatchCancelledDuringRefresh(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := runWatch(ctx, time.Hour, outputJSON, func(ctx context.Context) error {
+		cancel()
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Errorf("runWatch() error = %v, want nil after cancellation", err)
+	}
+}
+
+func TestValidateWatchInterval(t *testing.T) {
+	if err := validateWatchInterval(true, 0); err == nil {
+		t.Errorf("validateWatchInterval(true, 0) expected error")
+	}
+	if err := validateWatchInterval(false, 0); err != nil {
+		t.Errorf("validateWatchInterval(false, 0) error = %v", err)
+	}
+	if err := validateWatchInterval(true, time.Second); err != nil {
+		t.Errorf("validateWatchInterval(true, 1s) error = %v", err)
+	}
+}
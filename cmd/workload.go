@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/veditoid/kubectl-rltop/pkg"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CombinedWorkloadData represents the pods of a workload aggregated together.
+// The embedded group holds the namespace and name of the workload and the totals of its pods.
+type CombinedWorkloadData struct {
+	Kind string
+	podGroup
+}
+
+// WorkloadOptions holds the options of the workload command
+type WorkloadOptions struct {
+	Namespace     string // Empty means all namespaces
+	LabelSelector string
+	WorkloadNames []string // NAME or KIND/NAME
+	SortBy        string
+	NoHeaders     bool
+	Output        string
+	Watch         bool
+	Interval      time.Duration
+}
+
+// RunWorkload executes the workload command
+func RunWorkload(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	opts WorkloadOptions,
+) error {
+	// Check the metrics source is available (only once, also in watch mode)
+	if err := source.Check(ctx); err != nil {
+		return err
+	}
+
+	refresh := func(ctx context.Context) error {
+		combined, err := fetchWorkloadData(ctx, clientset, source, opts)
+		if err != nil {
+			return err
+		}
+		return printWorkloadData(combined, opts)
+	}
+
+	if opts.Watch {
+		return runWatch(ctx, opts.Interval, opts.Output, refresh)
+	}
+	return refresh(ctx)
+}
+
+// fetchWorkloadData fetches pod metrics and resources, resolves the workload of every pod
+// and returns the pods aggregated per workload, filtered and sorted
+func fetchWorkloadData(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	source pkg.MetricsSource,
+	opts WorkloadOptions,
+) ([]CombinedWorkloadData, error) {
+	metrics, resources, err := fetchPodMetricsAndResources(ctx, clientset, source,
+		opts.Namespace, opts.LabelSelector, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	workloads, err := pkg.ResolveWorkloads(ctx, clientset, opts.Namespace, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	combined := combineWorkloads(combineMetricsAndResources(metrics, resources), workloads)
+	combined = filterWorkloads(combined, opts.WorkloadNames)
+
+	// Sort based on sortBy parameter (default: by namespace, kind, then name)
+	sortWorkloadData(combined, opts.SortBy)
+
+	return combined, nil
+}
+
+// printWorkloadData prints the combined workload data in the requested output format
+func printWorkloadData(combined []CombinedWorkloadData, opts WorkloadOptions) error {
+	if isStructuredOutput(opts.Output) {
+		return printStructured(newWorkloadUsageList(combined), opts.Output)
+	}
+
+	if len(combined) == 0 {
+		fmt.Fprintf(os.Stderr, "No workloads found\n")
+		return nil
+	}
+
+	// Print table, with a NAMESPACE column when listing across all namespaces
+	printWorkloadTable(combined, workloadTableOptions{
+		NoHeaders:     opts.NoHeaders,
+		ShowNamespace: opts.Namespace == "",
+		Wide:          opts.Output == outputWide,
+	})
+
+	return nil
+}
+
+// combineWorkloads sums the pods of every workload. Pods missing from workloads (metrics of a pod
+// that was not listed) are their own workload. Terminated pods, such as the completed Jobs a CronJob
+// keeps in its history, are left out.
+func combineWorkloads(pods []CombinedPodData, workloads map[string]pkg.Workload) []CombinedWorkloadData {
+	combined := make([]CombinedWorkloadData, 0)
+	index := make(map[string]int)
+
+	for _, p := range pods {
+		if p.Terminated {
+			continue
+		}
+		workload, ok := workloads[p.Namespace+"/"+p.Name]
+		if !ok {
+			workload = pkg.Workload{Kind: pkg.KindPod, Name: p.Name}
+		}
+
+		key := p.Namespace + "/" + workload.Kind + "/" + workload.Name
+		i, ok := index[key]
+		if !ok {
+			i = len(combined)
+			index[key] = i
+			combined = append(combined, CombinedWorkloadData{
+				Kind:     workload.Kind,
+				podGroup: podGroup{CombinedPodData: CombinedPodData{Namespace: p.Namespace, Name: workload.Name}},
+			})
+		}
+		combined[i].add(p)
+	}
+
+	return combined
+}
+
+// filterWorkloads keeps the workloads matching one of the names, see matchWorkload.
+// No names keeps every workload.
+func filterWorkloads(data []CombinedWorkloadData, names []string) []CombinedWorkloadData {
+	if len(names) == 0 {
+		return data
+	}
+
+	filtered := make([]CombinedWorkloadData, 0, len(data))
+	for _, d := range data {
+		if matchWorkload(d.Kind, d.Name, names) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// matchWorkload reports whether a workload matches one of the names, given as NAME or KIND/NAME
+// (the kind is case-insensitive, e.g. deployment/web)
+func matchWorkload(kind, name string, names []string) bool {
+	for _, n := range names {
+		nameKind, workloadName, hasKind := strings.Cut(n, "/")
+		if !hasKind {
+			workloadName = nameKind
+		}
+		if name == workloadName && (!hasKind || strings.EqualFold(kind, nameKind)) {
+			return true
+		}
+	}
+	return false
+}
+
+// workloadTableOptions controls which columns printWorkloadTable prints
+type workloadTableOptions struct {
+	NoHeaders     bool
+	ShowNamespace bool // Add a leading NAMESPACE column
+	Wide          bool // Add usage-vs-request and usage-vs-limit percentage columns
+}
+
+// printWorkloadTable prints the combined workload data in a formatted table
+func printWorkloadTable(data []CombinedWorkloadData, opts workloadTableOptions) {
+	// Calculate column widths
+	namespaceWidth := 20
+	kindWidth := 11
+	nameWidth := 40
+	podsWidth := 4
+	cpuWidth := 12
+	memWidth := 15
+	percentWidth := 7
+
+	for _, d := range data {
+		if len(d.Namespace) > namespaceWidth {
+			namespaceWidth = len(d.Namespace)
+		}
+		if len(d.Kind) > kindWidth {
+			kindWidth = len(d.Kind)
+		}
+		if len(d.Name) > nameWidth {
+			nameWidth = len(d.Name)
+		}
+	}
+
+	// Print header unless --no-headers is set
+	if !opts.NoHeaders {
+		var header string
+		if opts.ShowNamespace {
+			header = fmt.Sprintf("%-*s  ", namespaceWidth, "NAMESPACE")
+		}
+		header += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
+			kindWidth, "KIND",
+			nameWidth, "NAME",
+			podsWidth, "PODS",
+			cpuWidth, "CPU(cores)",
+			cpuWidth, "CPU AVG",
+			cpuWidth, "CPU REQUEST",
+			cpuWidth, "CPU LIMIT",
+			memWidth, "MEMORY(bytes)",
+			memWidth, "MEMORY AVG",
+			memWidth, "MEMORY REQUEST",
+			memWidth, "MEMORY LIMIT",
+		)
+		if opts.Wide {
+			header += fmt.Sprintf("  %-*s  %-*s  %-*s  %-*s",
+				percentWidth, "CPU%REQ",
+				percentWidth, "CPU%LIM",
+				percentWidth, "MEM%REQ",
+				percentWidth, "MEM%LIM",
+			)
+		}
+		fmt.Println(header)
+	}
+
+	// Print rows
+	for _, d := range data {
+		columns := d.columns()
+		cpuAverage, memoryAverage := d.formattedAverages()
+		var row string
+		if opts.ShowNamespace {
+			row = fmt.Sprintf("%-*s  ", namespaceWidth, d.Namespace)
+		}
+		row += fmt.Sprintf("%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s",
+			kindWidth, d.Kind,
+			nameWidth, d.Name,
+			podsWidth, strconv.Itoa(d.Pods),
+			cpuWidth, columns.CPUUsage,
+			cpuWidth, cpuAverage,
+			cpuWidth, columns.CPURequest,
+			cpuWidth, columns.CPULimit,
+			memWidth, columns.MemoryUsage,
+			memWidth, memoryAverage,
+			memWidth, columns.MemoryRequest,
+			memWidth, columns.MemoryLimit,
+		)
+		if opts.Wide {
+			cpuRequestPercent, cpuLimitPercent, memoryRequestPercent, memoryLimitPercent := d.utilization()
+			row += fmt.Sprintf("  %-*s  %-*s  %-*s  %-*s",
+				percentWidth, cpuRequestPercent,
+				percentWidth, cpuLimitPercent,
+				percentWidth, memoryRequestPercent,
+				percentWidth, memoryLimitPercent,
+			)
+		}
+		fmt.Println(row)
+	}
+}
+
+// sortWorkloadData sorts the combined workload data based on the sortBy field.
+// Ties and unknown values keep the default namespace/kind/name order.
+func sortWorkloadData(data []CombinedWorkloadData, sortBy string) {
+	sort.Slice(data, func(i, j int) bool {
+		if data[i].Namespace != data[j].Namespace {
+			return data[i].Namespace < data[j].Namespace
+		}
+		if data[i].Kind != data[j].Kind {
+			return data[i].Kind < data[j].Kind
+		}
+		return data[i].Name < data[j].Name
+	})
+
+	field, descending := parseSortBy(sortBy)
+	switch field {
+	case "namespace":
+		if descending {
+			sort.SliceStable(data, func(i, j int) bool { return data[i].Namespace > data[j].Namespace })
+		}
+	case "name":
+		sort.SliceStable(data, func(i, j int) bool {
+			if descending {
+				return data[i].Name > data[j].Name
+			}
+			return data[i].Name < data[j].Name
+		})
+	default:
+		if value, ok := workloadSortValues[field]; ok {
+			sortByValue(data, value, descending)
+		}
+	}
+}
+
+// NewWorkloadCommand creates a new workload command
+func NewWorkloadCommand() *cobra.Command {
+	factory := newClientFactory()
+	var opts WorkloadOptions
+	var sourceOpts metricsSourceOptions
+	var allNamespaces bool
+
+	cmd := &cobra.Command{
+		Use:     "workload [NAME | KIND/NAME | -l label]",
+		Aliases: []string{"workloads", "wl"},
+		Short:   "Display resource usage (CPU, memory) and requests/limits aggregated per workload",
+		Long: `Display resource usage (CPU, memory) and requests/limits aggregated per workload.
+Pods are grouped by the controller in their ownerReferences: Deployments (through their
+ReplicaSets), StatefulSets, DaemonSets, Jobs and CronJobs (through their Jobs). Pods without
+a controller are listed on their own with kind Pod.
+
+Each row shows the number of pods, the total and average usage per pod, and the total
+requests and limits.
+
+Examples:
+  # Show usage per workload in the default namespace
+  kubectl rltop workload
+
+  # Show usage per workload across all namespaces, highest CPU first
+  kubectl rltop workload -A --sort-by=cpu
+
+  # Show a given deployment
+  kubectl rltop workload deployment/NAME
+
+  # Show usage as a percentage of requests and limits
+  kubectl rltop workload -o wide`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(opts.Output, workloadOutputFormats); err != nil {
+				return err
+			}
+			if err := sourceOpts.validate(); err != nil {
+				return err
+			}
+			if err := validateWatchInterval(opts.Watch, opts.Interval); err != nil {
+				return err
+			}
+			if err := validateSortBy(opts.SortBy, workloadSortFields); err != nil {
+				return err
+			}
+
+			// Extract workload names from args
+			if len(args) > 0 {
+				opts.WorkloadNames = args
+			}
+
+			// Get namespace from context if not specified
+			if !allNamespaces && opts.Namespace == "" {
+				opts.Namespace = factory.Namespace()
+			}
+
+			// Handle -A/--all-namespaces flag (must be after namespace detection)
+			if allNamespaces {
+				opts.Namespace = ""
+			}
+
+			clientset, metricsClient, err := factory.Clients()
+			if err != nil {
+				return err
+			}
+			source := sourceOpts.newSource(clientset, metricsClient, pkg.UsageWindow{})
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			return RunWorkload(ctx, clientset, source, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace to query (default: namespace from current context, or 'default')")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false,
+		"If present, list the requested object(s) across all namespaces. "+
+			"Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().StringVarP(&opts.LabelSelector, "selector", "l", "",
+		"Selector (label query) on the pods to aggregate, supports '=', '==', and '!='.(e.g. -l key1=value1)")
+	cmd.Flags().StringVar(&opts.SortBy, "sort-by", "",
+		"If non-empty, sort workloads list using specified field. One of: "+strings.Join(workloadSortFields, ", ")+". "+
+			"Usage, requests and limits are totals over the pods of the workload. "+
+			"Append ':asc' or ':desc' to choose the direction (numeric fields default to descending, names to ascending).")
+	cmd.Flags().BoolVar(&opts.NoHeaders, "no-headers", false,
+		"If present, print output without headers.")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "",
+		"Output format. One of: (json, yaml, wide). "+
+			"'wide' adds CPU%REQ, CPU%LIM, MEM%REQ and MEM%LIM columns comparing usage with requests and limits.")
+	addWatchFlags(cmd, &opts.Watch, &opts.Interval)
+	addMetricsSourceFlags(cmd, &sourceOpts)
+	factory.AddFlags(cmd.Flags())
+
+	return cmd
+}
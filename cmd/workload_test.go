@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/veditoid/kubectl-rltop/pkg"
+)
+
+func TestCombineWorkloads(t *testing.T) {
+	pods := []CombinedPodData{
+		{Namespace: "default", Name: "web-1", HasMetrics: true, CPUUsageMilli: 100, MemoryUsageBytes: 100 << 20,
+			CPURequestMilli: 200, MemoryRequestBytes: 256 << 20},
+		{Namespace: "default", Name: "web-2", HasMetrics: true, CPUUsageMilli: 300, MemoryUsageBytes: 300 << 20,
+			CPURequestMilli: 200, MemoryRequestBytes: 256 << 20},
+		{Namespace: "default", Name: "web-3", CPURequestMilli: 200, MemoryRequestBytes: 256 << 20},
+		{Namespace: "other", Name: "web-1", CPURequestMilli: 50},
+		{Namespace: "default", Name: "stray", HasMetrics: true, CPUUsageMilli: 10},
+		// An evicted pod of web, and a completed Job kept in the history of a CronJob
+		{Namespace: "default", Name: "web-0", Terminated: true, CPURequestMilli: 200},
+		{Namespace: "default", Name: "report-1", Terminated: true, CPURequestMilli: 1000},
+	}
+	workloads := map[string]pkg.Workload{
+		"default/web-0":    {Kind: "Deployment", Name: "web"},
+		"default/report-1": {Kind: "CronJob", Name: "report"},
+		"default/web-1":    {Kind: "Deployment", Name: "web"},
+		"default/web-2":    {Kind: "Deployment", Name: "web"},
+		"default/web-3":    {Kind: "Deployment", Name: "web"},
+		"other/web-1":      {Kind: "Deployment", Name: "web"},
+	}
+
+	combined := combineWorkloads(pods, workloads)
+	sortWorkloadData(combined, "")
+
+	if len(combined) != 3 {
+		t.Fatalf("combineWorkloads() returned %d workloads, want 3: %+v", len(combined), combined)
+	}
+
+	// Sorted by namespace, kind, then name
+	web := combined[0]
+	if web.Namespace != "default" || web.Name != "web" || web.Pods != 3 || web.PodsWithMetrics != 2 {
+		t.Errorf("combineWorkloads() web = %+v", web)
+	}
+	if web.CPUUsageMilli != 400 || web.CPURequestMilli != 600 || web.MemoryRequestBytes != 768<<20 {
+		t.Errorf("combineWorkloads() web totals = %+v", web)
+	}
+	if cpu, memory, ok := web.averages(); !ok || cpu != 200 || memory != 200<<20 {
+		t.Errorf("averages() = %d, %d, %v, want 200, %d, true", cpu, memory, ok, 200<<20)
+	}
+	// web-3 has no metrics: its request counts in the totals but not in the utilization
+	if cpu, _, memory, _ := web.utilization(); cpu != "100%" || memory != "78%" {
+		t.Errorf("utilization() = %s, %s, want 100%%, 78%% of the requests of the pods with metrics", cpu, memory)
+	}
+
+	stray := combined[1]
+	if stray.Kind != pkg.KindPod || stray.Name != "stray" || stray.Pods != 1 {
+		t.Errorf("combineWorkloads() pod without workload = %+v", stray)
+	}
+
+	if other := combined[2]; other.Namespace != "other" || other.HasMetrics {
+		t.Errorf("combineWorkloads() workloads in other namespaces should be separate: %+v", other)
+	}
+}
+
+func TestFilterWorkloads(t *testing.T) {
+	data := []CombinedWorkloadData{
+		{Kind: "Deployment", podGroup: podGroup{CombinedPodData: CombinedPodData{Name: "web"}}},
+		{Kind: "StatefulSet", podGroup: podGroup{CombinedPodData: CombinedPodData{Name: "web"}}},
+		{Kind: "StatefulSet", podGroup: podGroup{CombinedPodData: CombinedPodData{Name: "db"}}},
+	}
+
+	tests := []struct {
+		names []string
+		want  int
+	}{
+		{nil, 3},
+		{[]string{"web"}, 2},
+		{[]string{"deployment/web"}, 1},
+		{[]string{"StatefulSet/web", "db"}, 2},
+		{[]string{"daemonset/web"}, 0},
+	}
+	for _, tt := range tests {
+		if got := filterWorkloads(data, tt.names); len(got) != tt.want {
+			t.Errorf("filterWorkloads(%v) returned %d workloads, want %d", tt.names, len(got), tt.want)
+		}
+	}
+}
+
+func TestSortWorkloadData(t *testing.T) {
+	workload := func(name string, hasMetrics bool, cpu int64, pods int) CombinedWorkloadData {
+		return CombinedWorkloadData{Kind: "Deployment", podGroup: podGroup{
+			CombinedPodData: CombinedPodData{Name: name, HasMetrics: hasMetrics, CPUUsageMilli: cpu},
+			Pods:            pods,
+		}}
+	}
+	data := []CombinedWorkloadData{workload("a", true, 100, 1), workload("b", false, 0, 5), workload("c", true, 900, 2)}
+
+	sortWorkloadData(data, "pods")
+	if got := data[0].Name + data[1].Name + data[2].Name; got != "bca" {
+		t.Errorf("sortWorkloadData(pods) order = %s, want bca", got)
+	}
+
+	sortWorkloadData(data, "cpu")
+	if got := data[0].Name + data[1].Name + data[2].Name; got != "cab" {
+		t.Errorf("sortWorkloadData(cpu) order = %s, want cab", got)
+	}
+
+	// c requests more in total, but its measured pods use most of what they request
+	data[0].CPURequestMilli, data[0].MeasuredCPURequestMilli = 3000, 1000
+	data[1].CPURequestMilli, data[1].MeasuredCPURequestMilli = 200, 200
+	sortWorkloadData(data, "cpu-util")
+	if got := data[0].Name + data[1].Name + data[2].Name; got != "cab" {
+		t.Errorf("sortWorkloadData(cpu-util) order = %s, want cab", got)
+	}
+}
+
+func TestPrintWorkloadTable(t *testing.T) {
+	data := []CombinedWorkloadData{
+		{
+			Kind: "Deployment",
+			podGroup: podGroup{
+				CombinedPodData: CombinedPodData{
+					Namespace: "default", Name: "web", HasMetrics: true, CPUUsageMilli: 300, CPURequestMilli: 600,
+				},
+				Pods:            3,
+				PodsWithMetrics: 3,
+			},
+		},
+	}
+
+	output := captureStdout(t, func() {
+		printWorkloadTable(data, workloadTableOptions{})
+	})
+
+	for _, col := range []string{"KIND", "PODS", "CPU AVG", "MEMORY AVG"} {
+		if !strings.Contains(output, col) {
+			t.Errorf("printWorkloadTable() output missing column %s. Output: %s", col, output)
+		}
+	}
+	if strings.Contains(output, "NAMESPACE") {
+		t.Errorf("printWorkloadTable() output should not have a NAMESPACE column. Output: %s", output)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	want := []string{"Deployment", "web", "3", "300m", "100m", "600m"}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("printWorkloadTable() row = %v, want prefix %v", fields, want)
+			break
+		}
+	}
+}
+
+func TestNewWorkloadUsageList(t *testing.T) {
+	data := []CombinedWorkloadData{
+		{
+			Kind: "StatefulSet",
+			podGroup: podGroup{
+				CombinedPodData: CombinedPodData{
+					Name: "db", HasMetrics: true, CPUUsageMilli: 400, CPURequestMilli: 1200,
+				},
+				Pods:                    3,
+				PodsWithMetrics:         2,
+				MeasuredCPURequestMilli: 800,
+			},
+		},
+		{Kind: "Job", podGroup: podGroup{CombinedPodData: CombinedPodData{Name: "backup"}, Pods: 1}},
+	}
+
+	list := newWorkloadUsageList(data)
+	if list.Kind != "WorkloadUsageList" || len(list.Items) != 2 {
+		t.Fatalf("newWorkloadUsageList() = %+v", list)
+	}
+	db := list.Items[0]
+	if db.Kind != "StatefulSet" || db.Pods != 3 || db.CPU.Usage == nil || db.CPU.Usage.Millicores != 400 {
+		t.Errorf("newWorkloadUsageList() db = %+v", db)
+	}
+	if db.CPU.Average == nil || db.CPU.Average.Millicores != 200 {
+		t.Errorf("newWorkloadUsageList() db CPU average = %+v", db.CPU.Average)
+	}
+	if db.CPU.Request == nil || db.CPU.Request.Millicores != 1200 {
+		t.Errorf("newWorkloadUsageList() db CPU request = %+v, want the total of every pod", db.CPU.Request)
+	}
+	if u := db.CPU.RequestUtilization; u == nil || u.Percent != 50 {
+		t.Errorf("newWorkloadUsageList() db CPU request utilization = %+v, want 50%% of the measured pods", u)
+	}
+	if backup := list.Items[1]; backup.CPU.Average != nil || backup.Memory.Average != nil {
+		t.Errorf("newWorkloadUsageList() averages should be omitted without metrics: %+v", backup)
+	}
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/veditoid/kubectl-rltop/cmd"
@@ -18,18 +21,25 @@ var (
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "kubectl-rltop",
-		Short: "Display resource usage with requests and limits for pods and nodes",
+		Short: "Display resource usage with requests and limits for pods, nodes, workloads and namespaces",
 		Long: `kubectl-rltop is a kubectl plugin that displays resource usage (CPU and memory)
-along with resource requests and limits for pods and nodes.
+along with resource requests and limits for pods, nodes, workloads and namespaces.
 
 It works like 'kubectl top pods' and 'kubectl top nodes' but also shows the resource
 requests and limits defined in pod specifications.
 
 Usage:
-  kubectl rltop pod [flags]    # Display pod resource usage with requests/limits
-  kubectl rltop node [flags]   # Display node resource usage with aggregated requests/limits
-  kubectl rltop pods [flags]   # Alias for pod
-  kubectl rltop nodes [flags]  # Alias for node`,
+  kubectl rltop pod [flags]       # Display pod resource usage with requests/limits
+  kubectl rltop node [flags]      # Display node resource usage with aggregated requests/limits
+  kubectl rltop workload [flags]  # Display pod resource usage aggregated per workload
+  kubectl rltop ns [flags]        # Display pod resource usage aggregated per namespace
+  kubectl rltop quota [flags]     # Display the CPU and memory ResourceQuota left per namespace
+  kubectl rltop recommend [flags] # Propose requests and limits from sampled usage
+  kubectl rltop record [flags]    # Record snapshots of pod and node usage to a file
+  kubectl rltop diff A [B]        # Compare two recorded snapshots, or a snapshot and the cluster
+  kubectl rltop serve [flags]     # Expose usage, requests and limits as Prometheus metrics
+  kubectl rltop pods [flags]      # Alias for pod
+  kubectl rltop nodes [flags]     # Alias for node`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
@@ -51,9 +61,27 @@ Usage:
 	rootCmd.AddCommand(cmd.NewPodCommand())
 	// Add the node subcommand (with aliases: nodes, no)
 	rootCmd.AddCommand(cmd.NewNodeCommand())
+	// Add the workload subcommand (with aliases: workloads, wl)
+	rootCmd.AddCommand(cmd.NewWorkloadCommand())
+	// Add the namespace subcommand (with aliases: namespaces, ns)
+	rootCmd.AddCommand(cmd.NewNamespaceCommand())
+	// Add the quota subcommand (with aliases: quotas, resourcequota)
+	rootCmd.AddCommand(cmd.NewQuotaCommand())
+	// Add the recommend subcommand (with aliases: recommendations, rec)
+	rootCmd.AddCommand(cmd.NewRecommendCommand())
+	// Add the record subcommand
+	rootCmd.AddCommand(cmd.NewRecordCommand())
+	// Add the diff subcommand
+	rootCmd.AddCommand(cmd.NewDiffCommand())
+	// Add the serve subcommand
+	rootCmd.AddCommand(cmd.NewServeCommand())
 	rootCmd.AddCommand(versionCmd)
 
-	if err := rootCmd.Execute(); err != nil {
+	// Cancel the command context on Ctrl-C so long-running modes like --watch exit cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// PodEffectiveRequests returns the CPU and memory the scheduler reserves for a pod:
+// the larger of the app containers (plus native sidecars) and the largest init container step,
+// plus the RuntimeClass pod overhead. Container resources in force after an in-place resize
+// (from the pod status) are preferred over the spec.
+func PodEffectiveRequests(pod *corev1.Pod) corev1.ResourceList {
+	statuses := containerStatuses(pod)
+	requests := podEffectiveResources(pod, func(c *corev1.Container) corev1.ResourceList {
+		inForce, _ := containerResourcesInForce(c, statuses[c.Name])
+		return inForce
+	})
+	addResourceList(requests, pod.Spec.Overhead)
+	return requests
+}
+
+// PodEffectiveLimits returns the effective CPU and memory limits of a pod, computed like
+// PodEffectiveRequests. Pod overhead is only added to resources that have a limit.
+func PodEffectiveLimits(pod *corev1.Pod) corev1.ResourceList {
+	statuses := containerStatuses(pod)
+	limits := podEffectiveResources(pod, func(c *corev1.Container) corev1.ResourceList {
+		_, inForce := containerResourcesInForce(c, statuses[c.Name])
+		return inForce
+	})
+	for name, overhead := range pod.Spec.Overhead {
+		if q, ok := limits[name]; ok && !q.IsZero() {
+			q.Add(overhead)
+			limits[name] = q
+		}
+	}
+	return limits
+}
+
+// IsSidecarContainer reports whether an init container is a native sidecar (restartPolicy: Always),
+// which keeps running next to the app containers
+func IsSidecarContainer(container *corev1.Container) bool {
+	return container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways
+}
+
+// podEffectiveResources implements the scheduler's formula for requests (or limits), see
+// k8s.io/component-helpers/resource.PodRequests:
+//
+//	max(sum(app containers) + sum(sidecars), max over init containers i of (init[i] + sidecars started before i))
+func podEffectiveResources(pod *corev1.Pod, get func(*corev1.Container) corev1.ResourceList) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for i := range pod.Spec.Containers {
+		addResourceList(result, cpuAndMemory(get(&pod.Spec.Containers[i])))
+	}
+
+	sidecars := corev1.ResourceList{}
+	initMax := corev1.ResourceList{}
+	for i := range pod.Spec.InitContainers {
+		container := &pod.Spec.InitContainers[i]
+		resources := cpuAndMemory(get(container))
+
+		step := corev1.ResourceList{}
+		if IsSidecarContainer(container) {
+			// Sidecars run for the whole life of the pod, next to later init containers and the app containers
+			addResourceList(result, resources)
+			addResourceList(sidecars, resources)
+			addResourceList(step, sidecars)
+		} else {
+			// A regular init container runs alone, next to the sidecars started before it
+			addResourceList(step, resources)
+			addResourceList(step, sidecars)
+		}
+		maxResourceList(initMax, step)
+	}
+
+	maxResourceList(result, initMax)
+	return result
+}
+
+// cpuAndMemory returns the CPU and memory entries of a resource list
+func cpuAndMemory(list corev1.ResourceList) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if q, ok := list[name]; ok {
+			result[name] = q.DeepCopy()
+		}
+	}
+	return result
+}
+
+// addResourceList adds every quantity of src to dst
+func addResourceList(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		if existing, ok := dst[name]; ok {
+			existing.Add(q)
+			dst[name] = existing
+		} else {
+			dst[name] = q.DeepCopy()
+		}
+	}
+}
+
+// maxResourceList sets every quantity of dst to the larger of itself and the same quantity in src
+func maxResourceList(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		if existing, ok := dst[name]; !ok || q.Cmp(existing) > 0 {
+			dst[name] = q.DeepCopy()
+		}
+	}
+}
+
+// quantity returns the named quantity of a resource list, or zero if it is not set
+func quantity(list corev1.ResourceList, name corev1.ResourceName) resource.Quantity {
+	if q, ok := list[name]; ok {
+		return q
+	}
+	return resource.Quantity{}
+}
@@ -0,0 +1,145 @@
+package pkg
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// testContainer returns a container with the given CPU request and limit ("" leaves it unset)
+func testContainer(name, cpuRequest, cpuLimit string, sidecar bool) corev1.Container {
+	container := corev1.Container{
+		Name: name,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{},
+			Limits:   corev1.ResourceList{},
+		},
+	}
+	if cpuRequest != "" {
+		container.Resources.Requests[corev1.ResourceCPU] = resource.MustParse(cpuRequest)
+	}
+	if cpuLimit != "" {
+		container.Resources.Limits[corev1.ResourceCPU] = resource.MustParse(cpuLimit)
+	}
+	if sidecar {
+		always := corev1.ContainerRestartPolicyAlways
+		container.RestartPolicy = &always
+	}
+	return container
+}
+
+func TestPodEffectiveResources(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        corev1.PodSpec
+		wantRequest string
+		wantLimit   string
+	}{
+		{
+			name: "app containers are summed",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					testContainer("app", "100m", "200m", false),
+					testContainer("proxy", "50m", "", false),
+				},
+			},
+			wantRequest: "150m",
+			wantLimit:   "200m",
+		},
+		{
+			name: "largest init container wins over the app containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					testContainer("migrate", "500m", "1", false),
+					testContainer("warmup", "200m", "300m", false),
+				},
+				Containers: []corev1.Container{
+					testContainer("app", "100m", "200m", false),
+					testContainer("proxy", "100m", "200m", false),
+				},
+			},
+			wantRequest: "500m",
+			wantLimit:   "1",
+		},
+		{
+			name: "init containers smaller than the app containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					testContainer("migrate", "150m", "300m", false),
+				},
+				Containers: []corev1.Container{
+					testContainer("app", "100m", "200m", false),
+					testContainer("proxy", "100m", "200m", false),
+				},
+			},
+			wantRequest: "200m",
+			wantLimit:   "400m",
+		},
+		{
+			name: "sidecars are added to the app containers and to later init containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					testContainer("mesh", "100m", "100m", true),
+					testContainer("migrate", "800m", "", false),
+				},
+				Containers: []corev1.Container{
+					testContainer("app", "200m", "500m", false),
+				},
+			},
+			// max(200m + 100m, 800m + 100m)
+			wantRequest: "900m",
+			// max(500m + 100m, 0 + 100m)
+			wantLimit: "600m",
+		},
+		{
+			name: "overhead is added to requests and to set limits",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					testContainer("app", "100m", "200m", false),
+				},
+				Overhead: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("250m"),
+					corev1.ResourceMemory: resource.MustParse("120Mi"),
+				},
+			},
+			wantRequest: "350m",
+			wantLimit:   "450m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: tt.spec}
+
+			requests := PodEffectiveRequests(pod)
+			if got := requests[corev1.ResourceCPU]; got.Cmp(resource.MustParse(tt.wantRequest)) != 0 {
+				t.Errorf("PodEffectiveRequests() cpu = %v, want %v", got.String(), tt.wantRequest)
+			}
+
+			limits := PodEffectiveLimits(pod)
+			if got := limits[corev1.ResourceCPU]; got.Cmp(resource.MustParse(tt.wantLimit)) != 0 {
+				t.Errorf("PodEffectiveLimits() cpu = %v, want %v", got.String(), tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestPodEffectiveLimitsOverheadWithoutLimit(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{testContainer("app", "100m", "200m", false)},
+			Overhead: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("120Mi"),
+			},
+		},
+	}
+
+	// No container sets a memory limit, so the overhead must not create one
+	if memory, ok := PodEffectiveLimits(pod)[corev1.ResourceMemory]; ok && !memory.IsZero() {
+		t.Errorf("PodEffectiveLimits() memory = %v, want unset", memory.String())
+	}
+	if memory := PodEffectiveRequests(pod)[corev1.ResourceMemory]; memory.Cmp(resource.MustParse("120Mi")) != 0 {
+		t.Errorf("PodEffectiveRequests() memory = %v, want 120Mi", memory.String())
+	}
+}
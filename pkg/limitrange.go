@@ -0,0 +1,68 @@
+package pkg
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LimitRangerAnnotation is set by the LimitRanger admission plugin on the pods it applied LimitRange defaults to,
+// e.g. "LimitRanger plugin set: cpu, memory request for container app; cpu limit for init container setup"
+const LimitRangerAnnotation = "kubernetes.io/limit-ranger"
+
+// limitRangerPrefix starts the value of the LimitRangerAnnotation
+const limitRangerPrefix = "LimitRanger plugin set: "
+
+// LimitRangeDefaults returns, by container name, the CPU and memory requests and limits a LimitRange
+// defaulted at admission instead of the pod author, as quota resource names (requests.cpu, limits.memory, ...).
+// It is parsed from the LimitRangerAnnotation and is empty when the author set every value.
+func LimitRangeDefaults(pod *corev1.Pod) map[string][]string {
+	annotation, ok := pod.Annotations[LimitRangerAnnotation]
+	if !ok {
+		return nil
+	}
+
+	defaults := make(map[string][]string)
+	for _, entry := range strings.Split(strings.TrimPrefix(annotation, limitRangerPrefix), ";") {
+		// "<resources> request for [init ]container <name>"
+		setResources, target, found := strings.Cut(strings.TrimSpace(entry), " for ")
+		if !found {
+			continue
+		}
+		var kind string
+		switch {
+		case strings.HasSuffix(setResources, " request"):
+			kind, setResources = "requests.", strings.TrimSuffix(setResources, " request")
+		case strings.HasSuffix(setResources, " limit"):
+			kind, setResources = "limits.", strings.TrimSuffix(setResources, " limit")
+		default:
+			continue
+		}
+		container := strings.TrimPrefix(strings.TrimPrefix(target, "init "), "container ")
+
+		for _, name := range strings.Split(setResources, ",") {
+			name = strings.TrimSpace(name)
+			if name == string(corev1.ResourceCPU) || name == string(corev1.ResourceMemory) {
+				defaults[container] = append(defaults[container], kind+name)
+			}
+		}
+	}
+	return defaults
+}
+
+// mergedLimitRangeDefaults returns the sorted union of the defaulted resources of all containers
+func mergedLimitRangeDefaults(defaults map[string][]string) []string {
+	seen := make(map[string]bool)
+	var merged []string
+	for _, names := range defaults {
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				merged = append(merged, name)
+			}
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
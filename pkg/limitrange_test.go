@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLimitRangeDefaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       map[string][]string
+	}{
+		{
+			name: "no annotation",
+			want: nil,
+		},
+		{
+			name: "requests and limits for several containers",
+			annotation: "LimitRanger plugin set: cpu, memory request for container app; cpu limit for container app; " +
+				"memory request for init container setup",
+			want: map[string][]string{
+				"app":   {"requests.cpu", "requests.memory", "limits.cpu"},
+				"setup": {"requests.memory"},
+			},
+		},
+		{
+			name:       "other resources are ignored",
+			annotation: "LimitRanger plugin set: ephemeral-storage, memory limit for container app",
+			want:       map[string][]string{"app": {"limits.memory"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{}
+			if tt.annotation != "" {
+				pod.ObjectMeta = metav1.ObjectMeta{Annotations: map[string]string{LimitRangerAnnotation: tt.annotation}}
+			}
+			got := LimitRangeDefaults(pod)
+			if len(got) != len(tt.want) {
+				t.Fatalf("LimitRangeDefaults() = %v, want %v", got, tt.want)
+			}
+			for container, want := range tt.want {
+				if !reflect.DeepEqual(got[container], want) {
+					t.Errorf("LimitRangeDefaults()[%s] = %v, want %v", container, got[container], want)
+				}
+			}
+		})
+	}
+}
+
+func TestMergedLimitRangeDefaults(t *testing.T) {
+	got := mergedLimitRangeDefaults(map[string][]string{
+		"app":     {"requests.cpu", "limits.cpu"},
+		"sidecar": {"requests.cpu", "requests.memory"},
+	})
+	want := []string{"limits.cpu", "requests.cpu", "requests.memory"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergedLimitRangeDefaults() = %v, want %v", got, want)
+	}
+}
@@ -12,10 +12,18 @@ import (
 
 // PodMetrics represents CPU and memory usage for a pod
 type PodMetrics struct {
-	Name      string
-	Namespace string
-	CPU       string
-	Memory    string
+	Name        string             `json:"name"`
+	Namespace   string             `json:"namespace"`
+	CPUMilli    int64              `json:"cpuMilli"`    // CPU usage in millicores
+	MemoryBytes int64              `json:"memoryBytes"` // Memory usage in bytes
+	Containers  []ContainerMetrics `json:"containers"`
+}
+
+// ContainerMetrics represents CPU and memory usage for a single container in a pod
+type ContainerMetrics struct {
+	Name        string `json:"name"`
+	CPUMilli    int64  `json:"cpuMilli"`
+	MemoryBytes int64  `json:"memoryBytes"`
 }
 
 // GetPodMetrics fetches pod metrics from the Metrics API
@@ -62,16 +70,25 @@ func GetPodMetrics(
 	metrics := make([]PodMetrics, 0, len(podMetricsList.Items))
 	for _, pm := range podMetricsList.Items {
 		var totalCPU, totalMemory int64
+		containers := make([]ContainerMetrics, 0, len(pm.Containers))
 		for _, container := range pm.Containers {
-			totalCPU += container.Usage.Cpu().MilliValue()
-			totalMemory += container.Usage.Memory().Value()
+			cpu := container.Usage.Cpu().MilliValue()
+			memory := container.Usage.Memory().Value()
+			totalCPU += cpu
+			totalMemory += memory
+			containers = append(containers, ContainerMetrics{
+				Name:        container.Name,
+				CPUMilli:    cpu,
+				MemoryBytes: memory,
+			})
 		}
 
 		metrics = append(metrics, PodMetrics{
-			Name:      pm.Name,
-			Namespace: pm.Namespace,
-			CPU:       formatCPU(totalCPU),
-			Memory:    formatMemory(totalMemory),
+			Name:        pm.Name,
+			Namespace:   pm.Namespace,
+			CPUMilli:    totalCPU,
+			MemoryBytes: totalMemory,
+			Containers:  containers,
 		})
 	}
 
@@ -95,8 +112,8 @@ func CheckMetricsAPIAvailable(ctx context.Context, clientset kubernetes.Interfac
 	return fmt.Errorf("metrics API (metrics.k8s.io) not available in the cluster")
 }
 
-// formatCPU formats CPU value in millicores to a human-readable string
-func formatCPU(millicores int64) string {
+// FormatCPU formats CPU value in millicores to a human-readable string
+func FormatCPU(millicores int64) string {
 	if millicores == 0 {
 		return "0"
 	}
@@ -110,8 +127,8 @@ func formatCPU(millicores int64) string {
 	return fmt.Sprintf("%.2f", cores)
 }
 
-// formatMemory formats memory value in bytes to a human-readable string
-func formatMemory(bytes int64) string {
+// FormatMemory formats memory value in bytes to a human-readable string
+func FormatMemory(bytes int64) string {
 	if bytes == 0 {
 		return "0"
 	}
@@ -132,4 +149,3 @@ func formatMemory(bytes int64) string {
 		return fmt.Sprintf("%dB", bytes)
 	}
 }
-
@@ -19,9 +19,9 @@ func TestFormatCPU(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := formatCPU(tt.millicores)
+			got := FormatCPU(tt.millicores)
 			if got != tt.want {
-				t.Errorf("formatCPU(%d) = %v, want %v", tt.millicores, got, tt.want)
+				t.Errorf("FormatCPU(%d) = %v, want %v", tt.millicores, got, tt.want)
 			}
 		})
 	}
@@ -42,9 +42,9 @@ func TestFormatMemory(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := formatMemory(tt.bytes)
+			got := FormatMemory(tt.bytes)
 			if got != tt.want {
-				t.Errorf("formatMemory(%d) = %v, want %v", tt.bytes, got, tt.want)
+				t.Errorf("FormatMemory(%d) = %v, want %v", tt.bytes, got, tt.want)
 			}
 		})
 	}
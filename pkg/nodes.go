@@ -5,8 +5,8 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
@@ -14,20 +14,18 @@ import (
 
 // NodeMetrics represents CPU and memory usage for a node
 type NodeMetrics struct {
-	Name      string
-	CPU       string
-	CPUPercent string
-	Memory    string
-	MemoryPercent string
+	Name        string `json:"name"`
+	CPUMilli    int64  `json:"cpuMilli"`    // CPU usage in millicores
+	MemoryBytes int64  `json:"memoryBytes"` // Memory usage in bytes
 }
 
 // NodeAggregatedResources represents aggregated resource requests and limits for all pods on a node
 type NodeAggregatedResources struct {
-	NodeName       string
-	CPURequest     resource.Quantity
-	CPULimit       resource.Quantity
-	MemoryRequest  resource.Quantity
-	MemoryLimit    resource.Quantity
+	NodeName      string            `json:"nodeName"`
+	CPURequest    resource.Quantity `json:"cpuRequest"`
+	CPULimit      resource.Quantity `json:"cpuLimit"`
+	MemoryRequest resource.Quantity `json:"memoryRequest"`
+	MemoryLimit   resource.Quantity `json:"memoryLimit"`
 }
 
 // GetNodeMetrics fetches node metrics from the Metrics API
@@ -65,10 +63,9 @@ func GetNodeMetrics(
 		memoryUsage := nm.Usage.Memory().Value()
 
 		metrics = append(metrics, NodeMetrics{
-			Name:   nm.Name,
-			CPU:    formatCPU(cpuUsage),
-			Memory: formatMemory(memoryUsage),
-			// CPUPercent and MemoryPercent will be calculated later when we have node capacity/allocatable
+			Name:        nm.Name,
+			CPUMilli:    cpuUsage,
+			MemoryBytes: memoryUsage,
 		})
 	}
 
@@ -114,13 +111,34 @@ func GetNodeResources(
 	return nodesMap, nil
 }
 
-// AggregatePodResourcesByNode groups pods by node and aggregates their resource requests and limits
+// nonTerminatedPodsSelector matches the pods 'kubectl describe node' and the scheduler count against a node
+const nonTerminatedPodsSelector = "status.phase!=Succeeded,status.phase!=Failed"
+
+// NodePodsSelector returns the field selector of the pods scheduled on a node.
+// Terminated pods are left out unless includeTerminated is set, like in the node totals.
+func NodePodsSelector(nodeName string, includeTerminated bool) string {
+	selector := "spec.nodeName=" + nodeName
+	if !includeTerminated {
+		selector += "," + nonTerminatedPodsSelector
+	}
+	return selector
+}
+
+// AggregatePodResourcesByNode groups pods by node and aggregates their resource requests and limits.
+// Terminated pods (Succeeded or Failed phase, e.g. completed Jobs and evicted pods) no longer hold
+// resources and are skipped unless includeTerminated is set.
 func AggregatePodResourcesByNode(
 	ctx context.Context,
 	clientset kubernetes.Interface,
+	includeTerminated bool,
 ) (map[string]*NodeAggregatedResources, error) {
+	listOptions := metav1.ListOptions{}
+	if !includeTerminated {
+		listOptions.FieldSelector = nonTerminatedPodsSelector
+	}
+
 	// Get all pods across all namespaces
-	podList, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	podList, err := clientset.CoreV1().Pods("").List(ctx, listOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch pods: %w", err)
 	}
@@ -134,6 +152,11 @@ func AggregatePodResourcesByNode(
 			continue
 		}
 
+		// Check the phase here too, so the result doesn't depend on the server honoring the field selector
+		if !includeTerminated && IsPodTerminated(&pod) {
+			continue
+		}
+
 		// Initialize node entry if it doesn't exist
 		if nodeResources[pod.Spec.NodeName] == nil {
 			nodeResources[pod.Spec.NodeName] = &NodeAggregatedResources{
@@ -141,90 +164,54 @@ func AggregatePodResourcesByNode(
 			}
 		}
 
-		// Aggregate resources from all containers in the pod
-		for _, container := range pod.Spec.Containers {
-			if container.Resources.Requests != nil {
-				if cpu, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-					nodeResources[pod.Spec.NodeName].CPURequest.Add(cpu)
-				}
-				if memory, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
-					nodeResources[pod.Spec.NodeName].MemoryRequest.Add(memory)
-				}
-			}
-			if container.Resources.Limits != nil {
-				if cpu, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
-					nodeResources[pod.Spec.NodeName].CPULimit.Add(cpu)
-				}
-				if memory, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
-					nodeResources[pod.Spec.NodeName].MemoryLimit.Add(memory)
-				}
-			}
-		}
-
-		// Also check init containers (they can affect scheduling)
-		for _, container := range pod.Spec.InitContainers {
-			if container.Resources.Requests != nil {
-				if cpu, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-					// Init containers use max(request, initContainer request)
-					if cpu.Cmp(nodeResources[pod.Spec.NodeName].CPURequest) > 0 {
-						nodeResources[pod.Spec.NodeName].CPURequest = cpu
-					}
-				}
-				if memory, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
-					if memory.Cmp(nodeResources[pod.Spec.NodeName].MemoryRequest) > 0 {
-						nodeResources[pod.Spec.NodeName].MemoryRequest = memory
-					}
-				}
-			}
-		}
+		// Add what the scheduler reserves for the pod (init containers, sidecars and overhead included)
+		requests := PodEffectiveRequests(&pod)
+		limits := PodEffectiveLimits(&pod)
+		node := nodeResources[pod.Spec.NodeName]
+		node.CPURequest.Add(quantity(requests, corev1.ResourceCPU))
+		node.MemoryRequest.Add(quantity(requests, corev1.ResourceMemory))
+		node.CPULimit.Add(quantity(limits, corev1.ResourceCPU))
+		node.MemoryLimit.Add(quantity(limits, corev1.ResourceMemory))
 	}
 
 	return nodeResources, nil
 }
 
-// CalculateNodePercentages calculates CPU and memory percentages based on allocatable or capacity
-func CalculateNodePercentages(
-	node *corev1.Node,
-	cpuUsageMilli int64,
-	memoryUsageBytes int64,
-	showCapacity bool,
-) (cpuPercent, memoryPercent string) {
-	var cpuTotal, memoryTotal resource.Quantity
+// IsPodTerminated reports whether the pod has reached a terminal phase (Succeeded or Failed)
+func IsPodTerminated(pod *corev1.Pod) bool {
+	return IsTerminalPhase(pod.Status.Phase)
+}
+
+// IsTerminalPhase reports whether a pod phase is terminal (Succeeded or Failed)
+func IsTerminalPhase(phase corev1.PodPhase) bool {
+	return phase == corev1.PodSucceeded || phase == corev1.PodFailed
+}
 
+// NodeTotals returns the node's CPU (millicores) and memory (bytes) from allocatable, or capacity if showCapacity
+func NodeTotals(node *corev1.Node, showCapacity bool) (cpuMilli, memoryBytes int64) {
+	resources := node.Status.Allocatable
 	if showCapacity {
-		cpuTotal = node.Status.Capacity[corev1.ResourceCPU]
-		memoryTotal = node.Status.Capacity[corev1.ResourceMemory]
-	} else {
-		cpuTotal = node.Status.Allocatable[corev1.ResourceCPU]
-		memoryTotal = node.Status.Allocatable[corev1.ResourceMemory]
+		resources = node.Status.Capacity
 	}
+	return resources.Cpu().MilliValue(), resources.Memory().Value()
+}
 
-	// Calculate CPU percentage
-	if !cpuTotal.IsZero() {
-		cpuTotalMilli := cpuTotal.MilliValue()
-		if cpuTotalMilli > 0 {
-			percent := float64(cpuUsageMilli) / float64(cpuTotalMilli) * 100
-			cpuPercent = fmt.Sprintf("%.0f%%", percent)
-		} else {
-			cpuPercent = "0%"
+// NodeStatus returns the node status the way 'kubectl get nodes' shows it: Ready, NotReady or Unknown
+// from the Ready condition, with ",SchedulingDisabled" appended for cordoned nodes
+func NodeStatus(node *corev1.Node) string {
+	status := "Unknown"
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			if condition.Status == corev1.ConditionTrue {
+				status = "Ready"
+			} else if condition.Status == corev1.ConditionFalse {
+				status = "NotReady"
+			}
+			break
 		}
-	} else {
-		cpuPercent = "-"
 	}
-
-	// Calculate Memory percentage
-	if !memoryTotal.IsZero() {
-		memoryTotalBytes := memoryTotal.Value()
-		if memoryTotalBytes > 0 {
-			percent := float64(memoryUsageBytes) / float64(memoryTotalBytes) * 100
-			memoryPercent = fmt.Sprintf("%.0f%%", percent)
-		} else {
-			memoryPercent = "0%"
-		}
-	} else {
-		memoryPercent = "-"
+	if node.Spec.Unschedulable {
+		status += ",SchedulingDisabled"
 	}
-
-	return cpuPercent, memoryPercent
+	return status
 }
-
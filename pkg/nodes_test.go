@@ -10,7 +10,7 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 )
 
-func TestCalculateNodePercentages(t *testing.T) {
+func TestNodeTotalsPercentages(t *testing.T) {
 	tests := []struct {
 		name              string
 		node              *corev1.Node
@@ -115,13 +115,12 @@ func TestCalculateNodePercentages(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cpuPercent, memoryPercent := CalculateNodePercentages(
-				tt.node, tt.cpuUsageMilli, tt.memoryUsageBytes, tt.showCapacity)
-			if cpuPercent != tt.expectedCPU {
-				t.Errorf("CalculateNodePercentages() cpuPercent = %v, want %v", cpuPercent, tt.expectedCPU)
+			cpuTotal, memoryTotal := NodeTotals(tt.node, tt.showCapacity)
+			if cpuPercent := FormatUtilization(tt.cpuUsageMilli, cpuTotal); cpuPercent != tt.expectedCPU {
+				t.Errorf("NodeTotals() cpuPercent = %v, want %v", cpuPercent, tt.expectedCPU)
 			}
-			if memoryPercent != tt.expectedMemory {
-				t.Errorf("CalculateNodePercentages() memoryPercent = %v, want %v", memoryPercent, tt.expectedMemory)
+			if memoryPercent := FormatUtilization(tt.memoryUsageBytes, memoryTotal); memoryPercent != tt.expectedMemory {
+				t.Errorf("NodeTotals() memoryPercent = %v, want %v", memoryPercent, tt.expectedMemory)
 			}
 		})
 	}
@@ -130,11 +129,55 @@ func TestCalculateNodePercentages(t *testing.T) {
 func TestAggregatePodResourcesByNode(t *testing.T) {
 	ctx := context.Background()
 
+	terminatedPod := func(name string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName: "node1",
+				Containers: []corev1.Container{
+					{
+						Name: "container1",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+						},
+					},
+				},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	runningPod := terminatedPod("running", corev1.PodRunning)
+	runningPod.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("250m")
+
 	tests := []struct {
-		name     string
-		pods     []corev1.Pod
-		expected map[string]*NodeAggregatedResources
+		name              string
+		pods              []corev1.Pod
+		includeTerminated bool
+		expected          map[string]*NodeAggregatedResources
 	}{
+		{
+			name: "terminated pods are skipped",
+			pods: []corev1.Pod{
+				runningPod,
+				terminatedPod("job-done", corev1.PodSucceeded),
+				terminatedPod("evicted", corev1.PodFailed),
+			},
+			expected: map[string]*NodeAggregatedResources{
+				"node1": {NodeName: "node1", CPURequest: resource.MustParse("250m")},
+			},
+		},
+		{
+			name: "terminated pods are included on request",
+			pods: []corev1.Pod{
+				runningPod,
+				terminatedPod("job-done", corev1.PodSucceeded),
+				terminatedPod("evicted", corev1.PodFailed),
+			},
+			includeTerminated: true,
+			expected: map[string]*NodeAggregatedResources{
+				"node1": {NodeName: "node1", CPURequest: resource.MustParse("2250m")},
+			},
+		},
 		{
 			name: "single pod with requests and limits",
 			pods: []corev1.Pod{
@@ -356,6 +399,29 @@ func TestAggregatePodResourcesByNode(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "init containers are compared per pod, not with the node total",
+			pods: []corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+					Spec: corev1.PodSpec{
+						NodeName:   "node1",
+						Containers: []corev1.Container{testContainer("app", "300m", "", false)},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "default"},
+					Spec: corev1.PodSpec{
+						NodeName:       "node1",
+						InitContainers: []corev1.Container{testContainer("init", "400m", "", false)},
+						Containers:     []corev1.Container{testContainer("app", "100m", "", false)},
+					},
+				},
+			},
+			expected: map[string]*NodeAggregatedResources{
+				"node1": {NodeName: "node1", CPURequest: resource.MustParse("700m")},
+			},
+		},
 		{
 			name:     "empty pod list",
 			pods:     []corev1.Pod{},
@@ -373,7 +439,7 @@ func TestAggregatePodResourcesByNode(t *testing.T) {
 				}
 			}
 
-			result, err := AggregatePodResourcesByNode(ctx, clientset)
+			result, err := AggregatePodResourcesByNode(ctx, clientset, tt.includeTerminated)
 			if err != nil {
 				t.Fatalf("AggregatePodResourcesByNode() error = %v", err)
 			}
@@ -533,3 +599,49 @@ func TestGetNodeResources(t *testing.T) {
 // Note: GetNodeMetrics test is skipped here as it requires complex mocking of metricsclientset.Interface
 // It will be tested in integration tests instead
 
+
+func TestNodeStatus(t *testing.T) {
+	readyCondition := func(status corev1.ConditionStatus) []corev1.NodeCondition {
+		return []corev1.NodeCondition{
+			{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+			{Type: corev1.NodeReady, Status: status},
+		}
+	}
+
+	tests := []struct {
+		name string
+		node corev1.Node
+		want string
+	}{
+		{"ready", corev1.Node{Status: corev1.NodeStatus{Conditions: readyCondition(corev1.ConditionTrue)}}, "Ready"},
+		{"not ready", corev1.Node{Status: corev1.NodeStatus{Conditions: readyCondition(corev1.ConditionFalse)}}, "NotReady"},
+		{"unknown", corev1.Node{Status: corev1.NodeStatus{Conditions: readyCondition(corev1.ConditionUnknown)}}, "Unknown"},
+		{"no conditions", corev1.Node{}, "Unknown"},
+		{
+			"cordoned",
+			corev1.Node{
+				Spec:   corev1.NodeSpec{Unschedulable: true},
+				Status: corev1.NodeStatus{Conditions: readyCondition(corev1.ConditionTrue)},
+			},
+			"Ready,SchedulingDisabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NodeStatus(&tt.node); got != tt.want {
+				t.Errorf("NodeStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodePodsSelector(t *testing.T) {
+	if got, want := NodePodsSelector("node1", false),
+		"spec.nodeName=node1,status.phase!=Succeeded,status.phase!=Failed"; got != want {
+		t.Errorf("NodePodsSelector(false) = %q, want %q", got, want)
+	}
+	if got, want := NodePodsSelector("node1", true), "spec.nodeName=node1"; got != want {
+		t.Errorf("NodePodsSelector(true) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,407 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// prometheusRateWindow is the range the CPU usage rate is computed over, a few scrapes at the usual intervals
+const prometheusRateWindow = "5m"
+
+// prometheusContainerMatchers selects the series of app containers, leaving out the pod cgroup and pause containers
+const prometheusContainerMatchers = `container!="",container!="POD"`
+
+// minUsageWindowStep is the smallest resolution usage is sampled at over a window, a few scrapes apart
+const minUsageWindowStep = time.Minute
+
+// maxUsageWindowPoints bounds the number of points sampled per series over a window
+const maxUsageWindowPoints = 1000
+
+// UsageStat is the statistic usage is aggregated with over a window: avg, max, or a percentile such as p95
+type UsageStat string
+
+// Usage statistics besides percentiles
+const (
+	UsageStatAvg UsageStat = "avg"
+	UsageStatMax UsageStat = "max"
+)
+
+// ParseUsageStat parses avg, max or pNN, where NN is a percentile between 0 and 100 (exclusive)
+func ParseUsageStat(s string) (UsageStat, error) {
+	stat := UsageStat(s)
+	if stat == UsageStatAvg || stat == UsageStatMax {
+		return stat, nil
+	}
+	if _, ok := stat.quantile(); ok {
+		return stat, nil
+	}
+	return "", fmt.Errorf("invalid usage statistic %q, must be avg, max or a percentile such as p95", s)
+}
+
+// quantile returns the quantile of a percentile statistic, such as 0.95 for p95
+func (s UsageStat) quantile() (float64, bool) {
+	if !strings.HasPrefix(string(s), "p") {
+		return 0, false
+	}
+	percentile, err := strconv.ParseFloat(strings.TrimPrefix(string(s), "p"), 64)
+	if err != nil || percentile <= 0 || percentile >= 100 {
+		return 0, false
+	}
+	return percentile / 100, true
+}
+
+// UsageWindow aggregates usage over a time window with a statistic. The zero value means the current usage.
+type UsageWindow struct {
+	Window time.Duration
+	Stat   UsageStat
+}
+
+// IsZero reports whether the window is unset, i.e. usage is the current usage
+func (w UsageWindow) IsZero() bool {
+	return w.Window <= 0
+}
+
+// aggregate returns the PromQL expression aggregating the instant vector expr over the window.
+// It samples expr through a subquery, at most maxUsageWindowPoints times and at least minUsageWindowStep apart.
+func (w UsageWindow) aggregate(expr string) string {
+	step := w.Window / maxUsageWindowPoints
+	if step < minUsageWindowStep {
+		step = minUsageWindowStep
+	}
+	subquery := fmt.Sprintf("(%s)[%ds:%ds]", expr, int64(w.Window.Seconds()), int64(step.Seconds()))
+
+	if q, ok := w.Stat.quantile(); ok {
+		return fmt.Sprintf("quantile_over_time(%s, %s)", strconv.FormatFloat(q, 'f', -1, 64), subquery)
+	}
+	return fmt.Sprintf("%s_over_time(%s)", w.Stat, subquery)
+}
+
+// PrometheusSource reads cAdvisor usage from the Prometheus HTTP API: the rate of container_cpu_usage_seconds_total
+// and container_memory_working_set_bytes. Nodes use the root cgroup series (id="/") and are named after their
+// node label, or their instance label when there is none.
+type PrometheusSource struct {
+	// clientset resolves label and field selectors, which cAdvisor series don't carry
+	clientset  kubernetes.Interface
+	baseURL    string
+	httpClient *http.Client
+	usage      UsageWindow
+}
+
+// NewPrometheusSource creates a metrics source querying the Prometheus server at baseURL.
+// A nil httpClient uses http.DefaultClient.
+func NewPrometheusSource(clientset kubernetes.Interface, baseURL string, httpClient *http.Client) *PrometheusSource {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &PrometheusSource{clientset: clientset, baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: httpClient}
+}
+
+// WithUsageWindow returns a copy of the source aggregating usage over the window, for the pods and nodes
+// running now. Pods that started within the window are aggregated over their lifetime.
+func (s *PrometheusSource) WithUsageWindow(usage UsageWindow) *PrometheusSource {
+	windowed := *s
+	windowed.usage = usage
+	return &windowed
+}
+
+// Check returns an error if Prometheus cannot be queried or holds no cAdvisor series
+func (s *PrometheusSource) Check(ctx context.Context) error {
+	samples, err := s.query(ctx, "count(container_memory_working_set_bytes)")
+	if err != nil {
+		return fmt.Errorf("prometheus not available at %s: %w", s.baseURL, err)
+	}
+	if len(samples) == 0 {
+		return fmt.Errorf("prometheus at %s has no container_memory_working_set_bytes series\n"+
+			"Please ensure it scrapes the cAdvisor metrics of the kubelets", s.baseURL)
+	}
+	return nil
+}
+
+// PodMetrics fetches the container usage of pods from Prometheus. Selectors are resolved by listing the pods,
+// and containers are taken from the memory series since the CPU rate still covers containers that stopped
+// within the rate window. Over a usage window, pod totals are aggregated per pod, not summed over containers.
+func (s *PrometheusSource) PodMetrics(
+	ctx context.Context,
+	namespace, labelSelector, fieldSelector string,
+	podNames []string,
+) ([]PodMetrics, error) {
+	matchers := prometheusContainerMatchers
+	if namespace != "" {
+		matchers += ",namespace=" + strconv.Quote(namespace)
+	}
+
+	memory, err := s.query(ctx, s.usageQuery(fmt.Sprintf(
+		"sum by (namespace, pod, container) (container_memory_working_set_bytes{%s})", matchers)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pod metrics: %w", err)
+	}
+	cpu, err := s.query(ctx, s.usageQuery(fmt.Sprintf(
+		"sum by (namespace, pod, container) (rate(container_cpu_usage_seconds_total{%s}[%s]))",
+		matchers, prometheusRateWindow)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pod metrics: %w", err)
+	}
+
+	var selected map[string]bool
+	if labelSelector != "" || fieldSelector != "" {
+		pods, err := s.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: labelSelector,
+			FieldSelector: fieldSelector,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch pods: %w", err)
+		}
+		selected = make(map[string]bool, len(pods.Items))
+		for _, pod := range pods.Items {
+			selected[pod.Namespace+"/"+pod.Name] = true
+		}
+	}
+	names := make(map[string]bool, len(podNames))
+	for _, name := range podNames {
+		names[name] = true
+	}
+
+	cpuByContainer := make(map[string]float64, len(cpu))
+	for _, sample := range cpu {
+		m := sample.Metric
+		cpuByContainer[m["namespace"]+"/"+m["pod"]+"/"+m["container"]] = sample.Value
+	}
+
+	metrics := make([]PodMetrics, 0)
+	index := make(map[string]int)
+	for _, sample := range memory {
+		m := sample.Metric
+		key := m["namespace"] + "/" + m["pod"]
+		if (selected != nil && !selected[key]) || (len(names) > 0 && !names[m["pod"]]) {
+			continue
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(metrics)
+			index[key] = i
+			metrics = append(metrics, PodMetrics{Name: m["pod"], Namespace: m["namespace"]})
+		}
+
+		container := ContainerMetrics{
+			Name:        m["container"],
+			CPUMilli:    int64(math.Round(cpuByContainer[key+"/"+m["container"]] * 1000)),
+			MemoryBytes: int64(math.Round(sample.Value)),
+		}
+		pod := &metrics[i]
+		pod.CPUMilli += container.CPUMilli
+		pod.MemoryBytes += container.MemoryBytes
+		pod.Containers = append(pod.Containers, container)
+	}
+
+	// Containers rarely peak together, so over a window the pod's own quantile or maximum is queried
+	// instead of summing the containers'
+	if !s.usage.IsZero() {
+		if err := s.aggregatePodTotals(ctx, matchers, metrics); err != nil {
+			return nil, err
+		}
+	}
+
+	return metrics, nil
+}
+
+// aggregatePodTotals replaces the usage of the pods, summed over their containers, with the usage
+// of the pods aggregated over the usage window
+func (s *PrometheusSource) aggregatePodTotals(ctx context.Context, matchers string, metrics []PodMetrics) error {
+	memory, err := s.query(ctx, s.usageQuery(fmt.Sprintf(
+		"sum by (namespace, pod) (container_memory_working_set_bytes{%s})", matchers)))
+	if err != nil {
+		return fmt.Errorf("failed to fetch pod metrics: %w", err)
+	}
+	cpu, err := s.query(ctx, s.usageQuery(fmt.Sprintf(
+		"sum by (namespace, pod) (rate(container_cpu_usage_seconds_total{%s}[%s]))",
+		matchers, prometheusRateWindow)))
+	if err != nil {
+		return fmt.Errorf("failed to fetch pod metrics: %w", err)
+	}
+
+	memoryByPod := make(map[string]float64, len(memory))
+	for _, sample := range memory {
+		memoryByPod[sample.Metric["namespace"]+"/"+sample.Metric["pod"]] = sample.Value
+	}
+	cpuByPod := make(map[string]float64, len(cpu))
+	for _, sample := range cpu {
+		cpuByPod[sample.Metric["namespace"]+"/"+sample.Metric["pod"]] = sample.Value
+	}
+
+	for i := range metrics {
+		key := metrics[i].Namespace + "/" + metrics[i].Name
+		if value, ok := memoryByPod[key]; ok {
+			metrics[i].MemoryBytes = int64(math.Round(value))
+		}
+		if value, ok := cpuByPod[key]; ok {
+			metrics[i].CPUMilli = int64(math.Round(value * 1000))
+		}
+	}
+	return nil
+}
+
+// NodeMetrics fetches the usage of the nodes' root cgroups from Prometheus
+func (s *PrometheusSource) NodeMetrics(
+	ctx context.Context,
+	labelSelector string,
+	nodeNames []string,
+) ([]NodeMetrics, error) {
+	memory, err := s.query(ctx, s.usageQuery(nodeUsageExpr("container_memory_working_set_bytes{%s}")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch node metrics: %w", err)
+	}
+	cpu, err := s.query(ctx, s.usageQuery(nodeUsageExpr(
+		"rate(container_cpu_usage_seconds_total{%s}["+prometheusRateWindow+"])")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch node metrics: %w", err)
+	}
+
+	var selected map[string]bool
+	if labelSelector != "" {
+		nodes, err := s.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch nodes: %w", err)
+		}
+		selected = make(map[string]bool, len(nodes.Items))
+		for _, node := range nodes.Items {
+			selected[node.Name] = true
+		}
+	}
+	names := make(map[string]bool, len(nodeNames))
+	for _, name := range nodeNames {
+		names[name] = true
+	}
+
+	// A node scraped through several instances (the kubelet and a standalone cAdvisor) reports the same
+	// root cgroup on each. nodeUsageExpr already keeps one series per node; should several still name the
+	// same node, one is kept, never their sum, in the order nodes were first seen
+	cpuByNode := make(map[string]float64, len(cpu))
+	for _, sample := range cpu {
+		name := prometheusNodeName(sample.Metric)
+		cpuByNode[name] = math.Max(cpuByNode[name], sample.Value)
+	}
+
+	var nodeOrder []string
+	memoryByNode := make(map[string]float64, len(memory))
+	for _, sample := range memory {
+		name := prometheusNodeName(sample.Metric)
+		if (selected != nil && !selected[name]) || (len(names) > 0 && !names[name]) {
+			continue
+		}
+		if _, ok := memoryByNode[name]; !ok {
+			nodeOrder = append(nodeOrder, name)
+		}
+		memoryByNode[name] = math.Max(memoryByNode[name], sample.Value)
+	}
+
+	metrics := make([]NodeMetrics, 0, len(nodeOrder))
+	for _, name := range nodeOrder {
+		metrics = append(metrics, NodeMetrics{
+			Name:        name,
+			CPUMilli:    int64(math.Round(cpuByNode[name] * 1000)),
+			MemoryBytes: int64(math.Round(memoryByNode[name])),
+		})
+	}
+
+	return metrics, nil
+}
+
+// usageQuery returns the query of the current usage expr, or of its aggregate over the usage window.
+// Aggregates are kept only for the series present now, so pods and nodes gone within the window are left out.
+func (s *PrometheusSource) usageQuery(expr string) string {
+	if s.usage.IsZero() {
+		return expr
+	}
+	return fmt.Sprintf("%s and %s", s.usage.aggregate(expr), expr)
+}
+
+// nodeUsageExpr returns the root cgroup usage of every node, one series per node named after its node
+// label, or its instance label when there is none. selector is formatted with the label matchers.
+// Reducing to one series per node here keeps a window aggregate the node's own statistic, even for a
+// node scraped through several instances.
+func nodeUsageExpr(selector string) string {
+	return fmt.Sprintf(`(max by (node) (%s) or max by (node) (label_replace(%s, "node", "$1", "instance", "(.*)")))`,
+		fmt.Sprintf(selector, `id="/",node!=""`), fmt.Sprintf(selector, `id="/",node=""`))
+}
+
+// prometheusNodeName returns the node of a series: its node label, or its instance label when there is none
+func prometheusNodeName(metric map[string]string) string {
+	if node := metric["node"]; node != "" {
+		return node
+	}
+	return metric["instance"]
+}
+
+// prometheusResponse is the envelope of the Prometheus HTTP API responses
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []json.RawMessage `json:"value"` // [timestamp, "value"]
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// prometheusSample is a series of an instant vector with its value
+type prometheusSample struct {
+	Metric map[string]string
+	Value  float64
+}
+
+// query runs an instant query and returns the samples of the resulting vector.
+// NaN and infinite samples are dropped.
+func (s *PrometheusSource) query(ctx context.Context, query string) ([]prometheusSample, error) {
+	endpoint := s.baseURL + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prometheus request: %w", err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body prometheusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode prometheus response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if body.Status != "success" {
+		return nil, fmt.Errorf("prometheus query %q failed: %s: %s", query, body.ErrorType, body.Error)
+	}
+	if body.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("prometheus query %q returned a %s, want a vector", query, body.Data.ResultType)
+	}
+
+	samples := make([]prometheusSample, 0, len(body.Data.Result))
+	for _, r := range body.Data.Result {
+		if len(r.Value) != 2 {
+			continue
+		}
+		var raw string
+		if err := json.Unmarshal(r.Value[1], &raw); err != nil {
+			return nil, fmt.Errorf("failed to decode prometheus sample: %w", err)
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		samples = append(samples, prometheusSample{Metric: r.Metric, Value: value})
+	}
+	return samples, nil
+}
@@ -0,0 +1,297 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testSeries is a series of a fake Prometheus instant vector
+type testSeries struct {
+	labels map[string]string
+	value  string
+}
+
+// newFakePrometheus starts a Prometheus HTTP API answering instant queries with the series of every
+// key contained in the query, and records the queries it receives
+func newFakePrometheus(t *testing.T, vectors map[string][]testSeries, queries *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query().Get("query")
+		if queries != nil {
+			*queries = append(*queries, query)
+		}
+
+		result := make([]map[string]interface{}, 0)
+		for key, series := range vectors {
+			if !strings.Contains(query, key) {
+				continue
+			}
+			for _, s := range series {
+				result = append(result, map[string]interface{}{
+					"metric": s.labels,
+					"value":  []interface{}{1700000000.0, s.value},
+				})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data":   map[string]interface{}{"resultType": "vector", "result": result},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// containerSeries returns the labels of the series of a container
+func containerSeries(namespace, pod, container string) map[string]string {
+	return map[string]string{"namespace": namespace, "pod": pod, "container": container}
+}
+
+func TestPrometheusSourcePodMetrics(t *testing.T) {
+	var queries []string
+	server := newFakePrometheus(t, map[string][]testSeries{
+		"sum by (namespace, pod, container) (container_memory_working_set_bytes": {
+			{containerSeries("default", "web-1", "app"), "104857600"},
+			{containerSeries("default", "web-1", "proxy"), "20971520"},
+			{containerSeries("default", "db-0", "db"), "1073741824"},
+		},
+		"sum by (namespace, pod, container) (rate(container_cpu_usage_seconds_total": {
+			{containerSeries("default", "web-1", "app"), "0.25"},
+			{containerSeries("default", "web-1", "proxy"), "0.0104"},
+			{containerSeries("default", "db-0", "db"), "1.5"},
+			// Stopped within the rate window: no memory series anymore
+			{containerSeries("default", "old", "app"), "0.1"},
+		},
+	}, &queries)
+
+	clientset := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+	})
+	source := NewPrometheusSource(clientset, server.URL+"/", nil)
+
+	metrics, err := source.PodMetrics(context.Background(), "default", "", "", nil)
+	if err != nil {
+		t.Fatalf("PodMetrics() error = %v", err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("PodMetrics() returned %d pods, want 2 (stopped containers are dropped): %+v", len(metrics), metrics)
+	}
+	web := metrics[0]
+	if web.Name != "web-1" || web.CPUMilli != 260 || web.MemoryBytes != 120<<20 || len(web.Containers) != 2 {
+		t.Errorf("PodMetrics() web-1 = %+v, want 260m and 120Mi over 2 containers", web)
+	}
+	if db := metrics[1]; db.CPUMilli != 1500 || db.Containers[0].MemoryBytes != 1<<30 {
+		t.Errorf("PodMetrics() db-0 = %+v", db)
+	}
+	for _, query := range queries {
+		if !strings.Contains(query, `namespace="default"`) || !strings.Contains(query, `container!="POD"`) {
+			t.Errorf("query %q should select the app containers of the namespace", query)
+		}
+	}
+
+	// Selectors are resolved through the API server, names are matched locally
+	selected, err := source.PodMetrics(context.Background(), "default", "app=web", "", nil)
+	if err != nil || len(selected) != 1 || selected[0].Name != "web-1" {
+		t.Errorf("PodMetrics() with a label selector = %+v, %v, want web-1", selected, err)
+	}
+	named, err := source.PodMetrics(context.Background(), "default", "", "", []string{"db-0"})
+	if err != nil || len(named) != 1 || named[0].Name != "db-0" {
+		t.Errorf("PodMetrics() with a pod name = %+v, %v, want db-0", named, err)
+	}
+}
+
+func TestPrometheusSourceNodeMetrics(t *testing.T) {
+	server := newFakePrometheus(t, map[string][]testSeries{
+		"max by (node) (container_memory_working_set_bytes": {
+			{map[string]string{"node": "node-1"}, "2147483648"},
+			{map[string]string{"node": "node-3"}, "536870912"},
+			{map[string]string{"node": "node-3"}, "536870912"},
+		},
+		"label_replace(container_memory_working_set_bytes": {
+			{map[string]string{"instance": "node-2"}, "1073741824"},
+		},
+		"max by (node) (rate(container_cpu_usage_seconds_total": {
+			{map[string]string{"node": "node-1"}, "1.25"},
+			{map[string]string{"node": "node-3"}, "0.25"},
+			{map[string]string{"node": "node-3"}, "0.25"},
+		},
+		"label_replace(rate(container_cpu_usage_seconds_total": {
+			{map[string]string{"instance": "node-2"}, "0.5"},
+		},
+	}, nil)
+
+	clientset := fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-2", Labels: map[string]string{"pool": "spot"}},
+	})
+	source := NewPrometheusSource(clientset, server.URL, nil)
+
+	metrics, err := source.NodeMetrics(context.Background(), "", nil)
+	if err != nil {
+		t.Fatalf("NodeMetrics() error = %v", err)
+	}
+	if len(metrics) != 3 {
+		t.Fatalf("NodeMetrics() returned %d nodes, want 3: %+v", len(metrics), metrics)
+	}
+	// The series of both expressions come back in any order
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	if n := metrics[0]; n.Name != "node-1" || n.CPUMilli != 1250 || n.MemoryBytes != 2<<30 {
+		t.Errorf("NodeMetrics() node-1 = %+v, want the node label to name it", n)
+	}
+	if n := metrics[1]; n.Name != "node-2" || n.CPUMilli != 500 {
+		t.Errorf("NodeMetrics() node-2 = %+v, want the instance label without a node label", n)
+	}
+	if n := metrics[2]; n.Name != "node-3" || n.CPUMilli != 250 || n.MemoryBytes != 512<<20 {
+		t.Errorf("NodeMetrics() node-3 = %+v, want one entry with the usage both instances report", n)
+	}
+
+	selected, err := source.NodeMetrics(context.Background(), "pool=spot", nil)
+	if err != nil || len(selected) != 1 || selected[0].Name != "node-2" {
+		t.Errorf("NodeMetrics() with a label selector = %+v, %v, want node-2", selected, err)
+	}
+}
+
+func TestPrometheusSourceCheck(t *testing.T) {
+	server := newFakePrometheus(t, map[string][]testSeries{
+		"count(container_memory_working_set_bytes)": {{map[string]string{}, "42"}},
+	}, nil)
+	if err := NewPrometheusSource(nil, server.URL, nil).Check(context.Background()); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+
+	empty := newFakePrometheus(t, nil, nil)
+	if err := NewPrometheusSource(nil, empty.URL, nil).Check(context.Background()); err == nil ||
+		!strings.Contains(err.Error(), "cAdvisor") {
+		t.Errorf("Check() without cAdvisor series = %v, want an error", err)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+	}))
+	defer failing.Close()
+	err := NewPrometheusSource(nil, failing.URL, nil).Check(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "bad_data: parse error") {
+		t.Errorf("Check() on a failing query = %v, want the Prometheus error", err)
+	}
+}
+
+func TestParseUsageStat(t *testing.T) {
+	for _, valid := range []string{"avg", "max", "p95", "p99.9", "p50"} {
+		if _, err := ParseUsageStat(valid); err != nil {
+			t.Errorf("ParseUsageStat(%q) error = %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"", "min", "p100", "p0", "95", "pxx"} {
+		if _, err := ParseUsageStat(invalid); err == nil {
+			t.Errorf("ParseUsageStat(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestPrometheusSourceUsageWindow(t *testing.T) {
+	var queries []string
+	server := newFakePrometheus(t, map[string][]testSeries{
+		"(container_memory_working_set_bytes":     {{containerSeries("default", "web-1", "app"), "268435456"}},
+		"(rate(container_cpu_usage_seconds_total": {{containerSeries("default", "web-1", "app"), "0.8"}},
+	}, &queries)
+	source := NewPrometheusSource(nil, server.URL, nil).
+		WithUsageWindow(UsageWindow{Window: 24 * time.Hour, Stat: "p95"})
+
+	metrics, err := source.PodMetrics(context.Background(), "default", "", "", nil)
+	if err != nil {
+		t.Fatalf("PodMetrics() error = %v", err)
+	}
+	if len(metrics) != 1 || metrics[0].CPUMilli != 800 || metrics[0].MemoryBytes != 256<<20 {
+		t.Errorf("PodMetrics() over a window = %+v, want 800m and 256Mi", metrics)
+	}
+
+	// A day is sampled every 86s through a subquery, for the containers running now
+	want := "quantile_over_time(0.95, (sum by (namespace, pod, container) (container_memory_working_set_bytes{"
+	if len(queries) != 4 || !strings.HasPrefix(queries[0], want) ||
+		!strings.Contains(queries[0], "}))[86400s:86s]) and sum by") {
+		t.Errorf("PodMetrics() queries = %q, want a p95 subquery over the day", queries)
+	}
+
+	if !strings.Contains(queries[2], "(sum by (namespace, pod) (container_memory_working_set_bytes{") {
+		t.Errorf("PodMetrics() queries = %q, want the pod totals aggregated per pod", queries)
+	}
+
+	hour := UsageWindow{Window: time.Hour, Stat: UsageStatMax}
+	if got := hour.aggregate("up"); got != "max_over_time((up)[3600s:60s])" {
+		t.Errorf("aggregate() = %s, want a one-minute resolution at least", got)
+	}
+}
+
+func TestPrometheusSourceUsageWindowNodes(t *testing.T) {
+	var queries []string
+	server := newFakePrometheus(t, map[string][]testSeries{
+		"max by (node) (container_memory_working_set_bytes":     {{map[string]string{"node": "node-1"}, "1073741824"}},
+		"max by (node) (rate(container_cpu_usage_seconds_total": {{map[string]string{"node": "node-1"}, "0.75"}},
+	}, &queries)
+	source := NewPrometheusSource(nil, server.URL, nil).
+		WithUsageWindow(UsageWindow{Window: 24 * time.Hour, Stat: "p95"})
+
+	metrics, err := source.NodeMetrics(context.Background(), "", nil)
+	if err != nil {
+		t.Fatalf("NodeMetrics() error = %v", err)
+	}
+	if len(metrics) != 1 || metrics[0].CPUMilli != 750 || metrics[0].MemoryBytes != 1<<30 {
+		t.Errorf("NodeMetrics() over a window = %+v, want 750m and 1Gi", metrics)
+	}
+
+	// The instances a node is scraped through are reduced to one series before the p95 is taken
+	want := "quantile_over_time(0.95, ((max by (node) (container_memory_working_set_bytes{id=\"/\",node!=\"\"}) or "
+	if len(queries) != 2 || !strings.HasPrefix(queries[0], want) {
+		t.Errorf("NodeMetrics() queries = %q, want the p95 of the node's own series", queries)
+	}
+	if !strings.Contains(queries[1], `label_replace(rate(container_cpu_usage_seconds_total{id="/",node=""}[5m])`) {
+		t.Errorf("NodeMetrics() queries = %q, want nodes without a node label named after their instance", queries)
+	}
+}
+
+func TestPrometheusSourceUsageWindowPodTotals(t *testing.T) {
+	// The containers peak at different times: the pod's p95 is below the sum of the containers' p95
+	server := newFakePrometheus(t, map[string][]testSeries{
+		"(sum by (namespace, pod, container) (container_memory_working_set_bytes": {
+			{containerSeries("default", "web-1", "app"), "209715200"},
+			{containerSeries("default", "web-1", "proxy"), "104857600"},
+		},
+		"(sum by (namespace, pod, container) (rate(container_cpu_usage_seconds_total": {
+			{containerSeries("default", "web-1", "app"), "0.8"},
+			{containerSeries("default", "web-1", "proxy"), "0.4"},
+		},
+		"(sum by (namespace, pod) (container_memory_working_set_bytes": {
+			{map[string]string{"namespace": "default", "pod": "web-1"}, "251658240"},
+		},
+		"(sum by (namespace, pod) (rate(container_cpu_usage_seconds_total": {
+			{map[string]string{"namespace": "default", "pod": "web-1"}, "0.9"},
+		},
+	}, nil)
+	source := NewPrometheusSource(nil, server.URL, nil).
+		WithUsageWindow(UsageWindow{Window: 24 * time.Hour, Stat: "p95"})
+
+	metrics, err := source.PodMetrics(context.Background(), "default", "", "", nil)
+	if err != nil {
+		t.Fatalf("PodMetrics() error = %v", err)
+	}
+	if len(metrics) != 1 || metrics[0].CPUMilli != 900 || metrics[0].MemoryBytes != 240<<20 {
+		t.Fatalf("PodMetrics() = %+v, want the pod's own p95 of 900m and 240Mi, not the sum of its containers", metrics)
+	}
+	if c := metrics[0].Containers; len(c) != 2 || c[0].CPUMilli != 800 || c[1].MemoryBytes != 100<<20 {
+		t.Errorf("PodMetrics() containers = %+v, want the p95 of each container", c)
+	}
+}
@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// QuotaUsage holds the used and hard values of a quota resource, in millicores for CPU and bytes for memory
+type QuotaUsage struct {
+	Limited bool // The namespace has a quota on the resource
+	Used    int64
+	Hard    int64
+}
+
+// NamespaceQuota holds the compute quota of a namespace. When several ResourceQuotas limit the same
+// resource, the one with the smallest hard value is kept since it is the one pods run into first.
+type NamespaceQuota struct {
+	Namespace     string
+	CPURequest    QuotaUsage // requests.cpu (or cpu)
+	CPULimit      QuotaUsage // limits.cpu
+	MemoryRequest QuotaUsage // requests.memory (or memory)
+	MemoryLimit   QuotaUsage // limits.memory
+}
+
+// GetNamespaceQuotas fetches the ResourceQuotas of a namespace (all namespaces if empty) and returns
+// their compute resources by namespace. Scoped quotas (BestEffort, PriorityClass, ...) only cover
+// some of the pods and are skipped.
+func GetNamespaceQuotas(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	namespace string,
+) (map[string]*NamespaceQuota, error) {
+	quotaList, err := clientset.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch resource quotas: %w", err)
+	}
+
+	quotas := make(map[string]*NamespaceQuota)
+	for i := range quotaList.Items {
+		quota := &quotaList.Items[i]
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+
+		nsQuota := quotas[quota.Namespace]
+		if nsQuota == nil {
+			nsQuota = &NamespaceQuota{Namespace: quota.Namespace}
+			quotas[quota.Namespace] = nsQuota
+		}
+
+		status := quota.Status
+		mergeQuotaUsage(&nsQuota.CPURequest, status, true, corev1.ResourceRequestsCPU, corev1.ResourceCPU)
+		mergeQuotaUsage(&nsQuota.CPULimit, status, true, corev1.ResourceLimitsCPU)
+		mergeQuotaUsage(&nsQuota.MemoryRequest, status, false, corev1.ResourceRequestsMemory, corev1.ResourceMemory)
+		mergeQuotaUsage(&nsQuota.MemoryLimit, status, false, corev1.ResourceLimitsMemory)
+	}
+
+	return quotas, nil
+}
+
+// mergeQuotaUsage keeps the hard and used values of the first of names set in the quota status,
+// if its hard value is smaller than the one already in usage
+func mergeQuotaUsage(usage *QuotaUsage, status corev1.ResourceQuotaStatus, isCPU bool, names ...corev1.ResourceName) {
+	for _, name := range names {
+		hard, ok := status.Hard[name]
+		if !ok {
+			continue
+		}
+		used := quantity(status.Used, name)
+
+		candidate := QuotaUsage{Limited: true, Used: used.Value(), Hard: hard.Value()}
+		if isCPU {
+			candidate = QuotaUsage{Limited: true, Used: used.MilliValue(), Hard: hard.MilliValue()}
+		}
+		if !usage.Limited || candidate.Hard < usage.Hard {
+			*usage = candidate
+		}
+		return
+	}
+}
@@ -0,0 +1,78 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testQuota returns a ResourceQuota whose status reports the given hard and used values
+func testQuota(namespace, name string, hard, used map[corev1.ResourceName]string) *corev1.ResourceQuota {
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status:     corev1.ResourceQuotaStatus{Hard: corev1.ResourceList{}, Used: corev1.ResourceList{}},
+	}
+	for name, value := range hard {
+		quota.Status.Hard[name] = resource.MustParse(value)
+	}
+	for name, value := range used {
+		quota.Status.Used[name] = resource.MustParse(value)
+	}
+	return quota
+}
+
+func TestGetNamespaceQuotas(t *testing.T) {
+	scoped := testQuota("team-a", "best-effort", map[corev1.ResourceName]string{corev1.ResourceRequestsCPU: "1"}, nil)
+	scoped.Spec.Scopes = []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}
+
+	clientset := fake.NewSimpleClientset(
+		testQuota("team-a", "compute",
+			map[corev1.ResourceName]string{
+				corev1.ResourceRequestsCPU: "4", corev1.ResourceLimitsCPU: "8", corev1.ResourceRequestsMemory: "8Gi",
+			},
+			map[corev1.ResourceName]string{
+				corev1.ResourceRequestsCPU: "1500m", corev1.ResourceLimitsCPU: "3", corev1.ResourceRequestsMemory: "2Gi",
+			}),
+		testQuota("team-a", "tight",
+			map[corev1.ResourceName]string{corev1.ResourceRequestsCPU: "2"},
+			map[corev1.ResourceName]string{corev1.ResourceRequestsCPU: "1500m"}),
+		scoped,
+		testQuota("team-b", "legacy",
+			map[corev1.ResourceName]string{corev1.ResourceCPU: "1", corev1.ResourceMemory: "1Gi"},
+			map[corev1.ResourceName]string{corev1.ResourceCPU: "250m"}),
+	)
+
+	quotas, err := GetNamespaceQuotas(context.Background(), clientset, "")
+	if err != nil {
+		t.Fatalf("GetNamespaceQuotas() error = %v", err)
+	}
+	if len(quotas) != 2 {
+		t.Fatalf("GetNamespaceQuotas() returned %d namespaces, want 2", len(quotas))
+	}
+
+	teamA := quotas["team-a"]
+	if want := (QuotaUsage{Limited: true, Used: 1500, Hard: 2000}); teamA.CPURequest != want {
+		t.Errorf("team-a CPU request quota = %+v, want the smallest hard %+v", teamA.CPURequest, want)
+	}
+	if want := (QuotaUsage{Limited: true, Used: 3000, Hard: 8000}); teamA.CPULimit != want {
+		t.Errorf("team-a CPU limit quota = %+v, want %+v", teamA.CPULimit, want)
+	}
+	if want := (QuotaUsage{Limited: true, Used: 2 << 30, Hard: 8 << 30}); teamA.MemoryRequest != want {
+		t.Errorf("team-a memory request quota = %+v, want %+v", teamA.MemoryRequest, want)
+	}
+	if teamA.MemoryLimit.Limited {
+		t.Errorf("team-a memory limit quota = %+v, want unlimited", teamA.MemoryLimit)
+	}
+
+	teamB := quotas["team-b"]
+	if want := (QuotaUsage{Limited: true, Used: 250, Hard: 1000}); teamB.CPURequest != want {
+		t.Errorf("team-b cpu quota = %+v, want %+v", teamB.CPURequest, want)
+	}
+	if want := (QuotaUsage{Limited: true, Used: 0, Hard: 1 << 30}); teamB.MemoryRequest != want {
+		t.Errorf("team-b memory quota = %+v, want %+v", teamB.MemoryRequest, want)
+	}
+}
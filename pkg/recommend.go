@@ -0,0 +1,72 @@
+package pkg
+
+import (
+	"math"
+	"sort"
+)
+
+// RecommendationPolicy turns the usage samples of one resource (CPU in millicores or memory in bytes)
+// into a proposed request and limit
+type RecommendationPolicy struct {
+	RequestPercentile float64 // Percentile of the samples the request covers, in (0, 100]
+	LimitPercentile   float64 // Percentile of the samples the limit covers, 100 is the peak
+	Headroom          float64 // Percentage added on top of both percentiles
+	Round             int64   // Proposals are rounded up to a multiple of this, which is also the minimum
+}
+
+// Recommend returns the proposed request and limit for the samples; the limit is never below the request.
+// It returns false without samples.
+func (p RecommendationPolicy) Recommend(samples []int64) (request, limit int64, ok bool) {
+	if len(samples) == 0 {
+		return 0, 0, false
+	}
+
+	sorted := append([]int64(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	request = p.propose(percentile(sorted, p.RequestPercentile))
+	limit = p.propose(percentile(sorted, p.LimitPercentile))
+	if limit < request {
+		limit = request
+	}
+	return request, limit, true
+}
+
+// propose adds the headroom to a sample value and rounds it up
+func (p RecommendationPolicy) propose(value int64) int64 {
+	withHeadroom := int64(math.Ceil(float64(value) * (1 + p.Headroom/100)))
+	return roundUp(withHeadroom, p.Round)
+}
+
+// Percentile returns the nearest-rank percentile (0-100) of the samples, 0 without samples
+func Percentile(samples []int64, p float64) int64 {
+	sorted := append([]int64(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return percentile(sorted, p)
+}
+
+// percentile returns the nearest-rank percentile of samples sorted in ascending order
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// roundUp rounds value up to a multiple of step, with step as the minimum. A non-positive step keeps the value.
+func roundUp(value, step int64) int64 {
+	if step <= 0 {
+		return value
+	}
+	if value <= step {
+		return step
+	}
+	return (value + step - 1) / step * step
+}
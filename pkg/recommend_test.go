@@ -0,0 +1,72 @@
+package pkg
+
+import "testing"
+
+func TestPercentile(t *testing.T) {
+	samples := []int64{50, 10, 40, 20, 30, 60, 70, 80, 90, 100}
+
+	tests := []struct {
+		p    float64
+		want int64
+	}{
+		{p: 0, want: 10},
+		{p: 50, want: 50},
+		{p: 90, want: 90},
+		{p: 95, want: 100},
+		{p: 100, want: 100},
+	}
+	for _, tt := range tests {
+		if got := Percentile(samples, tt.p); got != tt.want {
+			t.Errorf("Percentile(p%v) = %d, want %d", tt.p, got, tt.want)
+		}
+	}
+	if got := Percentile(nil, 90); got != 0 {
+		t.Errorf("Percentile() without samples = %d, want 0", got)
+	}
+}
+
+func TestRecommendationPolicyRecommend(t *testing.T) {
+	policy := RecommendationPolicy{RequestPercentile: 90, LimitPercentile: 100, Headroom: 15, Round: 10}
+
+	tests := []struct {
+		name                   string
+		samples                []int64
+		wantRequest, wantLimit int64
+		wantOK                 bool
+	}{
+		{
+			name: "no samples",
+		},
+		{
+			// p90 = 90 -> 103.5 -> 110, peak 100 -> 115 -> 120
+			name:        "headroom and rounding",
+			samples:     []int64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
+			wantRequest: 110,
+			wantLimit:   120,
+			wantOK:      true,
+		},
+		{
+			name:        "round is the minimum",
+			samples:     []int64{0, 1, 0},
+			wantRequest: 10,
+			wantLimit:   10,
+			wantOK:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, limit, ok := policy.Recommend(tt.samples)
+			if request != tt.wantRequest || limit != tt.wantLimit || ok != tt.wantOK {
+				t.Errorf("Recommend() = %d, %d, %v, want %d, %d, %v",
+					request, limit, ok, tt.wantRequest, tt.wantLimit, tt.wantOK)
+			}
+		})
+	}
+
+	// The limit never goes below the request, even with a lower limit percentile
+	low := RecommendationPolicy{RequestPercentile: 90, LimitPercentile: 50, Round: 1}
+	if request, limit, _ := low.Recommend([]int64{10, 20, 30, 40}); limit != request {
+		t.Errorf("Recommend() limit = %d, want the request %d", limit, request)
+	}
+}
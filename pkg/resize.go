@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// In-place resize states returned by PodResizeState
+const (
+	ResizePending    = "Pending"    // The spec was resized but the kubelet has not allocated the resources yet
+	ResizeInfeasible = "Infeasible" // The node cannot fit the new resources
+	ResizeInProgress = "InProgress" // The resources are allocated but not yet in force
+)
+
+// containerStatuses returns the statuses of the pod's containers and init containers by container name
+func containerStatuses(pod *corev1.Pod) map[string]*corev1.ContainerStatus {
+	statuses := make(map[string]*corev1.ContainerStatus,
+		len(pod.Status.ContainerStatuses)+len(pod.Status.InitContainerStatuses))
+	for i := range pod.Status.InitContainerStatuses {
+		statuses[pod.Status.InitContainerStatuses[i].Name] = &pod.Status.InitContainerStatuses[i]
+	}
+	for i := range pod.Status.ContainerStatuses {
+		statuses[pod.Status.ContainerStatuses[i].Name] = &pod.Status.ContainerStatuses[i]
+	}
+	return statuses
+}
+
+// containerResourcesInForce returns the requests and limits in force for a container.
+// With in-place pod resize the spec only holds the desired resources; the status reports the
+// resources actually configured (or at least allocated), which are preferred when present.
+func containerResourcesInForce(
+	container *corev1.Container,
+	status *corev1.ContainerStatus,
+) (requests, limits corev1.ResourceList) {
+	requests, limits = container.Resources.Requests, container.Resources.Limits
+	if status == nil {
+		return requests, limits
+	}
+	if status.Resources != nil {
+		return status.Resources.Requests, status.Resources.Limits
+	}
+	if len(status.AllocatedResources) > 0 {
+		requests = status.AllocatedResources
+	}
+	return requests, limits
+}
+
+// containerResizing reports whether the container's desired CPU and memory differ from the resources in force
+func containerResizing(container *corev1.Container, status *corev1.ContainerStatus) bool {
+	requests, limits := containerResourcesInForce(container, status)
+	return !cpuAndMemoryEqual(container.Resources.Requests, requests) ||
+		!cpuAndMemoryEqual(container.Resources.Limits, limits)
+}
+
+// PodResizeState returns the state of an in-place resize of the pod, or "" when the resources in force
+// match the spec. It is based on the PodResizePending and PodResizeInProgress conditions, and on the
+// spec differing from the container statuses for clusters that do not set those conditions.
+func PodResizeState(pod *corev1.Pod) string {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodResizePending && condition.Status == corev1.ConditionTrue {
+			if condition.Reason == corev1.PodReasonInfeasible {
+				return ResizeInfeasible
+			}
+			return ResizePending
+		}
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodResizeInProgress && condition.Status == corev1.ConditionTrue {
+			return ResizeInProgress
+		}
+	}
+
+	statuses := containerStatuses(pod)
+	for i := range pod.Spec.InitContainers {
+		if containerResizing(&pod.Spec.InitContainers[i], statuses[pod.Spec.InitContainers[i].Name]) {
+			return ResizeInProgress
+		}
+	}
+	for i := range pod.Spec.Containers {
+		if containerResizing(&pod.Spec.Containers[i], statuses[pod.Spec.Containers[i].Name]) {
+			return ResizeInProgress
+		}
+	}
+	return ""
+}
+
+// cpuAndMemoryEqual reports whether two resource lists hold the same CPU and memory
+func cpuAndMemoryEqual(a, b corev1.ResourceList) bool {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		qa, qb := quantity(a, name), quantity(b, name)
+		if qa.Cmp(qb) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package pkg
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestPodEffectiveRequestsPreferStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      corev1.ContainerStatus
+		wantRequest string
+		wantLimit   string
+	}{
+		{
+			name:        "no resources in status uses the spec",
+			status:      corev1.ContainerStatus{Name: "app"},
+			wantRequest: "500m",
+			wantLimit:   "1",
+		},
+		{
+			name: "allocated resources replace the spec requests",
+			status: corev1.ContainerStatus{
+				Name:               "app",
+				AllocatedResources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")},
+			},
+			wantRequest: "200m",
+			wantLimit:   "1",
+		},
+		{
+			name: "resources in status win over allocated resources",
+			status: corev1.ContainerStatus{
+				Name:               "app",
+				AllocatedResources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")},
+				Resources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")},
+				},
+			},
+			wantRequest: "100m",
+			wantLimit:   "300m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "500m", "1", false)}},
+				Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{tt.status}},
+			}
+
+			requests := PodEffectiveRequests(pod)
+			if got, want := requests[corev1.ResourceCPU], resource.MustParse(tt.wantRequest); got.Cmp(want) != 0 {
+				t.Errorf("PodEffectiveRequests() cpu = %s, want %s", got.String(), want.String())
+			}
+			limits := PodEffectiveLimits(pod)
+			if got, want := limits[corev1.ResourceCPU], resource.MustParse(tt.wantLimit); got.Cmp(want) != 0 {
+				t.Errorf("PodEffectiveLimits() cpu = %s, want %s", got.String(), want.String())
+			}
+		})
+	}
+}
+
+func TestPodResizeState(t *testing.T) {
+	inForce := func(cpu string) *corev1.ResourceRequirements {
+		return &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+			Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		conditions []corev1.PodCondition
+		resources  *corev1.ResourceRequirements
+		want       string
+	}{
+		{
+			name:      "spec in force",
+			resources: inForce("500m"),
+			want:      "",
+		},
+		{
+			name:      "no resources in status",
+			resources: nil,
+			want:      "",
+		},
+		{
+			name: "pending",
+			conditions: []corev1.PodCondition{
+				{Type: corev1.PodResizePending, Status: corev1.ConditionTrue, Reason: corev1.PodReasonDeferred},
+			},
+			resources: inForce("500m"),
+			want:      ResizePending,
+		},
+		{
+			name: "infeasible",
+			conditions: []corev1.PodCondition{
+				{Type: corev1.PodResizePending, Status: corev1.ConditionTrue, Reason: corev1.PodReasonInfeasible},
+			},
+			resources: inForce("500m"),
+			want:      ResizeInfeasible,
+		},
+		{
+			name:       "in progress",
+			conditions: []corev1.PodCondition{{Type: corev1.PodResizeInProgress, Status: corev1.ConditionTrue}},
+			resources:  inForce("500m"),
+			want:       ResizeInProgress,
+		},
+		{
+			name:      "status differs from spec without conditions",
+			resources: inForce("250m"),
+			want:      ResizeInProgress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{testContainer("app", "500m", "1", false)}},
+				Status: corev1.PodStatus{
+					Conditions:        tt.conditions,
+					ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Resources: tt.resources}},
+				},
+			}
+			if got := PodResizeState(pod); got != tt.want {
+				t.Errorf("PodResizeState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,24 +3,48 @@ package pkg
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/apimachinery/pkg/api/resource"
 )
 
-// PodResources represents resource requests and limits for a pod
+// PodResources represents resource requests and limits for a pod.
+// CPU is in millicores and memory in bytes; zero means unset.
 type PodResources struct {
-	Name            string
-	Namespace       string
-	CPURequest      string
-	CPULimit        string
-	MemoryRequest   resource.Quantity
-	MemoryLimit     resource.Quantity
-	MemoryRequestStr string // Keep formatted string for backward compatibility
-	MemoryLimitStr  string
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Empty if the pod is not scheduled yet
+	NodeName string `json:"nodeName,omitempty"`
+	// Lifecycle phase of the pod (Running, Succeeded, ...)
+	Phase corev1.PodPhase `json:"phase,omitempty"`
+	// Controller of the pod as found in its ownerReferences, see ResolveWorkloads
+	Owner Workload `json:"owner"`
+	// In-place resize state (ResizePending, ...), empty if the spec is in force
+	Resize string `json:"resize,omitempty"`
+	// Resources a LimitRange defaulted in any container, see LimitRangeDefaults
+	LimitRangeDefaults []string             `json:"limitRangeDefaults,omitempty"`
+	CPURequestMilli    int64                `json:"cpuRequestMilli"`
+	CPULimitMilli      int64                `json:"cpuLimitMilli"`
+	MemoryRequestBytes int64                `json:"memoryRequestBytes"`
+	MemoryLimitBytes   int64                `json:"memoryLimitBytes"`
+	Containers         []ContainerResources `json:"containers"`
+}
+
+// ContainerResources represents resource requests and limits for a single container in a pod
+type ContainerResources struct {
+	Name string `json:"name"`
+	// Native sidecar, declared in the pod's init containers
+	Sidecar bool `json:"sidecar,omitempty"`
+	// The pod's resize state if this container is being resized
+	Resize string `json:"resize,omitempty"`
+	// Resources a LimitRange defaulted for this container
+	LimitRangeDefaults []string `json:"limitRangeDefaults,omitempty"`
+	CPURequestMilli    int64    `json:"cpuRequestMilli"`
+	CPULimitMilli      int64    `json:"cpuLimitMilli"`
+	MemoryRequestBytes int64    `json:"memoryRequestBytes"`
+	MemoryLimitBytes   int64    `json:"memoryLimitBytes"`
 }
 
 // GetPodResources fetches pod resources (requests and limits) from pod specifications
@@ -65,62 +89,88 @@ func GetPodResources(
 	}
 
 	resources := make([]PodResources, 0, len(podList.Items))
-	for _, pod := range podList.Items {
-		var totalCPURequest, totalCPULimit resource.Quantity
-		var totalMemoryRequest, totalMemoryLimit resource.Quantity
-
-		// Aggregate resources from all containers in the pod
-		for _, container := range pod.Spec.Containers {
-			if container.Resources.Requests != nil {
-				if cpu, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-					totalCPURequest.Add(cpu)
-				}
-				if memory, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
-					totalMemoryRequest.Add(memory)
-				}
-			}
-			if container.Resources.Limits != nil {
-				if cpu, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
-					totalCPULimit.Add(cpu)
-				}
-				if memory, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
-					totalMemoryLimit.Add(memory)
-				}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+
+		resize := PodResizeState(pod)
+		statuses := containerStatuses(pod)
+		defaults := LimitRangeDefaults(pod)
+
+		// Native sidecars run next to the app containers, so they are listed with them
+		containers := make([]ContainerResources, 0, len(pod.Spec.Containers))
+		for j := range pod.Spec.InitContainers {
+			if container := &pod.Spec.InitContainers[j]; IsSidecarContainer(container) {
+				c := newContainerResources(container, statuses[container.Name], resize)
+				c.Sidecar = true
+				c.LimitRangeDefaults = defaults[container.Name]
+				containers = append(containers, c)
 			}
 		}
-
-		// Also check init containers (they can affect scheduling)
-		for _, container := range pod.Spec.InitContainers {
-			if container.Resources.Requests != nil {
-				if cpu, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-					// Init containers use max(request, initContainer request)
-					if cpu.Cmp(totalCPURequest) > 0 {
-						totalCPURequest = cpu
-					}
-				}
-				if memory, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
-					if memory.Cmp(totalMemoryRequest) > 0 {
-						totalMemoryRequest = memory
-					}
-				}
-			}
+		for j := range pod.Spec.Containers {
+			container := &pod.Spec.Containers[j]
+			c := newContainerResources(container, statuses[container.Name], resize)
+			c.LimitRangeDefaults = defaults[container.Name]
+			containers = append(containers, c)
 		}
 
+		// Pod totals are what the scheduler reserves: init containers, sidecars and overhead included
+		requests := PodEffectiveRequests(pod)
+		limits := PodEffectiveLimits(pod)
+		cpuRequest := quantity(requests, corev1.ResourceCPU)
+		cpuLimit := quantity(limits, corev1.ResourceCPU)
+		memoryRequest := quantity(requests, corev1.ResourceMemory)
+		memoryLimit := quantity(limits, corev1.ResourceMemory)
+
 		resources = append(resources, PodResources{
-			Name:             pod.Name,
-			Namespace:        pod.Namespace,
-			CPURequest:       FormatResourceQuantity(totalCPURequest, true),
-			CPULimit:         FormatResourceQuantity(totalCPULimit, true),
-			MemoryRequest:    totalMemoryRequest,
-			MemoryLimit:      totalMemoryLimit,
-			MemoryRequestStr: FormatResourceQuantity(totalMemoryRequest, false),
-			MemoryLimitStr:   FormatResourceQuantity(totalMemoryLimit, false),
+			Name:               pod.Name,
+			Namespace:          pod.Namespace,
+			NodeName:           pod.Spec.NodeName,
+			Phase:              pod.Status.Phase,
+			Owner:              podController(pod),
+			Resize:             resize,
+			LimitRangeDefaults: mergedLimitRangeDefaults(defaults),
+			CPURequestMilli:    cpuRequest.MilliValue(),
+			CPULimitMilli:      cpuLimit.MilliValue(),
+			MemoryRequestBytes: memoryRequest.Value(),
+			MemoryLimitBytes:   memoryLimit.Value(),
+			Containers:         containers,
 		})
 	}
 
 	return resources, nil
 }
 
+// newContainerResources returns the requests and limits in force for a single container.
+// podResize is the pod's resize state, reported for the container if its spec differs from its status.
+func newContainerResources(
+	container *corev1.Container,
+	status *corev1.ContainerStatus,
+	podResize string,
+) ContainerResources {
+	requests, limits := containerResourcesInForce(container, status)
+	cpuRequest := quantity(requests, corev1.ResourceCPU)
+	cpuLimit := quantity(limits, corev1.ResourceCPU)
+	memoryRequest := quantity(requests, corev1.ResourceMemory)
+	memoryLimit := quantity(limits, corev1.ResourceMemory)
+
+	var resize string
+	if containerResizing(container, status) {
+		resize = podResize
+		if resize == "" {
+			resize = ResizeInProgress
+		}
+	}
+
+	return ContainerResources{
+		Name:               container.Name,
+		Resize:             resize,
+		CPURequestMilli:    cpuRequest.MilliValue(),
+		CPULimitMilli:      cpuLimit.MilliValue(),
+		MemoryRequestBytes: memoryRequest.Value(),
+		MemoryLimitBytes:   memoryLimit.Value(),
+	}
+}
+
 // FormatResourceQuantity formats a resource.Quantity to a human-readable string
 func FormatResourceQuantity(q resource.Quantity, isCPU bool) string {
 	if q.IsZero() {
@@ -166,33 +216,23 @@ func FormatMemoryInUnit(q resource.Quantity, targetUnit string) string {
 	}
 }
 
-// ExtractMemoryUnit extracts the unit from a memory string (e.g., "128Mi" -> "Mi")
-func ExtractMemoryUnit(memoryStr string) string {
-	if memoryStr == "" || memoryStr == "-" || memoryStr == "<unknown>" {
-		return "Mi" // Default unit
-	}
+// MemoryUnit returns the unit FormatMemory picks for a memory value in bytes (Gi, Mi or Ki),
+// so requests and limits can be shown in the same unit as the usage. Values under 1Ki use Mi.
+func MemoryUnit(bytes int64) string {
+	const (
+		KB = 1024
+		MB = KB * 1024
+		GB = MB * 1024
+	)
 
-	// Check for common units
-	if strings.HasSuffix(memoryStr, "Gi") {
+	switch {
+	case bytes >= GB:
 		return "Gi"
-	}
-	if strings.HasSuffix(memoryStr, "Mi") {
+	case bytes >= MB:
 		return "Mi"
-	}
-	if strings.HasSuffix(memoryStr, "Ki") {
+	case bytes >= KB:
 		return "Ki"
-	}
-	if strings.HasSuffix(memoryStr, "G") {
-		return "Gi"
-	}
-	if strings.HasSuffix(memoryStr, "M") {
+	default:
 		return "Mi"
 	}
-	if strings.HasSuffix(memoryStr, "K") {
-		return "Ki"
-	}
-
-	// Default to Mi if no unit found
-	return "Mi"
 }
-
@@ -1,9 +1,13 @@
 package pkg
 
 import (
+	"context"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestFormatResourceQuantity(t *testing.T) {
@@ -31,3 +35,120 @@ func TestFormatResourceQuantity(t *testing.T) {
 	}
 }
 
+
+func TestGetPodResourcesContainers(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("100m"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("200m"),
+						},
+					},
+				},
+				{
+					Name: "sidecar",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("50m"),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+
+	clientset := fake.NewSimpleClientset(pod)
+	result, err := GetPodResources(ctx, clientset, "default", "", "", nil)
+	if err != nil {
+		t.Fatalf("GetPodResources() error = %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("GetPodResources() returned %d pods, want 1", len(result))
+	}
+	if result[0].Phase != corev1.PodRunning {
+		t.Errorf("GetPodResources() phase = %q, want Running", result[0].Phase)
+	}
+
+	containers := result[0].Containers
+	if len(containers) != 2 {
+		t.Fatalf("GetPodResources() returned %d containers, want 2", len(containers))
+	}
+	if containers[0].Name != "app" || containers[0].CPURequestMilli != 100 || containers[0].CPULimitMilli != 200 {
+		t.Errorf("GetPodResources() app container = %+v", containers[0])
+	}
+	if containers[0].MemoryRequestBytes != 128<<20 || containers[0].MemoryLimitBytes != 0 {
+		t.Errorf("GetPodResources() app container memory = %d/%d, want %d/0",
+			containers[0].MemoryRequestBytes, containers[0].MemoryLimitBytes, 128<<20)
+	}
+	if containers[1].Name != "sidecar" || containers[1].CPURequestMilli != 50 || containers[1].CPULimitMilli != 0 {
+		t.Errorf("GetPodResources() sidecar container = %+v", containers[1])
+	}
+	if result[0].CPURequestMilli != 150 || result[0].MemoryRequestBytes != 128<<20 {
+		t.Errorf("GetPodResources() pod requests = %dm/%d, want 150m/%d",
+			result[0].CPURequestMilli, result[0].MemoryRequestBytes, 128<<20)
+	}
+}
+
+func TestGetPodResourcesSidecar(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{
+				testContainer("init", "1", "", false),
+				testContainer("mesh", "100m", "", true),
+			},
+			Containers: []corev1.Container{testContainer("app", "200m", "", false)},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(pod)
+	result, err := GetPodResources(ctx, clientset, "default", "", "", nil)
+	if err != nil {
+		t.Fatalf("GetPodResources() error = %v", err)
+	}
+
+	// The sidecar is listed with the app containers, the regular init container is not
+	containers := result[0].Containers
+	if len(containers) != 2 || containers[0].Name != "mesh" || containers[1].Name != "app" {
+		t.Errorf("GetPodResources() containers = %+v, want mesh and app", containers)
+	}
+	if !containers[0].Sidecar || containers[1].Sidecar {
+		t.Errorf("GetPodResources() only mesh should be marked as a sidecar: %+v", containers)
+	}
+	// max(200m + 100m, 1 + 0)
+	if result[0].CPURequestMilli != 1000 {
+		t.Errorf("GetPodResources() pod CPURequestMilli = %d, want 1000", result[0].CPURequestMilli)
+	}
+}
+
+func TestMemoryUnit(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "Mi"},
+		{512, "Mi"},
+		{2 << 10, "Ki"},
+		{128 << 20, "Mi"},
+		{3 << 30, "Gi"},
+	}
+
+	for _, tt := range tests {
+		if got := MemoryUnit(tt.bytes); got != tt.want {
+			t.Errorf("MemoryUnit(%d) = %v, want %v", tt.bytes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Version and kind of the snapshots written by 'rltop record'
+const (
+	SnapshotAPIVersion = "rltop.veditoid.io/v1alpha1"
+	SnapshotKind       = "Snapshot"
+)
+
+// Snapshot is what rltop saw at a point in time: the raw pod and node data the commands combine,
+// so recorded snapshots can be replayed through the same pipeline. Recordings hold one snapshot per line.
+type Snapshot struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Timestamp  time.Time `json:"timestamp"`
+	// Namespace of the recorded pods, empty when every namespace was recorded
+	Namespace string `json:"namespace,omitempty"`
+	// LabelSelector the recorded pods were selected with, empty when every pod was recorded
+	LabelSelector string         `json:"labelSelector,omitempty"`
+	PodMetrics    []PodMetrics   `json:"podMetrics"`
+	PodResources  []PodResources `json:"podResources"`
+	// Top-level workload of every pod keyed by "namespace/name", see ResolveWorkloads
+	Workloads map[string]Workload `json:"workloads,omitempty"`
+	// Node data, empty when nodes were not recorded. Node totals leave out terminated pods.
+	NodeMetrics   []NodeMetrics             `json:"nodeMetrics,omitempty"`
+	NodeResources []NodeAggregatedResources `json:"nodeResources,omitempty"`
+	Nodes         []corev1.Node             `json:"nodes,omitempty"`
+}
+
+// SnapshotNode trims a node to what the node view reads: name, labels, cordon, capacity, allocatable and
+// the Ready condition
+func SnapshotNode(node *corev1.Node) corev1.Node {
+	trimmed := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: node.Name, Labels: node.Labels},
+		Spec:       corev1.NodeSpec{Unschedulable: node.Spec.Unschedulable},
+		Status: corev1.NodeStatus{
+			Capacity:    node.Status.Capacity,
+			Allocatable: node.Status.Allocatable,
+		},
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			trimmed.Status.Conditions = []corev1.NodeCondition{{Type: condition.Type, Status: condition.Status}}
+		}
+	}
+	return trimmed
+}
+
+// WriteSnapshot writes the snapshot as a single JSON line
+func WriteSnapshot(w io.Writer, snapshot *Snapshot) error {
+	snapshot.APIVersion = SnapshotAPIVersion
+	snapshot.Kind = SnapshotKind
+	line, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// ReadSnapshot reads a recording and returns its latest snapshot taken at or before at,
+// or its last snapshot when at is zero
+func ReadSnapshot(r io.Reader, at time.Time) (*Snapshot, error) {
+	reader := bufio.NewReader(r)
+	var found *Snapshot
+	for lineNumber := 1; ; lineNumber++ {
+		// Snapshots of large clusters make long lines, so they are not read with a bufio.Scanner
+		line, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var snapshot Snapshot
+			if decodeErr := json.Unmarshal(line, &snapshot); decodeErr != nil {
+				return nil, fmt.Errorf("failed to decode snapshot on line %d: %w", lineNumber, decodeErr)
+			}
+			if snapshot.Kind != SnapshotKind {
+				return nil, fmt.Errorf("line %d is a %q, not a %s", lineNumber, snapshot.Kind, SnapshotKind)
+			}
+			if at.IsZero() || !snapshot.Timestamp.After(at) {
+				found = &snapshot
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	if found == nil {
+		if at.IsZero() {
+			return nil, errors.New("no snapshot recorded")
+		}
+		return nil, fmt.Errorf("no snapshot recorded at or before %s", at.Format(time.RFC3339))
+	}
+	return found, nil
+}
+
+// Pods returns the pod metrics and resources of the snapshot in the namespace (empty for all)
+// and with the names (empty for all)
+func (s *Snapshot) Pods(namespace string, names []string) ([]PodMetrics, []PodResources) {
+	nameSet := make(map[string]bool, len(names))
+	for _, name := range names {
+		nameSet[name] = true
+	}
+	matches := func(podNamespace, podName string) bool {
+		return (namespace == "" || podNamespace == namespace) && (len(nameSet) == 0 || nameSet[podName])
+	}
+
+	metrics := make([]PodMetrics, 0, len(s.PodMetrics))
+	for _, m := range s.PodMetrics {
+		if matches(m.Namespace, m.Name) {
+			metrics = append(metrics, m)
+		}
+	}
+	resources := make([]PodResources, 0, len(s.PodResources))
+	for _, r := range s.PodResources {
+		if matches(r.Namespace, r.Name) {
+			resources = append(resources, r)
+		}
+	}
+	return metrics, resources
+}
+
+// NodeData returns the node metrics, aggregated pod resources by node and node objects of the snapshot
+// with the names (empty for all), as the node view fetches them
+func (s *Snapshot) NodeData(names []string) (
+	[]NodeMetrics,
+	map[string]*NodeAggregatedResources,
+	map[string]*corev1.Node,
+) {
+	nameSet := make(map[string]bool, len(names))
+	for _, name := range names {
+		nameSet[name] = true
+	}
+	matches := func(name string) bool {
+		return len(nameSet) == 0 || nameSet[name]
+	}
+
+	metrics := make([]NodeMetrics, 0, len(s.NodeMetrics))
+	for _, m := range s.NodeMetrics {
+		if matches(m.Name) {
+			metrics = append(metrics, m)
+		}
+	}
+	resources := make(map[string]*NodeAggregatedResources, len(s.NodeResources))
+	for i := range s.NodeResources {
+		if r := &s.NodeResources[i]; matches(r.NodeName) {
+			resources[r.NodeName] = r
+		}
+	}
+	nodes := make(map[string]*corev1.Node, len(s.Nodes))
+	for i := range s.Nodes {
+		if node := &s.Nodes[i]; matches(node.Name) {
+			nodes[node.Name] = node
+		}
+	}
+	return metrics, resources, nodes
+}
+
+// PodWorkloads returns the top-level workload of every pod of the snapshot, keyed by "namespace/name".
+// Snapshots recorded without workloads fall back to the controller in the pod's ownerReferences.
+func (s *Snapshot) PodWorkloads() map[string]Workload {
+	if s.Workloads != nil {
+		return s.Workloads
+	}
+	workloads := make(map[string]Workload, len(s.PodResources))
+	for _, r := range s.PodResources {
+		owner := r.Owner
+		if owner.Kind == "" {
+			owner = Workload{Kind: KindPod, Name: r.Name}
+		}
+		workloads[r.Namespace+"/"+r.Name] = owner
+	}
+	return workloads
+}
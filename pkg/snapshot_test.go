@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var recording bytes.Buffer
+	for i := 0; i < 3; i++ {
+		snapshot := &Snapshot{
+			Timestamp:    start.Add(time.Duration(i) * time.Minute),
+			PodMetrics:   []PodMetrics{{Name: "web-1", Namespace: "default", CPUMilli: int64(100 * (i + 1))}},
+			PodResources: []PodResources{{Name: "web-1", Namespace: "default", CPURequestMilli: 250}},
+			NodeResources: []NodeAggregatedResources{
+				{NodeName: "node-1", CPURequest: resource.MustParse("250m"), MemoryRequest: resource.MustParse("1Gi")},
+			},
+		}
+		if err := WriteSnapshot(&recording, snapshot); err != nil {
+			t.Fatalf("WriteSnapshot() error = %v", err)
+		}
+	}
+	if lines := strings.Count(recording.String(), "\n"); lines != 3 {
+		t.Fatalf("WriteSnapshot() wrote %d lines, want one per snapshot", lines)
+	}
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		wantCPU int64
+		wantErr string
+	}{
+		{name: "last snapshot", wantCPU: 300},
+		{name: "exact timestamp", at: start.Add(time.Minute), wantCPU: 200},
+		{name: "between snapshots", at: start.Add(90 * time.Second), wantCPU: 200},
+		{name: "before the recording", at: start.Add(-time.Second), wantErr: "no snapshot recorded at or before"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, err := ReadSnapshot(bytes.NewReader(recording.Bytes()), tt.at)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ReadSnapshot() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadSnapshot() error = %v", err)
+			}
+			if snapshot.APIVersion != SnapshotAPIVersion || snapshot.PodMetrics[0].CPUMilli != tt.wantCPU {
+				t.Errorf("ReadSnapshot() = %+v, want %dm of CPU", snapshot, tt.wantCPU)
+			}
+			if got := snapshot.NodeResources[0].MemoryRequest; got.Cmp(resource.MustParse("1Gi")) != 0 {
+				t.Errorf("ReadSnapshot() memory request = %s, want 1Gi", got.String())
+			}
+		})
+	}
+}
+
+func TestReadSnapshotErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		recording string
+		wantErr   string
+	}{
+		{name: "empty", recording: "\n", wantErr: "no snapshot recorded"},
+		{name: "not JSON", recording: "{\"kind\":\"Snapshot\"}\nnot json\n", wantErr: "line 2"},
+		{name: "other kind", recording: "{\"kind\":\"PodUsageList\"}\n", wantErr: "not a Snapshot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSnapshot(strings.NewReader(tt.recording), time.Time{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadSnapshot() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSnapshotFilters(t *testing.T) {
+	snapshot := &Snapshot{
+		PodMetrics: []PodMetrics{
+			{Name: "web-1", Namespace: "default"},
+			{Name: "web-1", Namespace: "staging"},
+		},
+		PodResources: []PodResources{
+			{Name: "web-1", Namespace: "default"},
+			{Name: "db-0", Namespace: "default"},
+			{Name: "web-1", Namespace: "staging"},
+		},
+		NodeMetrics:   []NodeMetrics{{Name: "node-1"}, {Name: "node-2"}},
+		NodeResources: []NodeAggregatedResources{{NodeName: "node-1"}, {NodeName: "node-2"}},
+		Nodes: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		},
+	}
+
+	if metrics, resources := snapshot.Pods("", nil); len(metrics) != 2 || len(resources) != 3 {
+		t.Errorf("Pods() across namespaces = %d metrics and %d resources, want 2 and 3", len(metrics), len(resources))
+	}
+	if metrics, resources := snapshot.Pods("default", []string{"web-1"}); len(metrics) != 1 || len(resources) != 1 {
+		t.Errorf("Pods() of default/web-1 = %d metrics and %d resources, want 1 and 1", len(metrics), len(resources))
+	}
+
+	metrics, resources, nodes := snapshot.NodeData([]string{"node-2"})
+	if len(metrics) != 1 || resources["node-2"] == nil || nodes["node-2"] == nil || len(nodes) != 1 {
+		t.Errorf("NodeData() of node-2 = %+v, %+v, %+v", metrics, resources, nodes)
+	}
+}
+
+func TestSnapshotNode(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "node-1",
+			Labels:      map[string]string{"pool": "spot"},
+			Annotations: map[string]string{"node.alpha.kubernetes.io/ttl": "0"},
+		},
+		Spec: corev1.NodeSpec{Unschedulable: true, PodCIDR: "10.0.0.0/24"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue, Message: "kubelet is posting ready status"},
+			},
+			Images: []corev1.ContainerImage{{Names: []string{"nginx"}}},
+		},
+	}
+
+	trimmed := SnapshotNode(node)
+	if trimmed.Annotations != nil || trimmed.Spec.PodCIDR != "" || trimmed.Status.Images != nil {
+		t.Errorf("SnapshotNode() = %+v, want only what the node view reads", trimmed)
+	}
+	if !trimmed.Spec.Unschedulable || trimmed.Labels["pool"] != "spot" || trimmed.Status.Allocatable.Cpu().Value() != 4 {
+		t.Errorf("SnapshotNode() lost the cordon, labels or allocatable: %+v", trimmed)
+	}
+	if len(trimmed.Status.Conditions) != 1 || trimmed.Status.Conditions[0].Type != corev1.NodeReady {
+		t.Errorf("SnapshotNode() conditions = %+v, want only Ready", trimmed.Status.Conditions)
+	}
+}
+
+func TestSnapshotPodWorkloads(t *testing.T) {
+	snapshot := &Snapshot{PodResources: []PodResources{
+		{Name: "web-7d9f-abcde", Namespace: "default", Owner: Workload{Kind: KindReplicaSet, Name: "web-7d9f"}},
+		{Name: "debug", Namespace: "default"},
+	}}
+	workloads := snapshot.PodWorkloads()
+	if got := workloads["default/web-7d9f-abcde"]; got.Kind != KindReplicaSet || got.Name != "web-7d9f" {
+		t.Errorf("PodWorkloads() without resolved workloads = %+v, want the owner", got)
+	}
+	if got := workloads["default/debug"]; got.Kind != KindPod || got.Name != "debug" {
+		t.Errorf("PodWorkloads() of a bare pod = %+v, want the pod itself", got)
+	}
+
+	snapshot.Workloads = map[string]Workload{"default/web-7d9f-abcde": {Kind: "Deployment", Name: "web"}}
+	if got := snapshot.PodWorkloads()["default/web-7d9f-abcde"]; got.Kind != "Deployment" {
+		t.Errorf("PodWorkloads() = %+v, want the recorded workload", got)
+	}
+}
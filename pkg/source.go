@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// MetricsSource provides the CPU and memory usage of pods and nodes.
+// The Metrics API (metrics-server) and Prometheus (cAdvisor metrics) implement it.
+type MetricsSource interface {
+	// Check returns an error, with a hint on how to fix it, when the source cannot be queried
+	Check(ctx context.Context) error
+	// PodMetrics returns the usage of the pods matching the namespace (empty for all), selectors and names
+	PodMetrics(
+		ctx context.Context,
+		namespace, labelSelector, fieldSelector string,
+		podNames []string,
+	) ([]PodMetrics, error)
+	// NodeMetrics returns the usage of the nodes matching the label selector and names
+	NodeMetrics(ctx context.Context, labelSelector string, nodeNames []string) ([]NodeMetrics, error)
+}
+
+// MetricsAPISource reads usage from the Metrics API (metrics.k8s.io), like 'kubectl top'
+type MetricsAPISource struct {
+	clientset     kubernetes.Interface
+	metricsClient metricsclientset.Interface
+}
+
+// NewMetricsAPISource creates a metrics source backed by the Metrics API
+func NewMetricsAPISource(clientset kubernetes.Interface, metricsClient metricsclientset.Interface) *MetricsAPISource {
+	return &MetricsAPISource{clientset: clientset, metricsClient: metricsClient}
+}
+
+// Check returns an error if the Metrics API is not served by the cluster
+func (s *MetricsAPISource) Check(ctx context.Context) error {
+	if err := CheckMetricsAPIAvailable(ctx, s.clientset); err != nil {
+		return fmt.Errorf("metrics API not available: %w\nPlease ensure metrics-server is installed in your cluster", err)
+	}
+	return nil
+}
+
+// PodMetrics fetches pod metrics from the Metrics API
+func (s *MetricsAPISource) PodMetrics(
+	ctx context.Context,
+	namespace, labelSelector, fieldSelector string,
+	podNames []string,
+) ([]PodMetrics, error) {
+	return GetPodMetrics(ctx, s.metricsClient, namespace, labelSelector, fieldSelector, podNames)
+}
+
+// NodeMetrics fetches node metrics from the Metrics API
+func (s *MetricsAPISource) NodeMetrics(
+	ctx context.Context,
+	labelSelector string,
+	nodeNames []string,
+) ([]NodeMetrics, error) {
+	return GetNodeMetrics(ctx, s.metricsClient, labelSelector, nodeNames)
+}
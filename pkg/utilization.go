@@ -0,0 +1,21 @@
+package pkg
+
+import "fmt"
+
+// UtilizationPercent returns used as a percentage of total.
+// The second return value is false when total is unset (zero or negative), e.g. no request or limit.
+func UtilizationPercent(used, total int64) (float64, bool) {
+	if total <= 0 {
+		return 0, false
+	}
+	return float64(used) / float64(total) * 100, true
+}
+
+// FormatUtilization formats used as a percentage of total, or "-" when total is unset
+func FormatUtilization(used, total int64) string {
+	percent, ok := UtilizationPercent(used, total)
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", percent)
+}
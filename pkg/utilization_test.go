@@ -0,0 +1,39 @@
+package pkg
+
+import (
+	"testing"
+)
+
+func TestFormatUtilization(t *testing.T) {
+	tests := []struct {
+		name  string
+		used  int64
+		total int64
+		want  string
+	}{
+		{"half of request", 100, 200, "50%"},
+		{"over limit", 300, 200, "150%"},
+		{"zero usage", 0, 200, "0%"},
+		{"rounded", 1, 3, "33%"},
+		{"unset total", 100, 0, "-"},
+		{"exact bytes", 128 << 20, 512 << 20, "25%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatUtilization(tt.used, tt.total)
+			if got != tt.want {
+				t.Errorf("FormatUtilization(%d, %d) = %v, want %v", tt.used, tt.total, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUtilizationPercent(t *testing.T) {
+	if percent, ok := UtilizationPercent(150, 1000); !ok || percent != 15 {
+		t.Errorf("UtilizationPercent(150, 1000) = %v, %v, want 15, true", percent, ok)
+	}
+	if _, ok := UtilizationPercent(150, 0); ok {
+		t.Errorf("UtilizationPercent(150, 0) ok = true, want false")
+	}
+}
@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// VPAResource is the VerticalPodAutoscaler resource of the autoscaling.k8s.io/v1 API
+var VPAResource = schema.GroupVersionResource{
+	Group:    "autoscaling.k8s.io",
+	Version:  "v1",
+	Resource: "verticalpodautoscalers",
+}
+
+// ErrVPANotInstalled is returned by GetVPARecommendations when the VerticalPodAutoscaler CRD is not installed
+var ErrVPANotInstalled = errors.New("VerticalPodAutoscaler CRD (autoscaling.k8s.io/v1) is not installed")
+
+// VPATarget identifies the workload a VerticalPodAutoscaler targets
+type VPATarget struct {
+	Namespace string
+	Workload
+}
+
+// VPARecommendation is the recommendation of a VerticalPodAutoscaler for a container.
+// CPU is in millicores and memory in bytes; zero means not recommended.
+type VPARecommendation struct {
+	CPUTargetMilli        int64
+	CPULowerBoundMilli    int64
+	CPUUpperBoundMilli    int64
+	MemoryTargetBytes     int64
+	MemoryLowerBoundBytes int64
+	MemoryUpperBoundBytes int64
+}
+
+// GetVPARecommendations fetches the VerticalPodAutoscalers through the dynamic client and returns their
+// container recommendations by target workload and container name. VPAs without a recommendation yet
+// are skipped. It returns ErrVPANotInstalled when the CRD is missing.
+func GetVPARecommendations(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	namespace string,
+) (map[VPATarget]map[string]VPARecommendation, error) {
+	list, err := dynamicClient.Resource(VPAResource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, ErrVPANotInstalled
+		}
+		return nil, fmt.Errorf("failed to fetch vertical pod autoscalers: %w", err)
+	}
+
+	recommendations := make(map[VPATarget]map[string]VPARecommendation, len(list.Items))
+	for i := range list.Items {
+		vpa := &list.Items[i]
+		kind, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "kind")
+		name, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "name")
+		containers, _, _ := unstructured.NestedSlice(vpa.Object, "status", "recommendation", "containerRecommendations")
+		if kind == "" || name == "" || len(containers) == 0 {
+			continue
+		}
+
+		byContainer := make(map[string]VPARecommendation, len(containers))
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			containerName, _, _ := unstructured.NestedString(container, "containerName")
+
+			var r VPARecommendation
+			r.CPUTargetMilli, r.MemoryTargetBytes = vpaQuantities(container, "target")
+			r.CPULowerBoundMilli, r.MemoryLowerBoundBytes = vpaQuantities(container, "lowerBound")
+			r.CPUUpperBoundMilli, r.MemoryUpperBoundBytes = vpaQuantities(container, "upperBound")
+			byContainer[containerName] = r
+		}
+
+		target := VPATarget{Namespace: vpa.GetNamespace(), Workload: Workload{Kind: kind, Name: name}}
+		recommendations[target] = byContainer
+	}
+
+	return recommendations, nil
+}
+
+// vpaQuantities returns the CPU and memory of a container recommendation field (target, lowerBound, upperBound).
+// Missing or invalid quantities are zero.
+func vpaQuantities(container map[string]interface{}, field string) (cpuMilli, memoryBytes int64) {
+	values, _, _ := unstructured.NestedStringMap(container, field)
+	if q, err := resource.ParseQuantity(values["cpu"]); err == nil {
+		cpuMilli = q.MilliValue()
+	}
+	if q, err := resource.ParseQuantity(values["memory"]); err == nil {
+		memoryBytes = q.Value()
+	}
+	return cpuMilli, memoryBytes
+}
@@ -0,0 +1,85 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// testVPA returns a VerticalPodAutoscaler targeting a Deployment, with a recommendation for the app container
+func testVPA(namespace, name, deployment string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling.k8s.io/v1",
+		"kind":       "VerticalPodAutoscaler",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec": map[string]interface{}{
+			"targetRef":    map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": deployment},
+			"updatePolicy": map[string]interface{}{"updateMode": "Off"},
+		},
+		"status": map[string]interface{}{
+			"recommendation": map[string]interface{}{
+				"containerRecommendations": []interface{}{
+					map[string]interface{}{
+						"containerName": "app",
+						"target":        map[string]interface{}{"cpu": "250m", "memory": "262144k"},
+						"lowerBound":    map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+						"upperBound":    map[string]interface{}{"cpu": "1", "memory": "1Gi"},
+					},
+				},
+			},
+		},
+	}}
+}
+
+// newVPADynamicClient returns a fake dynamic client serving the given VerticalPodAutoscalers
+func newVPADynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{VPAResource: "VerticalPodAutoscalerList"}, objects...)
+}
+
+func TestGetVPARecommendations(t *testing.T) {
+	pending := testVPA("default", "pending", "new")
+	unstructured.RemoveNestedField(pending.Object, "status")
+
+	client := newVPADynamicClient(testVPA("default", "web", "web"), pending)
+	recommendations, err := GetVPARecommendations(context.Background(), client, "")
+	if err != nil {
+		t.Fatalf("GetVPARecommendations() error = %v", err)
+	}
+	if len(recommendations) != 1 {
+		t.Fatalf("GetVPARecommendations() returned %d targets, want 1 (no recommendation yet is skipped)",
+			len(recommendations))
+	}
+
+	target := VPATarget{Namespace: "default", Workload: Workload{Kind: "Deployment", Name: "web"}}
+	want := VPARecommendation{
+		CPUTargetMilli:        250,
+		CPULowerBoundMilli:    100,
+		CPUUpperBoundMilli:    1000,
+		MemoryTargetBytes:     262144000,
+		MemoryLowerBoundBytes: 128 << 20,
+		MemoryUpperBoundBytes: 1 << 30,
+	}
+	if got := recommendations[target]["app"]; got != want {
+		t.Errorf("GetVPARecommendations() app = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetVPARecommendationsNotInstalled(t *testing.T) {
+	client := newVPADynamicClient()
+	client.PrependReactor("list", "verticalpodautoscalers",
+		func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewNotFound(VPAResource.GroupResource(), "")
+		})
+
+	if _, err := GetVPARecommendations(context.Background(), client, "default"); !errors.Is(err, ErrVPANotInstalled) {
+		t.Errorf("GetVPARecommendations() error = %v, want ErrVPANotInstalled", err)
+	}
+}
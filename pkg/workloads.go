@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Kinds of the controllers ResolveWorkloads looks through, and of pods without a controller
+const (
+	KindPod        = "Pod"
+	KindReplicaSet = "ReplicaSet"
+	KindJob        = "Job"
+)
+
+// Workload identifies the controller a pod belongs to (Deployment, StatefulSet, DaemonSet, Job, ...)
+type Workload struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// podController returns the controller of a pod from its ownerReferences, or the pod itself for bare pods
+func podController(pod *corev1.Pod) Workload {
+	if ref := metav1.GetControllerOf(pod); ref != nil {
+		return Workload{Kind: ref.Kind, Name: ref.Name}
+	}
+	return Workload{Kind: KindPod, Name: pod.Name}
+}
+
+// ResolveWorkloads returns the top-level workload of every pod, keyed by "namespace/name".
+// Pods owned by a ReplicaSet or Job that is itself controlled by another object (a Deployment,
+// a CronJob) are resolved to that object; other controllers are used as they are.
+// ReplicaSets and Jobs are only listed when some pod is owned by one.
+func ResolveWorkloads(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	namespace string,
+	resources []PodResources,
+) (map[string]Workload, error) {
+	var hasReplicaSets, hasJobs bool
+	for _, r := range resources {
+		hasReplicaSets = hasReplicaSets || r.Owner.Kind == KindReplicaSet
+		hasJobs = hasJobs || r.Owner.Kind == KindJob
+	}
+
+	// Controllers of the ReplicaSets and Jobs, keyed by "kind/namespace/name"
+	parents := make(map[string]Workload)
+	if hasReplicaSets {
+		replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch replicasets: %w", err)
+		}
+		for i := range replicaSets.Items {
+			rs := &replicaSets.Items[i]
+			if ref := metav1.GetControllerOf(rs); ref != nil {
+				parents[KindReplicaSet+"/"+rs.Namespace+"/"+rs.Name] = Workload{Kind: ref.Kind, Name: ref.Name}
+			}
+		}
+	}
+	if hasJobs {
+		jobs, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch jobs: %w", err)
+		}
+		for i := range jobs.Items {
+			job := &jobs.Items[i]
+			if ref := metav1.GetControllerOf(job); ref != nil {
+				parents[KindJob+"/"+job.Namespace+"/"+job.Name] = Workload{Kind: ref.Kind, Name: ref.Name}
+			}
+		}
+	}
+
+	workloads := make(map[string]Workload, len(resources))
+	for _, r := range resources {
+		owner := r.Owner
+		if owner.Kind == "" {
+			owner = Workload{Kind: KindPod, Name: r.Name}
+		}
+		if parent, ok := parents[owner.Kind+"/"+r.Namespace+"/"+owner.Name]; ok {
+			owner = parent
+		}
+		workloads[r.Namespace+"/"+r.Name] = owner
+	}
+	return workloads, nil
+}
@@ -0,0 +1,82 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// controlledBy returns object metadata with a controller owner reference
+func controlledBy(name, kind, owner string) metav1.ObjectMeta {
+	controller := true
+	meta := metav1.ObjectMeta{Name: name, Namespace: "default"}
+	if kind != "" {
+		meta.OwnerReferences = []metav1.OwnerReference{{Kind: kind, Name: owner, Controller: &controller}}
+	}
+	return meta
+}
+
+func TestResolveWorkloads(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: controlledBy("web-abc-1", KindReplicaSet, "web-abc")},
+		&corev1.Pod{ObjectMeta: controlledBy("web-abc-2", KindReplicaSet, "web-abc")},
+		&corev1.Pod{ObjectMeta: controlledBy("orphan-rs-1", KindReplicaSet, "orphan-rs")},
+		&corev1.Pod{ObjectMeta: controlledBy("db-0", "StatefulSet", "db")},
+		&corev1.Pod{ObjectMeta: controlledBy("backup-123-x", KindJob, "backup-123")},
+		&corev1.Pod{ObjectMeta: controlledBy("debug", "", "")},
+		&appsv1.ReplicaSet{ObjectMeta: controlledBy("web-abc", "Deployment", "web")},
+		&appsv1.ReplicaSet{ObjectMeta: controlledBy("orphan-rs", "", "")},
+		&batchv1.Job{ObjectMeta: controlledBy("backup-123", "CronJob", "backup")},
+	)
+
+	resources, err := GetPodResources(context.Background(), clientset, "default", "", "", nil)
+	if err != nil {
+		t.Fatalf("GetPodResources() error = %v", err)
+	}
+
+	workloads, err := ResolveWorkloads(context.Background(), clientset, "default", resources)
+	if err != nil {
+		t.Fatalf("ResolveWorkloads() error = %v", err)
+	}
+
+	want := map[string]Workload{
+		"default/web-abc-1":    {Kind: "Deployment", Name: "web"},
+		"default/web-abc-2":    {Kind: "Deployment", Name: "web"},
+		"default/orphan-rs-1":  {Kind: KindReplicaSet, Name: "orphan-rs"},
+		"default/db-0":         {Kind: "StatefulSet", Name: "db"},
+		"default/backup-123-x": {Kind: "CronJob", Name: "backup"},
+		"default/debug":        {Kind: KindPod, Name: "debug"},
+	}
+	if len(workloads) != len(want) {
+		t.Errorf("ResolveWorkloads() returned %d workloads, want %d", len(workloads), len(want))
+	}
+	for key, w := range want {
+		if got := workloads[key]; got != w {
+			t.Errorf("ResolveWorkloads()[%s] = %+v, want %+v", key, got, w)
+		}
+	}
+}
+
+func TestResolveWorkloadsSkipsUnneededLists(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	resources := []PodResources{
+		{Name: "db-0", Namespace: "default", Owner: Workload{Kind: "StatefulSet", Name: "db"}},
+		{Name: "debug", Namespace: "default"},
+	}
+
+	workloads, err := ResolveWorkloads(context.Background(), clientset, "default", resources)
+	if err != nil {
+		t.Fatalf("ResolveWorkloads() error = %v", err)
+	}
+	if got := workloads["default/debug"]; got.Kind != KindPod || got.Name != "debug" {
+		t.Errorf("ResolveWorkloads() pod without owner = %+v, want itself", got)
+	}
+	if actions := clientset.Actions(); len(actions) != 0 {
+		t.Errorf("ResolveWorkloads() made %d API calls, want none", len(actions))
+	}
+}
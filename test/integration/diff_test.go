@@ -0,0 +1,44 @@
+//go:build integration
+
+package integration
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffCommand_RecordingAgainstCluster(t *testing.T) {
+	recording := filepath.Join(t.TempDir(), "recording.jsonl")
+	output, err := runCommand(t, "record", "-n", testNamespace, "-f", recording, "--count=1", "--nodes=false")
+	if err != nil {
+		t.Fatalf("record failed: %v\nOutput: %s", err, output)
+	}
+
+	// Requests did not change since the recording, only usage may have
+	output, err = runCommand(t, "diff", recording, "--by=workload", "--show-unchanged")
+	if err != nil {
+		t.Fatalf("diff failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "CHANGE") || !strings.Contains(output, "test-pod-1") {
+		t.Errorf("diff should list the workloads of the recorded namespace\nOutput: %s", output)
+	}
+	if strings.Contains(output, "added") || strings.Contains(output, "removed") {
+		t.Errorf("No workload should be added or removed\nOutput: %s", output)
+	}
+
+	output, err = runCommand(t, "diff", recording, recording, "-o", "json")
+	if err != nil {
+		t.Fatalf("diff of a recording with itself failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, `"items": []`) {
+		t.Errorf("A recording should not differ from itself\nOutput: %s", output)
+	}
+}
+
+func TestDiffCommand_StdinTwice(t *testing.T) {
+	output, err := runCommand(t, "diff", "-", "-")
+	if err == nil || !strings.Contains(output, "stdin can only be read once") {
+		t.Errorf("Expected error for reading stdin as both BEFORE and AFTER\nOutput: %s", output)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build integration
+
+package integration
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamespaceCommand_Basic(t *testing.T) {
+	output, err := runCommand(t, "namespace", testNamespace, "--quota")
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, col := range []string{"NAME", "PODS", "CPU EFF%", "MEM EFF%", "CPU REQ QUOTA", testNamespace} {
+		if !strings.Contains(output, col) {
+			t.Errorf("Output missing %s\nOutput: %s", col, output)
+		}
+	}
+}
@@ -154,3 +154,34 @@ func TestNodeCommand_AggregatedResources(t *testing.T) {
 	}
 }
 
+
+func TestNodeCommand_Pods(t *testing.T) {
+	output, err := runCommand(t, "node", "--no-headers")
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		t.Skip("No nodes found, skipping test")
+	}
+	nodeName := fields[0]
+
+	output, err = runCommand(t, "node", nodeName, "--pods")
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+
+	// The node table is followed by the table of its pods, with a NAMESPACE column
+	for _, col := range []string{"MEMORY%", "NAMESPACE", nodeName} {
+		if !strings.Contains(output, col) {
+			t.Errorf("Output missing %s\nOutput: %s", col, output)
+		}
+	}
+}
+
+func TestNodeCommand_PodsRequiresNode(t *testing.T) {
+	output, err := runCommand(t, "node", "--pods")
+	if err == nil {
+		t.Errorf("Expected error for --pods without a node name\nOutput: %s", output)
+	}
+}
@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -94,7 +95,7 @@ func TestPodCommand_NoHeaders(t *testing.T) {
 	}
 
 	// Should still contain pod names
-	if !strings.Contains(output, "test-pod") {
+	if !strings.Contains(output, "test-pod-1") {
 		t.Errorf("Output should contain pod names\nOutput: %s", output)
 	}
 }
@@ -129,3 +130,63 @@ func TestPodCommand_ResourceRequestsLimits(t *testing.T) {
 	}
 }
 
+
+func TestPodCommand_VPAWithoutCRD(t *testing.T) {
+	// The kind cluster has no VerticalPodAutoscaler CRD: the columns are empty instead of failing
+	output, err := runCommand(t, "pod", "-n", testNamespace, "--vpa")
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, want := range []string{"CONTAINER", "VPA CPU TARGET", "VPA MEM UPPER", "not installed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output missing %s\nOutput: %s", want, output)
+		}
+	}
+}
+
+func TestPodCommand_PrometheusSourceUnavailable(t *testing.T) {
+	// Nothing listens on the discard port: the command fails early with the Prometheus URL
+	output, err := runCommand(t, "pod", "-n", testNamespace,
+		"--metrics-source=prometheus", "--prometheus-url=http://127.0.0.1:9")
+	if err == nil {
+		t.Fatalf("Command should fail without a reachable Prometheus\nOutput: %s", output)
+	}
+	if !strings.Contains(output, "prometheus not available at http://127.0.0.1:9") {
+		t.Errorf("Output should name the Prometheus URL\nOutput: %s", output)
+	}
+}
+
+func TestPodCommand_WindowRequiresPrometheus(t *testing.T) {
+	output, err := runCommand(t, "pod", "-n", testNamespace, "--window=24h", "--stat=p95")
+	if err == nil {
+		t.Fatalf("Command should fail with --window on the Metrics API\nOutput: %s", output)
+	}
+	if !strings.Contains(output, "--window requires --metrics-source=prometheus") {
+		t.Errorf("Output should explain --window needs Prometheus\nOutput: %s", output)
+	}
+}
+
+func TestPodCommand_RecordAndReplay(t *testing.T) {
+	recording := filepath.Join(t.TempDir(), "recording.jsonl")
+	output, err := runCommand(t, "record", "-n", testNamespace, "-f", recording, "--count=1", "--nodes=false")
+	if err != nil {
+		t.Fatalf("record failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "Recorded") {
+		t.Errorf("record should report the snapshot\nOutput: %s", output)
+	}
+
+	output, err = runCommand(t, "pod", "--from-file", recording, "-n", testNamespace)
+	if err != nil {
+		t.Fatalf("pod --from-file failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "CPU REQUEST") || !strings.Contains(output, "test-pod-1") {
+		t.Errorf("Replay should show the recorded pods\nOutput: %s", output)
+	}
+
+	output, err = runCommand(t, "pod", "--from-file", recording, "--watch")
+	if err == nil || !strings.Contains(output, "--watch needs a live cluster") {
+		t.Errorf("--watch should be rejected with --from-file: %v\nOutput: %s", err, output)
+	}
+}
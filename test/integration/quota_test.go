@@ -0,0 +1,25 @@
+//go:build integration
+
+package integration
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuotaCommand_Basic(t *testing.T) {
+	output, err := runCommand(t, "quota", "-A")
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+
+	// The test cluster may have no ResourceQuota at all
+	if strings.Contains(output, "No resource quotas found") {
+		return
+	}
+	for _, col := range []string{"NAMESPACE", "RESOURCE", "USED", "HARD", "LEFT%"} {
+		if !strings.Contains(output, col) {
+			t.Errorf("Output missing %s\nOutput: %s", col, output)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+//go:build integration
+
+package integration
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecommendCommand_SingleSample(t *testing.T) {
+	output, err := runCommand(t, "recommend", "-n", testNamespace, "--window=0")
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, col := range []string{"CONTAINER", "SAMPLES", "CPU REQUEST", "CPU SAVINGS", "MEMORY SAVINGS"} {
+		if !strings.Contains(output, col) {
+			t.Errorf("Output missing %s\nOutput: %s", col, output)
+		}
+	}
+}
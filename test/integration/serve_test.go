@@ -0,0 +1,55 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeCommand_Metrics(t *testing.T) {
+	// Build the binary if needed
+	if output, err := runCommand(t, "version"); err != nil {
+		t.Fatalf("version failed: %v\nOutput: %s", err, output)
+	}
+
+	const address = "127.0.0.1:19712"
+	cmd := exec.Command(filepath.Join("..", "..", "kubectl-rltop"), "serve", "-n", testNamespace, "--address", address)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("KUBECONFIG=%s", kubeconfigPath))
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start serve: %v", err)
+	}
+	defer func() {
+		_ = cmd.Process.Signal(os.Interrupt)
+		_ = cmd.Wait()
+	}()
+
+	// The first refresh completes shortly after the server starts
+	want := fmt.Sprintf(`rltop_pod_cpu_request_cores{namespace=%q,pod="test-pod-1"}`, testNamespace)
+	var body string
+	for deadline := time.Now().Add(30 * time.Second); time.Now().Before(deadline); time.Sleep(time.Second) {
+		resp, err := http.Get("http://" + address + "/metrics")
+		if err != nil {
+			continue
+		}
+		out, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if body = string(out); strings.Contains(body, want) {
+			break
+		}
+	}
+
+	if !strings.Contains(body, want) {
+		t.Fatalf("Metrics should include the requests of test-pod-1\nMetrics: %s", body)
+	}
+	if !strings.Contains(body, "rltop_scrape_errors_total") || !strings.Contains(body, "rltop_node_cpu_usage_cores") {
+		t.Errorf("Metrics should include the node gauges and the refresh counters\nMetrics: %s", body)
+	}
+}
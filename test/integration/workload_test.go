@@ -0,0 +1,34 @@
+//go:build integration
+
+package integration
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWorkloadCommand_Deployment(t *testing.T) {
+	// metrics-server runs as a Deployment in kube-system, so its pods resolve through the ReplicaSet
+	output, err := runCommand(t, "workload", "-n", "kube-system", "deployment/metrics-server")
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, col := range []string{"KIND", "NAME", "PODS", "CPU AVG", "MEMORY AVG", "Deployment", "metrics-server"} {
+		if !strings.Contains(output, col) {
+			t.Errorf("Output missing %s\nOutput: %s", col, output)
+		}
+	}
+}
+
+func TestWorkloadCommand_BarePods(t *testing.T) {
+	output, err := runCommand(t, "workload", "-n", testNamespace)
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+
+	// The test pods have no controller, so each one is its own workload
+	if !strings.Contains(output, "Pod") || !strings.Contains(output, "test-pod-1") {
+		t.Errorf("Output missing bare pod test-pod-1\nOutput: %s", output)
+	}
+}